@@ -0,0 +1,154 @@
+// Package apperr 应用错误：稳定的错误码、对应的 HTTP 状态码、字段级校验详情和中英文错误信息。
+// 控制器通过 c.Error 返回 *Error，由 middleware.ErrorHandler 统一输出 Response
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// Code 错误码，客户端应根据错误码而不是 message 文本判断错误类型，已发布的错误码不能修改
+type Code string
+
+const (
+	CodeInvalidRequest       Code = "invalid_request"        // 请求格式或参数错误
+	CodeValidationFailed     Code = "validation_failed"      // 请求体字段校验失败，details 中列出每个字段
+	CodeUnauthorized         Code = "unauthorized"           // 未携带认证信息
+	CodeInvalidToken         Code = "invalid_token"          // 访问令牌或刷新令牌无效、过期或已吊销
+	CodeTokenReused          Code = "token_reused"           // 已轮换的刷新令牌被再次使用，需要重新登录
+	CodeInvalidCredentials   Code = "invalid_credentials"    // 用户名密码或钱包签名错误
+	CodeForbidden            Code = "forbidden"              // 无权操作该资源
+	CodeNotFound             Code = "not_found"              // 资源不存在
+	CodeConflict             Code = "conflict"               // 与已有数据冲突，如用户名、标签重复
+	CodePreconditionFailed   Code = "precondition_failed"    // If-Match 的版本不是资源的当前版本，current 中返回当前状态
+	CodePreconditionRequired Code = "precondition_required"  // 修改资源时没有指定基于的版本
+	CodeUnsupportedMedia     Code = "unsupported_media_type" // 请求体的 Content-Type 不受支持
+	CodeRateLimited          Code = "rate_limited"           // 请求过于频繁
+	CodeInternal             Code = "internal_error"         // 服务器内部错误，详细原因只记录在日志中
+)
+
+var statuses = map[Code]int{
+	CodeInvalidRequest:       http.StatusBadRequest,
+	CodeValidationFailed:     http.StatusBadRequest,
+	CodeUnauthorized:         http.StatusUnauthorized,
+	CodeInvalidToken:         http.StatusUnauthorized,
+	CodeTokenReused:          http.StatusUnauthorized,
+	CodeInvalidCredentials:   http.StatusUnauthorized,
+	CodeForbidden:            http.StatusForbidden,
+	CodeNotFound:             http.StatusNotFound,
+	CodeConflict:             http.StatusConflict,
+	CodePreconditionFailed:   http.StatusPreconditionFailed,
+	CodePreconditionRequired: http.StatusPreconditionRequired,
+	CodeUnsupportedMedia:     http.StatusUnsupportedMediaType,
+	CodeRateLimited:          http.StatusTooManyRequests,
+	CodeInternal:             http.StatusInternalServerError,
+}
+
+// Status 错误码对应的 HTTP 状态码，未知错误码按 500 处理
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error 返回给客户端的错误，Message 为英文，输出时按请求的语言翻译；Cause 只用于日志。
+// Current 为资源的当前状态，版本冲突时原样返回给客户端
+type Error struct {
+	Code    Code
+	Message string
+	Details []FieldError
+	Current any
+	Cause   error
+}
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Rule    string `json:"rule" example:"required"`
+	Param   string `json:"param,omitempty" example:""`
+	Message string `json:"message" example:"title is required"`
+
+	kind reflect.Kind // 字段类型，用于区分长度和数值的说明
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Status HTTP 状态码
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+// New 创建指定错误码的错误
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap 创建错误并记录原因，原因不会返回给客户端
+func Wrap(code Code, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, Cause: cause}
+}
+
+// BadRequest 请求参数错误（400）
+func BadRequest(message string) *Error {
+	return New(CodeInvalidRequest, message)
+}
+
+// Unauthorized 未认证（401）
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+// Forbidden 无权操作（403）
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+// NotFound 资源不存在（404）
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+// Conflict 与已有数据冲突（409）
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+// PreconditionFailed 资源已被修改（412），current 为资源的当前状态
+func PreconditionFailed(message string, current any) *Error {
+	return &Error{Code: CodePreconditionFailed, Message: message, Current: current}
+}
+
+// PreconditionRequired 没有指定修改基于的版本（428）
+func PreconditionRequired(message string) *Error {
+	return New(CodePreconditionRequired, message)
+}
+
+// UnsupportedMediaType 请求体的 Content-Type 不受支持（415）
+func UnsupportedMediaType(message string) *Error {
+	return New(CodeUnsupportedMedia, message)
+}
+
+// Internal 服务器内部错误（500），cause 会记录到日志
+func Internal(message string, cause error) *Error {
+	return Wrap(CodeInternal, message, cause)
+}
+
+// From 把任意错误转换为 *Error，不是 *Error 的错误按内部错误处理，不向客户端暴露原始信息
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal("Internal server error", err)
+}
@@ -0,0 +1,104 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type postInput struct {
+	Title  string   `json:"title" binding:"required,max=5"`
+	Status string   `json:"status" binding:"omitempty,oneof=draft published"`
+	Tags   []string `json:"tags" binding:"omitempty,max=2,dive,min=1"`
+	Count  int      `json:"count"`
+}
+
+func bind(t *testing.T, body string) *Error {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	var input postInput
+	err := c.ShouldBindJSON(&input)
+	if err == nil {
+		t.Fatalf("binding %s should fail", body)
+	}
+	return Bind(err)
+}
+
+func TestBindValidationDetails(t *testing.T) {
+	e := bind(t, `{"title":"too long","status":"x","tags":["a",""]}`)
+	if e.Code != CodeValidationFailed || e.Status() != http.StatusBadRequest {
+		t.Fatalf("got %s %d", e.Code, e.Status())
+	}
+
+	got := e.Response(LangEnglish, "req-1").Error
+	want := []FieldError{
+		{Field: "title", Rule: "max", Param: "5", Message: "title must be at most 5 characters long"},
+		{Field: "status", Rule: "oneof", Param: "draft published", Message: "status must be one of: draft published"},
+		{Field: "tags[1]", Rule: "min", Param: "1", Message: "tags[1] must be at least 1 characters long"},
+	}
+	if got.RequestID != "req-1" || len(got.Details) != len(want) {
+		t.Fatalf("unexpected response %+v", got)
+	}
+	for i := range want {
+		if got.Details[i] != want[i] {
+			t.Errorf("detail %d = %+v, want %+v", i, got.Details[i], want[i])
+		}
+	}
+
+	zh := e.Response(LangChinese, "").Error
+	if zh.Message != "参数校验失败" || zh.Details[0].Message != "title 最多 5 个字符" {
+		t.Errorf("unexpected zh response %+v", zh)
+	}
+}
+
+func TestBindMalformedBody(t *testing.T) {
+	if e := bind(t, `{"title":`); e.Code != CodeInvalidRequest {
+		t.Errorf("syntax error: got %s", e.Code)
+	}
+	if e := bind(t, ``); e.Code != CodeInvalidRequest || e.Message != "Request body is required" {
+		t.Errorf("empty body: got %s %q", e.Code, e.Message)
+	}
+	e := bind(t, `{"title":"a","count":"1"}`)
+	if e.Code != CodeValidationFailed || len(e.Details) != 1 || e.Details[0].Field != "count" || e.Details[0].Rule != "type" {
+		t.Errorf("type error: got %+v", e)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	cause := errors.New("connection refused")
+	if e := From(cause); e.Code != CodeInternal || e.Message != "Internal server error" || !errors.Is(e, cause) {
+		t.Errorf("From(plain error) = %+v", e)
+	}
+	notFound := NotFound("Post not found")
+	if From(notFound) != notFound {
+		t.Error("From should return *Error unchanged")
+	}
+	if Code("unknown").Status() != http.StatusInternalServerError {
+		t.Error("unknown code should map to 500")
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string]Lang{
+		"":                        LangEnglish,
+		"zh-CN,zh;q=0.9,en;q=0.8": LangChinese,
+		"zh-TW":                   LangChinese,
+		"en-US,en;q=0.9,zh;q=0.8": LangEnglish,
+		"fr-FR":                   LangEnglish,
+		"not a language;;;":       LangEnglish,
+	}
+	for header, want := range cases {
+		if got := ParseAcceptLanguage(header); got != want {
+			t.Errorf("ParseAcceptLanguage(%q) = %s, want %s", header, got, want)
+		}
+	}
+	if Translate(LangChinese, "Post not found") != "文章不存在" || Translate(LangChinese, "untranslated") != "untranslated" {
+		t.Error("unexpected translation")
+	}
+}
@@ -0,0 +1,40 @@
+package apperr
+
+import "golang.org/x/text/language"
+
+// Lang 错误信息的语言
+type Lang string
+
+const (
+	LangEnglish Lang = "en"
+	LangChinese Lang = "zh"
+)
+
+// supported 与 matcher 中的语言顺序一致，第一个为默认语言
+var (
+	supported = []Lang{LangEnglish, LangChinese}
+	matcher   = language.NewMatcher([]language.Tag{language.English, language.Chinese})
+)
+
+// ParseAcceptLanguage 根据 Accept-Language 请求头选择语言，无法匹配时使用英文
+func ParseAcceptLanguage(header string) Lang {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return LangEnglish
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return LangEnglish
+	}
+	return supported[index]
+}
+
+// Translate 把英文错误信息翻译为 lang，没有翻译时原样返回
+func Translate(lang Lang, message string) string {
+	if lang == LangChinese {
+		if translated, ok := zhMessages[message]; ok {
+			return translated
+		}
+	}
+	return message
+}
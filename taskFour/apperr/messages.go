@@ -0,0 +1,132 @@
+package apperr
+
+// zhMessages 错误信息的中文翻译，键为控制器中使用的英文信息，新增错误信息时同步添加
+var zhMessages = map[string]string{
+	// 通用
+	"Internal server error":    "服务器内部错误",
+	"Database error":           "数据库错误",
+	"Validation failed":        "参数校验失败",
+	"Request body is required": "请求体不能为空",
+	"Malformed request body":   "请求体格式错误",
+	"Permission denied":        "权限不足",
+	"Too many requests":        "请求过于频繁，请稍后再试",
+	"Failed to load user role": "获取用户角色失败",
+
+	// 分页
+	"Invalid cursor":                    "无效的分页游标",
+	"cursor does not match sort":        "分页游标与 sort 不一致",
+	"cursor does not match order":       "分页游标与 order 不一致",
+	"page is not supported, use cursor": "不支持 page 参数，请使用 cursor 翻页",
+
+	// 认证
+	"Authorization header is required":                 "缺少 Authorization 请求头",
+	"Invalid token":                                    "无效的令牌",
+	"Token has been revoked":                           "令牌已被吊销",
+	"Invalid credentials":                              "用户名或密码错误",
+	"Invalid refresh token":                            "无效的刷新令牌",
+	"Refresh token reuse detected, please login again": "刷新令牌被重复使用，请重新登录",
+	"Invalid or expired nonce":                         "随机数无效或已过期",
+	"Invalid SIWE message":                             "无效的 SIWE 签名消息",
+	"SIWE message domain mismatch":                     "SIWE 签名消息的域名不匹配",
+	"SIWE message chain ID mismatch":                   "SIWE 签名消息的链 ID 不匹配",
+	"SIWE message has expired":                         "SIWE 签名消息已过期",
+	"SIWE message is not yet valid":                    "SIWE 签名消息尚未生效",
+	"Invalid signature":                                "签名无效",
+	"Username or email already exists":                 "用户名或邮箱已存在",
+	"Username is reserved for wallet accounts":         "以太坊地址形式的用户名只能由钱包登录创建",
+	"Failed to create user":                            "创建用户失败",
+	"Failed to generate token":                         "生成令牌失败",
+	"Failed to generate nonce":                         "生成随机数失败",
+	"Failed to refresh token":                          "刷新令牌失败",
+	"Failed to revoke token":                           "吊销令牌失败",
+	"Failed to revoke refresh token":                   "吊销刷新令牌失败",
+	"Failed to load user":                              "获取用户失败",
+
+	// 用户和身份
+	"Invalid user ID":                          "无效的用户ID",
+	"User not found":                           "用户不存在",
+	"You cannot change your own role":          "不能修改自己的角色",
+	"Failed to fetch user":                     "获取用户失败",
+	"Failed to fetch users":                    "获取用户列表失败",
+	"Failed to update role":                    "修改角色失败",
+	"Invalid identity ID":                      "无效的身份ID",
+	"Identity not found":                       "身份不存在",
+	"Identity is already linked to an account": "该身份已关联其他账号",
+	"Cannot remove the last login method":      "不能删除最后一种登录方式",
+	"Failed to fetch identities":               "获取身份列表失败",
+	"Failed to fetch identity":                 "获取身份失败",
+	"Failed to link identity":                  "关联身份失败",
+	"Failed to delete identity":                "删除身份失败",
+	"Password is already set":                  "账号已设置密码",
+
+	// 文章
+	"Invalid post ID":                                        "无效的文章ID",
+	"Post not found":                                         "文章不存在",
+	"You can only update your own posts":                     "只能修改自己的文章",
+	"You can only delete your own posts":                     "只能删除自己的文章",
+	"You can only restore your own posts":                    "只能回滚自己的文章",
+	"published_at must be in the future for scheduled posts": "定时发布的 published_at 必须是将来的时间",
+	"Failed to fetch post":                                   "获取文章失败",
+	"Failed to fetch posts":                                  "获取文章列表失败",
+	"Failed to create post":                                  "创建文章失败",
+	"Failed to update post":                                  "修改文章失败",
+	"Failed to delete post":                                  "删除文章失败",
+	"Post has been modified":                                 "文章已被修改",
+	"If-Match header or version is required":                 "需要 If-Match 请求头或 version 字段",
+	"Unsupported patch format":                               "不支持的补丁格式",
+	"Invalid patch document":                                 "无效的补丁文档",
+	"Patch could not be applied":                             "补丁无法应用到文章",
+	"Invalid revision number":                                "无效的版本号",
+	"Invalid revision numbers":                               "无效的版本号",
+	"Revision not found":                                     "历史版本不存在",
+	"Failed to fetch revision":                               "获取历史版本失败",
+	"Failed to fetch revisions":                              "获取历史版本列表失败",
+	"Failed to restore revision":                             "回滚历史版本失败",
+
+	// 标签和分类
+	"Invalid tag ID":               "无效的标签ID",
+	"Invalid tag name":             "无效的标签名称",
+	"Tag not found":                "标签不存在",
+	"Tag already exists":           "标签已存在",
+	"Failed to fetch tag":          "获取标签失败",
+	"Failed to fetch tags":         "获取标签列表失败",
+	"Failed to create tag":         "创建标签失败",
+	"Failed to update tag":         "修改标签失败",
+	"Failed to delete tag":         "删除标签失败",
+	"Invalid category ID":          "无效的分类ID",
+	"Invalid category name":        "无效的分类名称",
+	"Category not found":           "分类不存在",
+	"Parent category not found":    "父分类不存在",
+	"Category slug already exists": "分类 slug 已存在",
+	"Category cannot be moved under itself or its descendants": "分类不能移动到自身或其子孙分类下",
+	"Failed to fetch category":                                 "获取分类失败",
+	"Failed to fetch categories":                               "获取分类列表失败",
+	"Failed to create category":                                "创建分类失败",
+	"Failed to update category":                                "修改分类失败",
+	"Failed to delete category":                                "删除分类失败",
+
+	// 评论
+	"Invalid comment ID":                                              "无效的评论ID",
+	"Comment not found":                                               "评论不存在",
+	"Parent comment not found":                                        "回复的评论不存在",
+	"Cannot reply to a deleted comment":                               "不能回复已删除的评论",
+	"Maximum reply depth exceeded":                                    "超过最大回复层数",
+	"Comment can no longer be edited":                                 "评论已超过可修改时间",
+	"You can only edit your own comments":                             "只能修改自己的评论",
+	"You can only delete your own comments or comments on your posts": "只能删除自己的评论或自己文章下的评论",
+	"view must be flat or tree":                                       "view 必须是 flat 或 tree",
+	"status must be pending or spam":                                  "status 必须是 pending 或 spam",
+	"Failed to fetch comment":                                         "获取评论失败",
+	"Failed to fetch comments":                                        "获取评论列表失败",
+	"Failed to create comment":                                        "创建评论失败",
+	"Failed to update comment":                                        "修改评论失败",
+	"Failed to update comments":                                       "批量修改评论失败",
+	"Failed to delete comment":                                        "删除评论失败",
+	"Failed to check comment":                                         "评论反垃圾检查失败",
+
+	// 搜索
+	"Query parameter q is required": "缺少查询参数 q",
+	"Invalid search type":           "无效的搜索类型",
+	"Failed to search posts":        "搜索文章失败",
+	"Failed to search comments":     "搜索评论失败",
+}
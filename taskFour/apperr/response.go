@@ -0,0 +1,34 @@
+package apperr
+
+// Response 统一的错误响应
+type Response struct {
+	Error Body `json:"error"`
+}
+
+// Body 错误详情
+type Body struct {
+	Code      Code         `json:"code" swaggertype:"string" example:"not_found"`
+	Message   string       `json:"message" example:"Post not found"`
+	Details   []FieldError `json:"details,omitempty"`
+	Current   any          `json:"current,omitempty" swaggertype:"object"`
+	RequestID string       `json:"request_id,omitempty" example:"a5f1bf16-ac6f-484f-93f0-8d125ed79293"`
+}
+
+// Response 生成 lang 语言的错误响应
+func (e *Error) Response(lang Lang, requestID string) Response {
+	body := Body{
+		Code:      e.Code,
+		Message:   Translate(lang, e.Message),
+		Current:   e.Current,
+		RequestID: requestID,
+	}
+	for _, detail := range e.Details {
+		body.Details = append(body.Details, FieldError{
+			Field:   detail.Field,
+			Rule:    detail.Rule,
+			Param:   detail.Param,
+			Message: fieldMessage(lang, detail),
+		})
+	}
+	return Response{Error: body}
+}
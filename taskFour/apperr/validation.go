@@ -0,0 +1,129 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// 校验错误中的字段名使用 JSON 名称，与请求体一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// Bind 转换 c.ShouldBindJSON 等方法返回的错误：校验失败和字段类型错误返回 validation_failed 和各字段的详情，
+// 请求体为空或不是合法的 JSON 返回 invalid_request
+func Bind(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		e := Wrap(CodeValidationFailed, "Validation failed", err)
+		for _, fe := range validationErrs {
+			e.Details = append(e.Details, FieldError{
+				Field: fieldPath(fe.Namespace()),
+				Rule:  fe.Tag(),
+				Param: fe.Param(),
+				kind:  fe.Kind(),
+			})
+		}
+		return e
+	case errors.As(err, &typeErr):
+		e := Wrap(CodeValidationFailed, "Validation failed", err)
+		e.Details = []FieldError{{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String()}}
+		return e
+	case errors.Is(err, io.EOF):
+		return Wrap(CodeInvalidRequest, "Request body is required", err)
+	default:
+		return Wrap(CodeInvalidRequest, "Malformed request body", err)
+	}
+}
+
+// Field 单个查询参数等非请求体字段的校验错误（validation_failed），rule 和 param 与校验标签的含义相同
+func Field(field, rule, param string) *Error {
+	e := New(CodeValidationFailed, "Validation failed")
+	e.Details = []FieldError{{Field: field, Rule: rule, Param: param}}
+	return e
+}
+
+// fieldPath 去掉校验错误命名空间中的结构体名，如 CreatePostInput.tags[0] 变为 tags[0]
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// fieldMessage 字段校验错误的说明
+func fieldMessage(lang Lang, fe FieldError) string {
+	templates := fieldTemplates[LangEnglish]
+	if t, ok := fieldTemplates[lang]; ok {
+		templates = t
+	}
+
+	rule := fe.Rule
+	if rule == "min" || rule == "max" {
+		switch fe.kind {
+		case reflect.String:
+			rule += ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			rule += ".items"
+		}
+	}
+	if format, ok := templates[rule]; ok {
+		return fmt.Sprintf(format, fe.Field, fe.Param)
+	}
+	return fmt.Sprintf(templates["default"], fe.Field, fe.Rule)
+}
+
+// fieldTemplates 字段校验错误的说明模板，参数依次为字段名和规则参数
+var fieldTemplates = map[Lang]map[string]string{
+	LangEnglish: {
+		"required":      "%s is required%.0s",
+		"email":         "%s must be a valid email address%.0s",
+		"oneof":         "%s must be one of: %s",
+		"min":           "%s must be at least %s",
+		"min.string":    "%s must be at least %s characters long",
+		"min.items":     "%s must contain at least %s items",
+		"max":           "%s must be at most %s",
+		"max.string":    "%s must be at most %s characters long",
+		"max.items":     "%s must contain at most %s items",
+		"type":          "%s must be of type %s",
+		"excluded_with": "%s cannot be used together with %s",
+		"default":       "%s failed the %s check",
+	},
+	LangChinese: {
+		"required":      "%s 不能为空%.0s",
+		"email":         "%s 不是有效的邮箱地址%.0s",
+		"oneof":         "%s 必须是以下值之一：%s",
+		"min":           "%s 不能小于 %s",
+		"min.string":    "%s 至少 %s 个字符",
+		"min.items":     "%s 至少包含 %s 项",
+		"max":           "%s 不能大于 %s",
+		"max.string":    "%s 最多 %s 个字符",
+		"max.items":     "%s 最多包含 %s 项",
+		"type":          "%s 的类型应为 %s",
+		"excluded_with": "%s 不能与 %s 同时使用",
+		"default":       "%s 未通过 %s 校验",
+	},
+}
@@ -0,0 +1,4 @@
+package config
+
+// AdminUsername 启动时提升为管理员的用户名，用于初始化第一个管理员
+var AdminUsername = defaults.Auth.AdminUsername
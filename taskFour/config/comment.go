@@ -0,0 +1,7 @@
+package config
+
+// CommentMaxDepth 评论回复的最大嵌套层数，顶级评论为第 0 层
+var CommentMaxDepth = defaults.Comment.MaxDepth
+
+// CommentEditWindow 评论发表后作者可以修改的时间，0 表示不限制
+var CommentEditWindow = defaults.Comment.EditWindow
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"taskFour/ratelimit"
+)
+
+// 运行模式
+const (
+	ModeDev  = "dev"
+	ModeProd = "prod"
+)
+
+// DefaultJWTSecret 开发用的默认密钥，非 dev 模式下拒绝启动，dev 模式下启动时记录警告
+const DefaultJWTSecret = "your-super-secret-jwt-key-change-in-production"
+
+// Config 服务的全部配置。加载顺序为 默认值 < 配置文件 < 环境变量 < 命令行参数，
+// config 标签为配置文件中的键（命令行参数为 -section.key），env 标签为环境变量名，
+// secret 标签的字段在 config print 中会被隐藏
+type Config struct {
+	Mode      string          `config:"mode" env:"APP_MODE" usage:"运行模式：dev 或 prod"`
+	Server    ServerConfig    `config:"server"`
+	Log       LogConfig       `config:"log"`
+	Tracing   TracingConfig   `config:"tracing"`
+	RateLimit RateLimitConfig `config:"ratelimit"`
+	Database  DatabaseConfig  `config:"database"`
+	Auth      AuthConfig      `config:"auth"`
+	SIWE      SIWEConfig      `config:"siwe"`
+	Scheduler SchedulerConfig `config:"scheduler"`
+	Comment   CommentConfig   `config:"comment"`
+	Paging    PagingConfig    `config:"paging"`
+	Spam      SpamConfig      `config:"spam"`
+
+	file    string
+	sources map[string]string
+}
+
+// ServerConfig HTTP 服务
+type ServerConfig struct {
+	Host            string        `config:"host" env:"SERVER_HOST" usage:"监听地址，为空时监听全部网卡"`
+	Port            int           `config:"port" env:"PORT" usage:"监听端口"`
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"退出时等待进行中请求完成的最长时间"`
+	TrustedProxies  []string      `config:"trusted_proxies" env:"TRUSTED_PROXIES" usage:"可信的反向代理地址或网段（逗号分隔），只有来自这些地址的 X-Forwarded-For 才用于识别客户端 IP"`
+}
+
+// LogConfig 日志
+type LogConfig struct {
+	File       string        `config:"file" env:"LOG_FILE" usage:"日志文件，stdout 或 stderr 表示输出到标准输出或标准错误"`
+	Level      string        `config:"level" env:"LOG_LEVEL" usage:"日志级别：debug、info、warn 或 error，debug 输出全部 SQL"`
+	MaxSize    int           `config:"max_size" env:"LOG_MAX_SIZE" usage:"单个日志文件的最大大小（MB），超过后切割"`
+	MaxAge     time.Duration `config:"max_age" env:"LOG_MAX_AGE" usage:"切割出的旧日志文件保留时间（按天取整），0 表示不按时间清理"`
+	MaxBackups int           `config:"max_backups" env:"LOG_MAX_BACKUPS" usage:"保留的旧日志文件数量，0 表示不限制"`
+	Compress   bool          `config:"compress" env:"LOG_COMPRESS" usage:"是否 gzip 压缩旧日志文件"`
+	SlowQuery  time.Duration `config:"slow_query" env:"LOG_SLOW_QUERY" usage:"超过该耗时的 SQL 以 warn 级别记录，0 表示不记录慢查询"`
+}
+
+// TracingConfig OpenTelemetry 链路追踪
+type TracingConfig struct {
+	Exporter    string  `config:"exporter" env:"TRACING_EXPORTER" usage:"span 导出方式：none、otlp 或 file"`
+	Endpoint    string  `config:"endpoint" env:"TRACING_ENDPOINT" usage:"OTLP/HTTP collector 地址"`
+	File        string  `config:"file" env:"TRACING_FILE" usage:"file 导出时写入的文件"`
+	ServiceName string  `config:"service_name" env:"TRACING_SERVICE_NAME" usage:"服务名（service.name）"`
+	SampleRatio float64 `config:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"根 span 的采样比例，0 到 1"`
+}
+
+// RateLimitConfig 限流，规则格式为 "请求数/周期"，如 10/1m，0 表示不限流
+type RateLimitConfig struct {
+	Enabled  bool   `config:"enabled" env:"RATELIMIT_ENABLED" usage:"是否启用限流"`
+	Store    string `config:"store" env:"RATELIMIT_STORE" usage:"限流状态的存储：memory 或 redis"`
+	RedisURL string `config:"redis_url" env:"RATELIMIT_REDIS_URL" secret:"dsn" usage:"Redis 地址，如 redis://:password@localhost:6379/0"`
+	Auth     string `config:"auth" env:"RATELIMIT_AUTH" usage:"认证接口（登录、注册等）的限流，按客户端 IP"`
+	API      string `config:"api" env:"RATELIMIT_API" usage:"全部 /api 接口的限流，登录用户按用户，匿名用户按客户端 IP"`
+	Write    string `config:"write" env:"RATELIMIT_WRITE" usage:"发表、修改、删除文章和评论的限流，按用户"`
+}
+
+// DatabaseConfig 数据库连接
+type DatabaseConfig struct {
+	Driver                 string        `config:"driver" env:"DB_DRIVER" usage:"数据库类型：sqlite、mysql 或 postgres"`
+	DSN                    string        `config:"dsn" env:"DB_DSN" secret:"dsn" usage:"数据源"`
+	MaxIdleConns           int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"连接池中空闲连接的最大数量"`
+	MaxOpenConns           int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"最大打开连接数"`
+	ConnMaxLifetime        time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"连接可重用的最大时间"`
+	AutoMigrate            bool          `config:"auto_migrate" env:"DB_AUTO_MIGRATE" usage:"启动时是否自动执行数据库迁移"`
+	AllowPendingMigrations bool          `config:"allow_pending_migrations" env:"DB_ALLOW_PENDING_MIGRATIONS" usage:"关闭自动迁移时，是否允许在有未执行的迁移时启动"`
+}
+
+// AuthConfig 令牌和管理员
+type AuthConfig struct {
+	JWTSecret       string        `config:"jwt_secret" env:"JWT_SECRET" secret:"true" usage:"JWT 签名密钥"`
+	AccessTokenTTL  time.Duration `config:"access_token_ttl" env:"ACCESS_TOKEN_TTL" usage:"访问令牌有效期"`
+	RefreshTokenTTL time.Duration `config:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" usage:"刷新令牌有效期"`
+	AdminUsername   string        `config:"admin_username" env:"ADMIN_USERNAME" usage:"启动时提升为管理员的用户名"`
+}
+
+// SIWEConfig 钱包登录
+type SIWEConfig struct {
+	Domain   string        `config:"domain" env:"SIWE_DOMAIN" usage:"钱包登录消息中的域名"`
+	NonceTTL time.Duration `config:"nonce_ttl" env:"SIWE_NONCE_TTL" usage:"登录随机数有效期"`
+	ChainID  int           `config:"chain_id" env:"SIWE_CHAIN_ID" usage:"钱包登录消息要求的链 ID，0 表示不限制"`
+}
+
+// SchedulerConfig 定时发布调度器
+type SchedulerConfig struct {
+	Interval time.Duration `config:"interval" env:"POST_SCHEDULER_INTERVAL" usage:"定时发布调度器的最长轮询间隔"`
+}
+
+// CommentConfig 评论
+type CommentConfig struct {
+	MaxDepth   int           `config:"max_depth" env:"COMMENT_MAX_DEPTH" usage:"评论回复的最大嵌套层数"`
+	EditWindow time.Duration `config:"edit_window" env:"COMMENT_EDIT_WINDOW" usage:"评论发表后允许修改的时间，0 表示不限制"`
+}
+
+// PagingConfig 列表分页
+type PagingConfig struct {
+	MaxLimit int `config:"max_limit" env:"PAGING_MAX_LIMIT" usage:"列表接口每页数量（limit）的上限"`
+}
+
+// SpamConfig 评论反垃圾规则
+type SpamConfig struct {
+	MaxLinks         int           `config:"max_links" env:"SPAM_MAX_LINKS" usage:"一条评论允许的最多链接数"`
+	Blocklist        []string      `config:"blocklist" env:"SPAM_BLOCKLIST" usage:"屏蔽词列表（逗号分隔）"`
+	RepeatWindow     time.Duration `config:"repeat_window" env:"SPAM_REPEAT_WINDOW" usage:"重复内容检测的时间窗口"`
+	NewAccountAge    time.Duration `config:"new_account_age" env:"SPAM_NEW_ACCOUNT_AGE" usage:"注册时间短于该值的账号按新账号限流"`
+	NewAccountLimit  int           `config:"new_account_limit" env:"SPAM_NEW_ACCOUNT_LIMIT" usage:"新账号在时间窗口内可以直接发布的评论数"`
+	NewAccountWindow time.Duration `config:"new_account_window" env:"SPAM_NEW_ACCOUNT_WINDOW" usage:"新账号评论限流的时间窗口"`
+}
+
+// Default 默认配置，适合本地开发
+func Default() *Config {
+	return &Config{
+		Mode:   ModeDev,
+		Server: ServerConfig{Port: 8080, ShutdownTimeout: 15 * time.Second},
+		Log: LogConfig{
+			File:       "app.log",
+			Level:      "info",
+			MaxSize:    100,
+			MaxAge:     30 * 24 * time.Hour,
+			MaxBackups: 10,
+			SlowQuery:  200 * time.Millisecond,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			File:        "traces.json",
+			ServiceName: "blog",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   "memory",
+			Auth:    "20/1m",
+			API:     "600/1m",
+			Write:   "60/1m",
+		},
+		Database: DatabaseConfig{
+			Driver:          "sqlite",
+			DSN:             "blog.db",
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: time.Hour,
+			AutoMigrate:     true,
+		},
+		Auth: AuthConfig{
+			JWTSecret:       DefaultJWTSecret,
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		SIWE:      SIWEConfig{Domain: "localhost:8080", NonceTTL: 5 * time.Minute, ChainID: 1},
+		Scheduler: SchedulerConfig{Interval: time.Minute},
+		Comment:   CommentConfig{MaxDepth: 5, EditWindow: 15 * time.Minute},
+		Paging:    PagingConfig{MaxLimit: 100},
+		Spam: SpamConfig{
+			MaxLinks:         2,
+			RepeatWindow:     10 * time.Minute,
+			NewAccountAge:    24 * time.Hour,
+			NewAccountLimit:  3,
+			NewAccountWindow: time.Hour,
+		},
+	}
+}
+
+// defaults 未加载配置时（如测试）各个包级变量使用的默认值
+var defaults = Default()
+
+// Addr HTTP 服务监听地址
+func (s ServerConfig) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Validate 检查配置是否可用，返回全部错误
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Mode == ModeDev || c.Mode == ModeProd, "mode must be %q or %q, got %q", ModeDev, ModeProd, c.Mode)
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port must be between 1 and 65535")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Log.File != "", "log.file is required")
+	_, ok := logLevels[c.Log.Level]
+	check(ok, "log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.MaxSize > 0, "log.max_size must be positive")
+	check(c.Log.MaxAge >= 0, "log.max_age must not be negative")
+	check(c.Log.MaxBackups >= 0, "log.max_backups must not be negative")
+	check(c.Log.SlowQuery >= 0, "log.slow_query must not be negative")
+
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "otlp" || c.Tracing.Exporter == "file",
+		"tracing.exporter must be none, otlp or file, got %q", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint is required for the otlp exporter")
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file is required for the file exporter")
+	check(c.Tracing.ServiceName != "", "tracing.service_name is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	check(c.RateLimit.Store == "memory" || c.RateLimit.Store == "redis", "ratelimit.store must be memory or redis, got %q", c.RateLimit.Store)
+	check(c.RateLimit.Store != "redis" || c.RateLimit.RedisURL != "", "ratelimit.redis_url is required for the redis store")
+	for name, rule := range map[string]string{"auth": c.RateLimit.Auth, "api": c.RateLimit.API, "write": c.RateLimit.Write} {
+		_, err := ratelimit.ParseLimit(rule)
+		check(err == nil, "ratelimit.%s: %v", name, err)
+	}
+
+	check(c.Database.Driver == "sqlite" || c.Database.Driver == "mysql" || c.Database.Driver == "postgres",
+		"database.driver must be sqlite, mysql or postgres, got %q", c.Database.Driver)
+	check(c.Database.DSN != "", "database.dsn is required")
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns must be positive")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+
+	check(c.Auth.JWTSecret != "", "auth.jwt_secret is required")
+	if c.Mode != ModeDev {
+		check(c.Auth.JWTSecret != DefaultJWTSecret, "auth.jwt_secret must be changed from the default outside dev mode")
+		check(len(c.Auth.JWTSecret) >= 32, "auth.jwt_secret must be at least 32 bytes outside dev mode")
+	}
+	check(c.Auth.AccessTokenTTL > 0, "auth.access_token_ttl must be positive")
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL, "auth.refresh_token_ttl must be longer than auth.access_token_ttl")
+
+	check(c.SIWE.Domain != "", "siwe.domain is required")
+	check(c.SIWE.NonceTTL > 0, "siwe.nonce_ttl must be positive")
+	check(c.SIWE.ChainID >= 0, "siwe.chain_id must not be negative")
+	check(c.Scheduler.Interval > 0, "scheduler.interval must be positive")
+	check(c.Comment.MaxDepth >= 0, "comment.max_depth must not be negative")
+	check(c.Comment.EditWindow >= 0, "comment.edit_window must not be negative")
+	check(c.Paging.MaxLimit > 0, "paging.max_limit must be positive")
+	check(c.Spam.MaxLinks >= 0, "spam.max_links must not be negative")
+	check(c.Spam.NewAccountLimit >= 0, "spam.new_account_limit must not be negative")
+
+	return errors.Join(errs...)
+}
+
+// Apply 把配置写入各个包级变量，供其余代码读取
+func (c *Config) Apply() {
+	LogLevel = c.Log.Level
+	LogSlowQuery = c.Log.SlowQuery
+	TracingServiceName = c.Tracing.ServiceName
+
+	TrustedProxies = c.Server.TrustedProxies
+	RateLimitAuth, RateLimitAPI, RateLimitWrite = ratelimit.Limit{}, ratelimit.Limit{}, ratelimit.Limit{}
+	if c.RateLimit.Enabled {
+		RateLimitAuth, _ = ratelimit.ParseLimit(c.RateLimit.Auth)
+		RateLimitAPI, _ = ratelimit.ParseLimit(c.RateLimit.API)
+		RateLimitWrite, _ = ratelimit.ParseLimit(c.RateLimit.Write)
+	}
+
+	DBDriver = c.Database.Driver
+	DBDSN = c.Database.DSN
+	DBMaxIdleConns = c.Database.MaxIdleConns
+	DBMaxOpenConns = c.Database.MaxOpenConns
+	DBConnMaxLifetime = c.Database.ConnMaxLifetime
+	DBAutoMigrate = c.Database.AutoMigrate
+	DBAllowPendingMigrations = c.Database.AllowPendingMigrations
+
+	JWTSecret = []byte(c.Auth.JWTSecret)
+	AccessTokenTTL = c.Auth.AccessTokenTTL
+	RefreshTokenTTL = c.Auth.RefreshTokenTTL
+	AdminUsername = c.Auth.AdminUsername
+
+	SIWEDomain = c.SIWE.Domain
+	SIWENonceTTL = c.SIWE.NonceTTL
+	SIWEChainID = c.SIWE.ChainID
+	PostSchedulerInterval = c.Scheduler.Interval
+
+	CommentMaxDepth = c.Comment.MaxDepth
+	CommentEditWindow = c.Comment.EditWindow
+	PagingMaxLimit = c.Paging.MaxLimit
+
+	SpamMaxLinks = c.Spam.MaxLinks
+	SpamBlocklist = c.Spam.Blocklist
+	SpamRepeatWindow = c.Spam.RepeatWindow
+	SpamNewAccountAge = c.Spam.NewAccountAge
+	SpamNewAccountLimit = c.Spam.NewAccountLimit
+	SpamNewAccountWindow = c.Spam.NewAccountWindow
+}
@@ -1,29 +1,110 @@
 package config
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"taskFour/logging"
 
 	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
-func ConnectDatabase() error {
-	database, err := gorm.Open(sqlite.Open("blog.db"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
+// DBDriver 数据库类型：sqlite、mysql 或 postgres
+var DBDriver = defaults.Database.Driver
+
+// DBDSN 数据源，SQLite 为文件路径（:memory: 为内存库），
+// MySQL 如 user:pass@tcp(127.0.0.1:3306)/blog?charset=utf8mb4&parseTime=True&loc=Local，
+// PostgreSQL 如 host=127.0.0.1 user=blog password=blog dbname=blog port=5432 sslmode=disable
+var DBDSN = defaults.Database.DSN
+
+// 连接池设置，默认值与 taskThree 中的 initDb 一致
+var (
+	DBMaxIdleConns    = defaults.Database.MaxIdleConns
+	DBMaxOpenConns    = defaults.Database.MaxOpenConns
+	DBConnMaxLifetime = defaults.Database.ConnMaxLifetime
+)
+
+// DBAutoMigrate 启动时是否自动执行未执行的数据库迁移
+var DBAutoMigrate = defaults.Database.AutoMigrate
 
+// DBAllowPendingMigrations 关闭自动迁移时，有未执行的迁移是否仍然启动，默认拒绝启动
+var DBAllowPendingMigrations = defaults.Database.AllowPendingMigrations
+
+func ConnectDatabase() error {
+	database, err := OpenDatabase(DBDriver, DBDSN, logging.NewGormLogger(LogSlowQuery))
 	if err != nil {
 		return err
 	}
 
 	DB = database
-	log.Println("Database connection established")
+	slog.Info("Database connection established", "driver", DBDriver)
 	return nil
 }
 
+// OpenDatabase 按驱动类型打开数据库并设置连接池
+func OpenDatabase(driver, dsn string, gormLogger logger.Interface) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case "sqlite":
+		dialector = sqlite.Open(dsn)
+	case "mysql":
+		dialector = mysql.Open(dsn)
+	case "postgres":
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+
+	// 时间统一按 UTC 保存和比较，SQLite 按字符串比较时间，混用时区会得到错误的结果
+	database, err := gorm.Open(dialector, &gorm.Config{
+		Logger:  gormLogger,
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxIdleConns(DBMaxIdleConns)       // 连接池中空闲连接的最大数量
+	sqlDB.SetMaxOpenConns(DBMaxOpenConns)       // 最大打开连接数
+	sqlDB.SetConnMaxLifetime(DBConnMaxLifetime) // 连接可重用的最大时间
+	// SQLite 内存库的每个连接都是一个独立的数据库，只能使用一个连接
+	if driver == "sqlite" && strings.Contains(dsn, ":memory:") {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
+	return database, nil
+}
+
+// CloseDatabase 关闭连接池，程序退出前调用
+func CloseDatabase() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// GetDBWithContext 返回绑定 ctx 的连接，查询可随请求取消，SQL 日志带上请求 ID
+func GetDBWithContext(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx)
+}
@@ -1,12 +1,9 @@
 package config
 
-import "os"
+var JWTSecret = []byte(defaults.Auth.JWTSecret)
 
-var JWTSecret = []byte(getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"))
+// AccessTokenTTL 访问令牌有效期，过期后需要使用刷新令牌换取新令牌
+var AccessTokenTTL = defaults.Auth.AccessTokenTTL
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
+// RefreshTokenTTL 刷新令牌有效期
+var RefreshTokenTTL = defaults.Auth.RefreshTokenTTL
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// 配置值的来源
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field 配置中的一个叶子字段
+type field struct {
+	path   string // 如 database.dsn，同时是命令行参数名
+	env    string
+	secret string
+	usage  string
+	value  reflect.Value
+}
+
+// Load 依次读取默认值、配置文件、环境变量和命令行参数，返回配置和剩余的命令行参数（子命令）。
+// 配置文件由 -config 参数或 CONFIG_FILE 环境变量指定，按扩展名识别 YAML 或 TOML
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	cfg.sources = make(map[string]string)
+	fields := cfg.fields()
+
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "配置文件路径（.yaml、.yml 或 .toml），也可以用 CONFIG_FILE 环境变量指定")
+	var flagValues [][2]string
+	for _, f := range fields {
+		path := f.path
+		usage := fmt.Sprintf("%s（环境变量 %s）", f.usage, f.env)
+		set := func(value string) error {
+			flagValues = append(flagValues, [2]string{path, value})
+			return nil
+		}
+		if f.value.Kind() == reflect.Bool {
+			flags.BoolFunc(path, usage, set)
+		} else {
+			flags.Func(path, usage, set)
+		}
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags] [migrate ... | config print]\n\n优先级：命令行参数 > 环境变量 > 配置文件 > 默认值\n\n", flags.Name())
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	byPath := make(map[string]*field, len(fields))
+	for i := range fields {
+		byPath[fields[i].path] = &fields[i]
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile, byPath); err != nil {
+			return nil, nil, err
+		}
+		cfg.file = *configFile
+	}
+
+	for _, f := range fields {
+		// 设置为空字符串的环境变量同样覆盖配置文件
+		value, ok := os.LookupEnv(f.env)
+		if !ok {
+			continue
+		}
+		if err := f.set(value); err != nil {
+			return nil, nil, fmt.Errorf("environment variable %s: %w", f.env, err)
+		}
+		cfg.sources[f.path] = SourceEnv
+	}
+
+	for _, fv := range flagValues {
+		if err := byPath[fv[0]].set(fv[1]); err != nil {
+			return nil, nil, fmt.Errorf("flag -%s: %w", fv[0], err)
+		}
+		cfg.sources[fv[0]] = SourceFlag
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// File 加载的配置文件路径，没有使用配置文件时为空
+func (c *Config) File() string {
+	return c.file
+}
+
+// Source 配置项的来源：default、file、env 或 flag
+func (c *Config) Source(path string) string {
+	if source, ok := c.sources[path]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// loadFile 读取 YAML 或 TOML 配置文件，未知的键视为错误
+func (c *Config) loadFile(path string, byPath map[string]*field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	raw := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("unsupported config file format %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return c.setFromMap("", raw, byPath)
+}
+
+func (c *Config) setFromMap(prefix string, raw map[string]interface{}, byPath map[string]*field) error {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if nested, ok := raw[key].(map[string]interface{}); ok {
+			if err := c.setFromMap(path, nested, byPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f, ok := byPath[path]
+		if !ok {
+			return fmt.Errorf("unknown config key %q", path)
+		}
+		if err := f.setRaw(raw[key]); err != nil {
+			return fmt.Errorf("config key %q: %w", path, err)
+		}
+		c.sources[path] = SourceFile
+	}
+	return nil
+}
+
+// fields 按声明顺序列出全部叶子字段
+func (c *Config) fields() []field {
+	var fields []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := sf.Tag.Get("config")
+			if key == "" {
+				continue
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if sf.Type.Kind() == reflect.Struct {
+				walk(key, v.Field(i))
+				continue
+			}
+			fields = append(fields, field{
+				path:   key,
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret"),
+				usage:  sf.Tag.Get("usage"),
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return fields
+}
+
+// set 从字符串（环境变量、命令行参数）解析字段值
+func (f *field) set(s string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(s)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		f.value.SetFloat(n)
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		f.value.SetBool(b)
+	case f.value.Kind() == reflect.Slice:
+		f.value.Set(reflect.ValueOf(splitList(s)))
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// setRaw 设置配置文件中解析出的值，标量统一转换成字符串后解析
+func (f *field) setRaw(raw interface{}) error {
+	switch v := raw.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		if f.value.Kind() != reflect.Slice {
+			return fmt.Errorf("expected a single value, got a list")
+		}
+		var items []string // 空列表与环境变量的空值一致，为 nil
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		f.value.Set(reflect.ValueOf(items))
+		return nil
+	case string:
+		return f.set(v)
+	case bool, int, int64, uint64, float64:
+		return f.set(fmt.Sprint(v))
+	default:
+		return fmt.Errorf("unsupported value %v", raw)
+	}
+}
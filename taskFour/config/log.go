@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log/slog"
+
+	"taskFour/logging"
+)
+
+// LogLevel 日志级别：debug、info、warn 或 error，debug 时输出全部 SQL
+var LogLevel = defaults.Log.Level
+
+// LogSlowQuery 超过该耗时的 SQL 以 warn 级别记录
+var LogSlowQuery = defaults.Log.SlowQuery
+
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// LoggingOptions 日志输出设置，传给 logging.Setup
+func (l LogConfig) LoggingOptions() logging.Options {
+	return logging.Options{
+		File:       l.File,
+		Level:      logLevels[l.Level],
+		MaxSize:    l.MaxSize,
+		MaxAge:     l.MaxAge,
+		MaxBackups: l.MaxBackups,
+		Compress:   l.Compress,
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import "strings"
+
+// SpamMaxLinks 一条评论允许的最多链接数，超过后进入待审核队列
+var SpamMaxLinks = defaults.Spam.MaxLinks
+
+// SpamBlocklist 屏蔽词列表，命中时评论直接标记为垃圾
+var SpamBlocklist = defaults.Spam.Blocklist
+
+// SpamRepeatWindow 同一用户在该时间内重复发表相同内容视为垃圾评论
+var SpamRepeatWindow = defaults.Spam.RepeatWindow
+
+// SpamNewAccountAge 注册时间短于该值的账号按新账号限流
+var SpamNewAccountAge = defaults.Spam.NewAccountAge
+
+// SpamNewAccountLimit 新账号在 SpamNewAccountWindow 内可以直接发布的评论数，超出后进入待审核队列
+var SpamNewAccountLimit = defaults.Spam.NewAccountLimit
+
+// SpamNewAccountWindow 新账号评论限流的统计时间窗口
+var SpamNewAccountWindow = defaults.Spam.NewAccountWindow
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,4 @@
+package config
+
+// PagingMaxLimit 列表接口每页数量的上限
+var PagingMaxLimit = defaults.Paging.MaxLimit
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "******"
+
+var (
+	// URL 无法解析时（如密码中有未转义的 /）把最后一个 @ 之前的内容都当作密码，宁可多隐藏
+	urlPassword = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://[^:@/]*):.*@`)
+	// MySQL 密码中可以包含 @：取 tcp(/unix( 之前的最后一个 @，没有写协议时取第一个 @
+	mysqlPassword    = regexp.MustCompile(`^([^:@/]*):.+@((?:tcp|unix)\()`)
+	mysqlNoProtocol  = regexp.MustCompile(`^([^:@/]*):[^@]+@`)
+	postgresPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+)
+
+// Print 以 YAML 格式输出生效的配置，密钥和数据源中的密码会被隐藏，
+// 不是默认值的配置项在行尾注明来源
+func (c *Config) Print(w io.Writer) {
+	fmt.Fprintln(w, "# 优先级：命令行参数 > 环境变量 > 配置文件 > 默认值")
+	if c.file != "" {
+		fmt.Fprintf(w, "# 配置文件：%s\n", c.file)
+	}
+
+	section := ""
+	for _, f := range c.fields() {
+		key, indent := f.path, ""
+		if i := strings.IndexByte(f.path, '.'); i >= 0 {
+			if f.path[:i] != section {
+				section = f.path[:i]
+				fmt.Fprintf(w, "%s:\n", section)
+			}
+			key, indent = f.path[i+1:], "  "
+		}
+
+		line := fmt.Sprintf("%s%s: %s", indent, key, f.format())
+		switch source := c.Source(f.path); source {
+		case SourceEnv:
+			line += "  # env " + f.env
+		case SourceFlag:
+			line += "  # flag -" + f.path
+		case SourceFile:
+			line += "  # file"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// format 把字段值格式化为 YAML 标量或列表
+func (f *field) format() string {
+	switch {
+	case f.value.Type() == durationType:
+		return strconv.Quote(time.Duration(f.value.Int()).String())
+	case f.value.Kind() == reflect.String:
+		s := f.value.String()
+		switch f.secret {
+		case "true":
+			if s != "" {
+				s = redacted
+			}
+		case "dsn":
+			s = RedactDSN(s)
+		}
+		return strconv.Quote(s)
+	case f.value.Kind() == reflect.Slice:
+		items := make([]string, 0, f.value.Len())
+		for i := 0; i < f.value.Len(); i++ {
+			items = append(items, strconv.Quote(f.value.Index(i).String()))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(f.value.Interface())
+	}
+}
+
+// RedactDSN 隐藏数据源中的密码，支持 URL、MySQL（user:pass@tcp(...)）和 PostgreSQL（password=...）格式
+func RedactDSN(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); !ok {
+				return dsn
+			}
+			// url.UserPassword 会转义 *，因此在去掉用户信息的 URL 前手工拼接
+			name := url.User(u.User.Username()).String()
+			u.User = nil
+			return u.Scheme + "://" + name + ":" + redacted + "@" + strings.TrimPrefix(u.String(), u.Scheme+"://")
+		}
+		return urlPassword.ReplaceAllString(dsn, "${1}:"+redacted+"@")
+	}
+	if postgresPassword.MatchString(dsn) {
+		return postgresPassword.ReplaceAllString(dsn, "${1}"+redacted)
+	}
+	if mysqlPassword.MatchString(dsn) {
+		return mysqlPassword.ReplaceAllString(dsn, "${1}:"+redacted+"@${2}")
+	}
+	return mysqlNoProtocol.ReplaceAllString(dsn, "${1}:"+redacted+"@")
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"taskFour/ratelimit"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TrustedProxies 可信的反向代理，为空时直接使用连接的对端地址作为客户端 IP
+var TrustedProxies = defaults.Server.TrustedProxies
+
+// 各路由组的限流规则，Enabled 为 false 的规则不限流
+var (
+	RateLimitAuth  = mustParseLimit(defaults.RateLimit.Auth)
+	RateLimitAPI   = mustParseLimit(defaults.RateLimit.API)
+	RateLimitWrite = mustParseLimit(defaults.RateLimit.Write)
+)
+
+func mustParseLimit(s string) ratelimit.Limit {
+	limit, err := ratelimit.ParseLimit(s)
+	if err != nil {
+		panic(err)
+	}
+	return limit
+}
+
+// OpenStore 按配置创建限流存储，返回的 close 函数在退出时释放连接
+func (c RateLimitConfig) OpenStore(ctx context.Context) (ratelimit.Store, func() error, error) {
+	if c.Store != "redis" {
+		return ratelimit.NewMemoryStore(), func() error { return nil }, nil
+	}
+
+	opts, err := redis.ParseURL(c.RedisURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ratelimit.redis_url: %w", err)
+	}
+	client := redis.NewClient(opts)
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("connect to redis: %w", err)
+	}
+	return ratelimit.NewRedisStore(client, "blog:ratelimit:"), client.Close, nil
+}
@@ -0,0 +1,4 @@
+package config
+
+// PostSchedulerInterval 定时发布调度器的最长轮询间隔
+var PostSchedulerInterval = defaults.Scheduler.Interval
@@ -0,0 +1,10 @@
+package config
+
+// SIWEDomain 钱包登录消息中必须出现的域名（EIP-4361 domain）
+var SIWEDomain = defaults.SIWE.Domain
+
+// SIWEChainID 钱包登录消息中必须出现的链 ID（EIP-155），0 表示不限制
+var SIWEChainID = defaults.SIWE.ChainID
+
+// SIWENonceTTL 登录随机数有效期
+var SIWENonceTTL = defaults.SIWE.NonceTTL
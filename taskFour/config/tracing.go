@@ -0,0 +1,17 @@
+package config
+
+import "taskFour/tracing"
+
+// TracingServiceName 链路追踪中的服务名
+var TracingServiceName = defaults.Tracing.ServiceName
+
+// TracingOptions 链路追踪设置，传给 tracing.Setup
+func (t TracingConfig) TracingOptions() tracing.Options {
+	return tracing.Options{
+		ServiceName: t.ServiceName,
+		Exporter:    t.Exporter,
+		Endpoint:    t.Endpoint,
+		File:        t.File,
+		SampleRatio: t.SampleRatio,
+	}
+}
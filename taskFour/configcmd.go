@@ -0,0 +1,21 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"taskFour/config"
+)
+
+// runConfig 执行 config 子命令
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New("usage: config print")
+	}
+
+	cfg.Print(os.Stdout)
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration is invalid:\n%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"taskFour/apperr"
+	"taskFour/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateRoleInput 修改角色输入参数
+type UpdateRoleInput struct {
+	Role string `json:"role" binding:"required,oneof=user editor admin" example:"editor"`
+}
+
+// ModerateCommentsInput 批量审核评论输入参数
+type ModerateCommentsInput struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100" example:"1,2,3"`
+}
+
+// ListUsers 获取用户列表
+// @Summary 获取用户列表
+// @Description 分页获取用户及其角色（需要 user:manage 权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(10)
+// @Success 200 {object} map[string]interface{} "成功获取用户列表"
+// @Failure 400 {object} apperr.Response "分页参数无效"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /admin/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	page, limit, ok := pagination(c, 10)
+	if !ok {
+		return
+	}
+
+	users, err := h.users.List(c.Request.Context(), (page-1)*limit, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// UpdateUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 修改指定用户的角色，不能修改自己的角色（需要 user:manage 权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param input body UpdateRoleInput true "角色"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "用户未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	id, ok := idParam(c, "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var input UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	user, err := h.users.UpdateRole(c.Request.Context(), userID, id, input.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"user":    user,
+	})
+}
+
+// ModerateDeleteComment 删除违规评论
+// @Summary 删除违规评论
+// @Description 删除任意用户的评论，仍有回复的评论保留为墓碑（需要 comment:moderate 权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "评论ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} apperr.Response "无效的评论ID"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "评论未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /admin/comments/{id} [delete]
+func (h *CommentHandler) ModerateDeleteComment(c *gin.Context) {
+	id, ok := idParam(c, "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.comments.ModerateDelete(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// ListModerationQueue 获取评论审核队列
+// @Summary 获取评论审核队列
+// @Description 按创建时间正序获取待审核（pending）或被判定为垃圾（spam）的评论（需要 comment:moderate 权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "审核状态" Enums(pending, spam) default(pending)
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(20)
+// @Success 200 {object} map[string]interface{} "成功获取审核队列"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /admin/comments [get]
+func (h *CommentHandler) ListModerationQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.CommentStatusPending)
+	page, limit, ok := pagination(c, 20)
+	if !ok {
+		return
+	}
+
+	comments, total, err := h.comments.ModerationQueue(c.Request.Context(), status, (page-1)*limit, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": comments,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// ApproveComments 批量通过评论
+// @Summary 批量通过评论
+// @Description 将待审核或被误判为垃圾的评论标记为通过（需要 comment:moderate 权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body ModerateCommentsInput true "评论ID列表"
+// @Success 200 {object} map[string]interface{} "操作成功，返回实际更新的数量"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /admin/comments/approve [post]
+func (h *CommentHandler) ApproveComments(c *gin.Context) {
+	h.moderateComments(c, h.comments.Approve)
+}
+
+// RejectComments 批量拒绝评论
+// @Summary 批量拒绝评论
+// @Description 将待审核的评论标记为垃圾，不再公开（需要 comment:moderate 权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body ModerateCommentsInput true "评论ID列表"
+// @Success 200 {object} map[string]interface{} "操作成功，返回实际更新的数量"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /admin/comments/reject [post]
+func (h *CommentHandler) RejectComments(c *gin.Context) {
+	h.moderateComments(c, h.comments.Reject)
+}
+
+// moderateComments 对请求中的评论执行审核操作，状态不符合的评论被忽略
+func (h *CommentHandler) moderateComments(c *gin.Context, moderate func(ctx context.Context, ids []uint) (int64, error)) {
+	var input ModerateCommentsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	updated, err := moderate(c.Request.Context(), input.IDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comments updated successfully",
+		"updated": updated,
+	})
+}
@@ -2,14 +2,25 @@ package controllers
 
 import (
 	"net/http"
-	"taskFour/config"
+	"taskFour/apperr"
 	"taskFour/middleware"
 	"taskFour/models"
+	"taskFour/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// UserHandler 注册、登录、令牌和用户管理接口
+type UserHandler struct {
+	users  *services.UserService
+	tokens *services.TokenService
+}
+
+// NewUserHandler 创建用户接口
+func NewUserHandler(users *services.UserService, tokens *services.TokenService) *UserHandler {
+	return &UserHandler{users: users, tokens: tokens}
+}
+
 // RegisterInput 注册输入参数
 type RegisterInput struct {
 	Username string `json:"username" binding:"required,min=3,max=100" example:"testuser"`
@@ -23,14 +34,33 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required" example:"password123"`
 }
 
+// RefreshInput 刷新令牌输入参数
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"q3V1c2VyLXJlZnJlc2gtdG9rZW4..."`
+}
+
+// LogoutInput 登出输入参数，携带刷新令牌时一并吊销
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token" example:"q3V1c2VyLXJlZnJlc2gtdG9rZW4..."`
+}
+
+// TokenResponse 令牌响应
+type TokenResponse struct {
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"q3V1c2VyLXJlZnJlc2gtdG9rZW4..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+}
+
 // LoginResponse 登录响应
 type LoginResponse struct {
 	Message string `json:"message" example:"Login successful"`
-	Token   string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User    struct {
-		ID       uint   `json:"id" example:"1"`
-		Username string `json:"username" example:"testuser"`
-		Email    string `json:"email" example:"test@example.com"`
+	TokenResponse
+	User struct {
+		ID       uint    `json:"id" example:"1"`
+		Username string  `json:"username" example:"testuser"`
+		Email    *string `json:"email" example:"test@example.com"`
+		Role     string  `json:"role" example:"user"`
 	} `json:"user"`
 }
 
@@ -42,36 +72,19 @@ type LoginResponse struct {
 // @Produce json
 // @Param input body RegisterInput true "注册信息"
 // @Success 201 {object} map[string]interface{} "注册成功"
-// @Failure 400 {object} map[string]interface{} "请求参数错误"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /auth/register [post]
-func Register(c *gin.Context) {
-	// 原有实现保持不变...
+func (h *UserHandler) Register(c *gin.Context) {
 	var input RegisterInput
-
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// 检查用户是否已存在
-	var existingUser models.User
-	if err := config.GetDB().Where("username = ? OR email = ?", input.Username, input.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username or email already exists"})
-		return
-	} else if err != gorm.ErrRecordNotFound {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		c.Error(apperr.Bind(err))
 		return
 	}
 
-	user := models.User{
-		Username: input.Username,
-		Password: input.Password,
-		Email:    input.Email,
-	}
-
-	if err := config.GetDB().Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	user, err := h.users.Register(c.Request.Context(), input.Username, input.Password, input.Email)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -81,6 +94,7 @@ func Register(c *gin.Context) {
 			"id":       user.ID,
 			"username": user.Username,
 			"email":    user.Email,
+			"role":     user.Role,
 		},
 	})
 }
@@ -93,47 +107,108 @@ func Register(c *gin.Context) {
 // @Produce json
 // @Param input body LoginInput true "登录信息"
 // @Success 200 {object} LoginResponse "登录成功"
-// @Failure 400 {object} map[string]interface{} "请求参数错误"
-// @Failure 401 {object} map[string]interface{} "认证失败"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "认证失败"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /auth/login [post]
-func Login(c *gin.Context) {
-	// 原有实现保持不变...
+func (h *UserHandler) Login(c *gin.Context) {
 	var input LoginInput
-
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperr.Bind(err))
 		return
 	}
 
-	var user models.User
-	if err := config.GetDB().Where("username = ?", input.Username).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	user, err := h.users.Authenticate(c.Request.Context(), input.Username, input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pair, err := h.tokens.Issue(c.Request.Context(), user.ID)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := user.CheckPassword(input.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	c.JSON(http.StatusOK, newLoginResponse(user, pair))
+}
+
+// Refresh 刷新令牌
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌立即失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param input body RefreshInput true "刷新令牌"
+// @Success 200 {object} TokenResponse "刷新成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "刷新令牌无效或已被重复使用"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /auth/refresh [post]
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.ID)
+	pair, err := h.tokens.Refresh(c.Request.Context(), input.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTokenResponse(pair))
+}
+
+// Logout 用户登出
+// @Summary 用户登出
+// @Description 吊销当前访问令牌，携带刷新令牌时同时吊销该次登录的所有刷新令牌（需要认证）
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body LogoutInput false "刷新令牌"
+// @Success 200 {object} map[string]interface{} "登出成功"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	claims := c.MustGet("claims").(*middleware.Claims)
+
+	var input LogoutInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(apperr.Bind(err))
+			return
+		}
+	}
+
+	if err := h.tokens.Revoke(c.Request.Context(), claims, input.RefreshToken); err != nil {
+		c.Error(err)
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+}
+
+func newLoginResponse(user *models.User, pair *services.TokenPair) LoginResponse {
 	response := LoginResponse{
-		Message: "Login successful",
-		Token:   token,
+		Message:       "Login successful",
+		TokenResponse: newTokenResponse(pair),
 	}
 	response.User.ID = user.ID
 	response.User.Username = user.Username
 	response.User.Email = user.Email
+	response.User.Role = user.Role
+	return response
+}
 
-	c.JSON(http.StatusOK, response)
+func newTokenResponse(pair *services.TokenPair) TokenResponse {
+	return TokenResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    pair.ExpiresIn,
+	}
 }
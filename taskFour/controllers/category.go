@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"net/http"
+	"taskFour/apperr"
+	"taskFour/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TaxonomyHandler 分类和标签接口
+type TaxonomyHandler struct {
+	taxonomy *services.TaxonomyService
+}
+
+// NewTaxonomyHandler 创建分类和标签接口
+func NewTaxonomyHandler(taxonomy *services.TaxonomyService) *TaxonomyHandler {
+	return &TaxonomyHandler{taxonomy: taxonomy}
+}
+
+// CategoryInput 创建/修改分类输入参数
+type CategoryInput struct {
+	Name     string `json:"name" binding:"required,max=50" example:"区块链"`
+	Slug     string `json:"slug" binding:"omitempty,max=60" example:"blockchain"`
+	ParentID *uint  `json:"parent_id" example:"1"`
+}
+
+// ListCategories 获取分类树
+// @Summary 获取分类树
+// @Description 获取全部分类，按父子关系组装成树
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "成功获取分类树"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /categories [get]
+func (h *TaxonomyHandler) ListCategories(c *gin.Context) {
+	tree, err := h.taxonomy.CategoryTree(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": tree})
+}
+
+// CreateCategory 创建分类
+// @Summary 创建分类
+// @Description 创建分类，可指定父分类，未指定 slug 时由名称生成（需要 taxonomy:manage 权限）
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body CategoryInput true "分类信息"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 409 {object} apperr.Response "slug 已存在"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /categories [post]
+func (h *TaxonomyHandler) CreateCategory(c *gin.Context) {
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	category, err := h.taxonomy.CreateCategory(c.Request.Context(), input.Name, input.Slug, input.ParentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Category created successfully",
+		"category": category,
+	})
+}
+
+// UpdateCategory 修改分类
+// @Summary 修改分类
+// @Description 修改分类名称、slug 和父分类，不能移动到自身或子孙分类下（需要 taxonomy:manage 权限）
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "分类ID"
+// @Param input body CategoryInput true "分类信息"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "分类未找到"
+// @Failure 409 {object} apperr.Response "slug 已存在"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /categories/{id} [put]
+func (h *TaxonomyHandler) UpdateCategory(c *gin.Context) {
+	id, ok := idParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	category, err := h.taxonomy.UpdateCategory(c.Request.Context(), id, input.Name, input.Slug, input.ParentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Category updated successfully",
+		"category": category,
+	})
+}
+
+// DeleteCategory 删除分类
+// @Summary 删除分类
+// @Description 删除分类，子分类和文章移动到被删除分类的父分类下（需要 taxonomy:manage 权限）
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "分类ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} apperr.Response "无效的分类ID"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "分类未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /categories/{id} [delete]
+func (h *TaxonomyHandler) DeleteCategory(c *gin.Context) {
+	id, ok := idParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	if err := h.taxonomy.DeleteCategory(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
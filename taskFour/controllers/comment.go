@@ -2,99 +2,206 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
-	"taskFour/config"
+	"taskFour/apperr"
+	"taskFour/middleware"
 	"taskFour/models"
+	"taskFour/repositories"
+	"taskFour/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// CommentHandler 评论和评论审核接口
+type CommentHandler struct {
+	comments *services.CommentService
+}
+
+// NewCommentHandler 创建评论接口
+func NewCommentHandler(comments *services.CommentService) *CommentHandler {
+	return &CommentHandler{comments: comments}
+}
+
 // CreateCommentInput 创建评论输入参数
 type CreateCommentInput struct {
-	Content string `json:"content" binding:"required,min=1" example:"这是一条评论"`
-	PostID  uint   `json:"post_id" binding:"required" example:"1"`
+	Content  string `json:"content" binding:"required,min=1" example:"这是一条评论"`
+	PostID   uint   `json:"post_id" binding:"required" example:"1"`
+	ParentID *uint  `json:"parent_id" example:"1"`
+}
+
+// UpdateCommentInput 修改评论输入参数
+type UpdateCommentInput struct {
+	Content string `json:"content" binding:"required,min=1" example:"修改后的评论"`
 }
 
 // CreateComment 创建评论
 // @Summary 创建评论
-// @Description 对文章发表评论（需要认证）
+// @Description 对文章发表评论，指定 parent_id 时回复该评论，嵌套层数不能超过 COMMENT_MAX_DEPTH（需要认证）
+// @Description 评论先经过反垃圾检查，status 为 pending 时需要审核通过后才会公开，为 spam 时不会公开
 // @Tags 评论
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body CreateCommentInput true "评论内容"
 // @Success 201 {object} map[string]interface{} "评论创建成功"
-// @Failure 400 {object} map[string]interface{} "请求参数错误"
-// @Failure 401 {object} map[string]interface{} "未认证"
-// @Failure 404 {object} map[string]interface{} "文章未找到"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 404 {object} apperr.Response "文章或父评论未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /comments [post]
-func CreateComment(c *gin.Context) {
-	// 原有实现保持不变...
+func (h *CommentHandler) CreateComment(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 
 	var input CreateCommentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperr.Bind(err))
 		return
 	}
 
-	// 检查文章是否存在
-	var post models.Post
-	if err := config.GetDB().First(&post, input.PostID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post"})
+	comment, err := h.comments.Create(c.Request.Context(), userID, services.CreateCommentParams{
+		Content:  input.Content,
+		PostID:   input.PostID,
+		ParentID: input.ParentID,
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	comment := models.Comment{
-		Content: input.Content,
-		UserID:  userID,
-		PostID:  input.PostID,
-	}
-
-	if err := config.GetDB().Create(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
-		return
+	message := "Comment created successfully"
+	if comment.Status != models.CommentStatusApproved {
+		message = "Comment is awaiting moderation"
 	}
-
-	// 重新加载以获取用户信息
-	config.GetDB().Preload("User").First(&comment, comment.ID)
-
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Comment created successfully",
+		"message": message,
 		"comment": comment,
 	})
 }
 
+// CommentsResponse 文章评论列表响应
+type CommentsResponse struct {
+	Comments []models.Comment `json:"comments"`
+	PageInfo
+}
+
 // GetPostComments 获取文章评论
 // @Summary 获取文章评论列表
-// @Description 获取指定文章的所有评论
+// @Description 获取指定文章审核通过的评论。按顶级评论分页，每页包含顶级评论及其全部回复，total 为顶级评论数，游标用法与文章列表相同。
+// @Description view=flat（默认）按对话顺序返回平铺列表，用 depth 表示层级；view=tree 返回嵌套的 replies。
+// @Description 顶级评论默认最新的在前，回复按时间正序；已删除但仍有回复的评论保留为 deleted=true 的占位
 // @Tags 评论
 // @Accept json
 // @Produce json
 // @Param id path int true "文章ID"
-// @Success 200 {object} map[string]interface{} "成功获取评论列表"
-// @Failure 400 {object} map[string]interface{} "无效的文章ID"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Param view query string false "返回格式" Enums(flat, tree) default(flat)
+// @Param cursor query string false "分页游标"
+// @Param page query int false "已废弃，页码，不能与 cursor 同时使用"
+// @Param limit query int false "每页顶级评论数，最大为 PAGING_MAX_LIMIT" default(20)
+// @Param sort query string false "排序字段" Enums(created_at) default(created_at)
+// @Param order query string false "顶级评论的排序方向" Enums(asc, desc) default(desc)
+// @Param total query bool false "是否返回顶级评论总数"
+// @Success 200 {object} CommentsResponse "成功获取评论列表"
+// @Header 200 {string} Link "下一页和上一页的链接"
+// @Failure 400 {object} apperr.Response "无效的文章ID或分页参数"
+// @Failure 404 {object} apperr.Response "文章未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /posts/{id}/comments [get]
-func GetPostComments(c *gin.Context) {
-	// 原有实现保持不变...
-	id, err := strconv.Atoi(c.Param("id"))
+func (h *CommentHandler) GetPostComments(c *gin.Context) {
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	view := c.DefaultQuery("view", "flat")
+	if view != "flat" && view != "tree" {
+		c.Error(apperr.BadRequest("view must be flat or tree"))
+		return
+	}
+	req, ok := pageRequest(c, []string{repositories.CommentSortCreatedAt}, repositories.CommentSortCreatedAt, 20)
+	if !ok {
+		return
+	}
+
+	viewerID, _ := middleware.CurrentUserID(c)
+	page, err := h.comments.ListForPost(c.Request.Context(), viewerID, id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	comments := models.CommentTree(page.Items)
+	if view == "flat" {
+		comments = models.FlattenCommentTree(comments)
+	}
+	c.JSON(http.StatusOK, CommentsResponse{Comments: comments, PageInfo: pageInfo(c, page, req.Limit)})
+}
+
+// UpdateComment 修改评论
+// @Summary 修改评论
+// @Description 评论作者在发表后 COMMENT_EDIT_WINDOW 时间内可以修改内容，修改后 edited_at 记录修改时间，新内容会重新经过反垃圾检查（需要认证）
+// @Tags 评论
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "评论ID"
+// @Param input body UpdateCommentInput true "评论内容"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "不是评论作者或已超过修改时限"
+// @Failure 404 {object} apperr.Response "评论未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /comments/{id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	id, ok := idParam(c, "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	var input UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	comment, err := h.comments.Update(c.Request.Context(), userID, id, input.Content)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment updated successfully",
+		"comment": comment,
+	})
+}
+
+// DeleteComment 删除评论
+// @Summary 删除评论
+// @Description 评论作者、文章作者或拥有 comment:moderate 权限的用户可以删除评论，仍有回复的评论保留为墓碑（需要认证）
+// @Tags 评论
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "评论ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} apperr.Response "无效的评论ID"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "评论未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	id, ok := idParam(c, "Invalid comment ID")
+	if !ok {
 		return
 	}
 
-	var comments []models.Comment
-	if err := config.GetDB().Preload("User").Where("post_id = ?", id).Order("created_at desc").Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+	if err := h.comments.Delete(c.Request.Context(), userID, id); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"comments": comments})
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
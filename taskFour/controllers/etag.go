@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"taskFour/apperr"
+	"taskFour/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postETag 文章的实体标签 "<文章ID>-<版本号>"，用于修改文章的响应和 If-Match
+func postETag(post *models.Post) string {
+	return fmt.Sprintf(`"%d-%d"`, post.ID, post.Version)
+}
+
+// postReadETag 文章详情的实体标签 "<文章ID>-<版本号>-<评论指纹>"。详情中带有审核通过的评论，
+// 评论的新增、审核、修改和删除都会改变指纹；作为 If-Match 时只比较版本号
+func postReadETag(post *models.Post) string {
+	h := fnv.New32a()
+	for _, comment := range post.Comments {
+		var edited int64
+		if comment.EditedAt != nil {
+			edited = comment.EditedAt.UnixNano()
+		}
+		fmt.Fprintf(h, "%d:%t:%d;", comment.ID, comment.Deleted, edited)
+	}
+	return fmt.Sprintf(`"%d-%d-%08x"`, post.ID, post.Version, h.Sum32())
+}
+
+// notModified If-None-Match 请求头为 * 或包含 etag 时返回 true，按弱比较忽略 W/ 前缀
+func notModified(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion 修改文章 id 基于的版本号，取自 If-Match 请求头，没有该请求头时取请求体中的 version，
+// 两者都没有时返回 nil。If-Match 为 * 时 anyVersion 为 true，表示不检查版本；只支持一个实体标签，
+// postETag 和 postReadETag 都可以使用，忽略评论指纹；
+// 无法解析或不属于这篇文章的标签（包括弱标签）按版本 0 处理，不会与任何文章匹配
+func ifMatchVersion(c *gin.Context, id uint, version *uint) (_ *uint, anyVersion bool) {
+	header, ok := c.Request.Header["If-Match"]
+	if !ok {
+		return version, false
+	}
+
+	tag := strings.TrimSpace(strings.Join(header, ","))
+	if tag == "*" {
+		return nil, true
+	}
+	var parsed uint
+	if value, ok := strings.CutPrefix(tag, `"`); ok {
+		tagID, rest, _ := strings.Cut(strings.TrimSuffix(value, `"`), "-")
+		tagVersion, _, _ := strings.Cut(rest, "-")
+		if tagID == strconv.FormatUint(uint64(id), 10) {
+			if v, err := strconv.ParseUint(tagVersion, 10, 0); err == nil {
+				parsed = uint(v)
+			}
+		}
+	}
+	return &parsed, false
+}
+
+// setConflictETag 版本冲突时把文章当前状态的实体标签写入 ETag 响应头，便于客户端基于最新版本重试
+func setConflictETag(c *gin.Context, err error) {
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperr.CodePreconditionFailed {
+		return
+	}
+	if post, ok := appErr.Current.(*models.Post); ok {
+		c.Header("ETag", postETag(post))
+	}
+}
@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"taskFour/config"
+	"taskFour/middleware"
+	"taskFour/models"
+	"taskFour/moderation"
+	"taskFour/repositories/memory"
+	"taskFour/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 处理器测试使用内存仓储，不依赖数据库；X-User-ID 请求头代替访问令牌
+
+type testAPI struct {
+	t      *testing.T
+	store  *memory.Store
+	router *gin.Engine
+}
+
+func newTestAPI(t *testing.T, check moderation.CheckFunc) *testAPI {
+	gin.SetMode(gin.TestMode)
+	if check == nil {
+		check = func(context.Context, *models.Comment, *models.User) (moderation.Result, error) {
+			return moderation.Approved, nil
+		}
+	}
+
+	store := memory.New()
+	users, posts, comments := store.Users(), store.Posts(), store.Comments()
+	userHandler := NewUserHandler(services.NewUserService(users), services.NewTokenService(store.Tokens()))
+	postHandler := NewPostHandler(services.NewPostService(posts, comments, users))
+	commentHandler := NewCommentHandler(services.NewCommentService(comments, posts, users, check))
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(), func(c *gin.Context) {
+		if id, err := strconv.ParseUint(c.GetHeader("X-User-ID"), 10, 0); err == nil {
+			c.Set("user_id", uint(id))
+		}
+	})
+	router.POST("/auth/register", userHandler.Register)
+	router.POST("/auth/login", userHandler.Login)
+	router.POST("/auth/refresh", userHandler.Refresh)
+	router.GET("/posts", postHandler.GetPosts)
+	router.POST("/posts", postHandler.CreatePost)
+	router.GET("/posts/:id", postHandler.GetPost)
+	router.PUT("/posts/:id", postHandler.UpdatePost)
+	router.DELETE("/posts/:id", postHandler.DeletePost)
+	router.GET("/posts/:id/comments", commentHandler.GetPostComments)
+	router.POST("/comments", commentHandler.CreateComment)
+	router.PUT("/comments/:id", commentHandler.UpdateComment)
+	router.DELETE("/comments/:id", commentHandler.DeleteComment)
+	router.GET("/users/me/posts", postHandler.GetMyPosts)
+	router.GET("/posts/:id/revisions", postHandler.ListRevisions)
+	router.GET("/posts/:id/revisions/diff", postHandler.DiffRevisions)
+	router.POST("/posts/:id/revisions/:rev/restore", postHandler.RestoreRevision)
+	router.PUT("/admin/users/:id/role", userHandler.UpdateUserRole)
+	router.GET("/admin/comments", commentHandler.ListModerationQueue)
+	router.POST("/admin/comments/approve", commentHandler.ApproveComments)
+
+	return &testAPI{t: t, store: store, router: router}
+}
+
+// user 直接在仓储中创建指定角色的用户
+func (a *testAPI) user(username, role string) uint {
+	user := models.User{Username: username, Password: "password123", Role: role}
+	if err := a.store.Users().Create(context.Background(), &user); err != nil {
+		a.t.Fatalf("create user %s: %v", username, err)
+	}
+	return user.ID
+}
+
+// do 发送请求并检查状态码，userID 为 0 时不携带用户
+func (a *testAPI) do(method, path string, userID uint, body interface{}, want int) map[string]interface{} {
+	a.t.Helper()
+	return a.doWithHeader(method, path, userID, body, nil, want)
+}
+
+// doWithHeader 与 do 相同，header 中的值加入请求头
+func (a *testAPI) doWithHeader(method, path string, userID uint, body interface{}, header http.Header, want int) map[string]interface{} {
+	a.t.Helper()
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != 0 {
+		req.Header.Set("X-User-ID", strconv.FormatUint(uint64(userID), 10))
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if w.Code != want {
+		a.t.Fatalf("%s %s: status %d, want %d: %s", method, path, w.Code, want, w.Body.String())
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		a.t.Fatalf("%s %s: invalid JSON %q", method, path, w.Body.String())
+	}
+	return result
+}
+
+func (a *testAPI) createPost(authorID uint, input map[string]interface{}) string {
+	a.t.Helper()
+	result := a.do(http.MethodPost, "/posts", authorID, input, http.StatusCreated)
+	return strconv.Itoa(int(result["post"].(map[string]interface{})["id"].(float64)))
+}
+
+func errorCode(result map[string]interface{}) string {
+	return result["error"].(map[string]interface{})["code"].(string)
+}
+
+func TestPostRules(t *testing.T) {
+	api := newTestAPI(t, nil)
+	author := api.user("author", models.RoleUser)
+	other := api.user("other", models.RoleUser)
+	editor := api.user("editor", models.RoleEditor)
+
+	parent := api.store.AddCategory("技术", "tech", nil)
+	child := api.store.AddCategory("Go", "go", &parent.ID)
+
+	draft := api.createPost(author, map[string]interface{}{"title": "草稿", "content": "内容", "category_id": child.ID, "tags": []string{"Go"}})
+
+	// 草稿只有作者和编辑可见
+	api.do(http.MethodGet, "/posts/"+draft, 0, nil, http.StatusNotFound)
+	api.do(http.MethodGet, "/posts/"+draft, other, nil, http.StatusNotFound)
+	api.do(http.MethodGet, "/posts/"+draft, author, nil, http.StatusOK)
+	api.do(http.MethodGet, "/posts/"+draft, editor, nil, http.StatusOK)
+	api.do(http.MethodGet, "/posts/"+draft+"/revisions", other, nil, http.StatusNotFound)
+	api.do(http.MethodGet, "/posts/"+draft+"/revisions", author, nil, http.StatusOK)
+
+	// 恢复历史版本只有作者可以操作，且与修改一样需要指定版本
+	api.do(http.MethodPost, "/posts/"+draft+"/revisions/1/restore", editor, nil, http.StatusForbidden)
+	api.do(http.MethodPost, "/posts/"+draft+"/revisions/1/restore", author, nil, http.StatusPreconditionRequired)
+
+	// 只有作者或拥有 post:update:any 权限的用户可以修改
+	result := api.do(http.MethodPut, "/posts/"+draft, other, map[string]interface{}{"title": "篡改"}, http.StatusForbidden)
+	if code := errorCode(result); code != "forbidden" {
+		t.Fatalf("error code = %q, want forbidden", code)
+	}
+	api.do(http.MethodPut, "/posts/"+draft, editor, map[string]interface{}{"title": "编辑修改"}, http.StatusPreconditionRequired)
+	result = api.do(http.MethodPut, "/posts/"+draft, editor, map[string]interface{}{"title": "编辑修改", "status": "published", "version": 1}, http.StatusOK)
+	post := result["post"].(map[string]interface{})
+	if post["title"] != "编辑修改" || post["status"] != "published" || post["published_at"] == nil || post["version"] != float64(2) {
+		t.Fatalf("updated post = %v", post)
+	}
+
+	// 基于旧版本的修改返回 412 和文章的当前状态
+	result = api.do(http.MethodPut, "/posts/"+draft, author, map[string]interface{}{"title": "作者修改", "version": 1}, http.StatusPreconditionFailed)
+	current := result["error"].(map[string]interface{})["current"].(map[string]interface{})
+	if errorCode(result) != "precondition_failed" || current["title"] != "编辑修改" || current["version"] != float64(2) {
+		t.Fatalf("conflict = %v", result)
+	}
+
+	// 公开列表按分类过滤时包含子分类
+	result = api.do(http.MethodGet, "/posts?category=tech", 0, nil, http.StatusOK)
+	if n := len(result["posts"].([]interface{})); n != 1 {
+		t.Fatalf("posts in category = %d, want 1", n)
+	}
+	result = api.do(http.MethodGet, "/posts?tag=rust", 0, nil, http.StatusOK)
+	if n := len(result["posts"].([]interface{})); n != 0 {
+		t.Fatalf("posts with tag rust = %d, want 0", n)
+	}
+
+	// 输入错误
+	missing := uint(9999)
+	api.do(http.MethodPost, "/posts", author, map[string]interface{}{"title": "t", "content": "c", "category_id": missing}, http.StatusBadRequest)
+	api.do(http.MethodPost, "/posts", author, map[string]interface{}{"title": "t", "content": "c", "tags": []string{"!!!"}}, http.StatusBadRequest)
+	api.do(http.MethodPost, "/posts", author, map[string]interface{}{"title": "t", "content": "c", "status": "scheduled"}, http.StatusBadRequest)
+	api.do(http.MethodGet, "/posts/abc", 0, nil, http.StatusBadRequest)
+
+	result = api.do(http.MethodGet, "/users/me/posts", author, nil, http.StatusOK)
+	if n := len(result["posts"].([]interface{})); n != 1 {
+		t.Fatalf("my posts = %d, want 1", n)
+	}
+
+	api.do(http.MethodDelete, "/posts/"+draft, other, nil, http.StatusForbidden)
+	api.do(http.MethodDelete, "/posts/"+draft, author, nil, http.StatusOK)
+	api.do(http.MethodGet, "/posts/"+draft, author, nil, http.StatusNotFound)
+}
+
+func TestRevisionRules(t *testing.T) {
+	api := newTestAPI(t, nil)
+	author := api.user("author", models.RoleUser)
+	id := api.createPost(author, map[string]interface{}{"title": "第一版", "content": "内容", "status": "published"})
+
+	// 只修改状态不产生新版本，修改标题或内容时保存新版本
+	api.do(http.MethodPut, "/posts/"+id, author, map[string]interface{}{"status": "archived", "version": 1}, http.StatusOK)
+	api.do(http.MethodPut, "/posts/"+id, author, map[string]interface{}{"title": "第二版", "status": "published", "version": 2}, http.StatusOK)
+	revisions := api.do(http.MethodGet, "/posts/"+id+"/revisions", 0, nil, http.StatusOK)["revisions"].([]interface{})
+	if len(revisions) != 2 {
+		t.Fatalf("revisions = %v", revisions)
+	}
+	latest := revisions[0].(map[string]interface{})
+	if latest["rev"] != float64(2) || latest["title"] != "第二版" || latest["editor"].(map[string]interface{})["username"] != "author" {
+		t.Fatalf("latest revision = %v", latest)
+	}
+	api.do(http.MethodGet, "/posts/"+id+"/revisions/diff?from=1&to=2", 0, nil, http.StatusOK)
+	api.do(http.MethodGet, "/posts/"+id+"/revisions/diff?from=1&to=9", 0, nil, http.StatusNotFound)
+
+	// 恢复基于旧版本时返回 412，成功后生成注明来源的新版本
+	api.doWithHeader(http.MethodPost, "/posts/"+id+"/revisions/1/restore", author, nil, http.Header{"If-Match": {`"` + id + `-2"`}}, http.StatusPreconditionFailed)
+	result := api.doWithHeader(http.MethodPost, "/posts/"+id+"/revisions/1/restore", author, nil, http.Header{"If-Match": {`"` + id + `-3"`}}, http.StatusOK)
+	if post := result["post"].(map[string]interface{}); post["title"] != "第一版" || post["version"] != float64(4) {
+		t.Fatalf("restored post = %v", post)
+	}
+	revisions = api.do(http.MethodGet, "/posts/"+id+"/revisions", 0, nil, http.StatusOK)["revisions"].([]interface{})
+	if latest := revisions[0].(map[string]interface{}); len(revisions) != 3 || latest["restored_from"] != float64(1) {
+		t.Fatalf("revisions after restore = %v", revisions)
+	}
+	api.doWithHeader(http.MethodPost, "/posts/"+id+"/revisions/9/restore", author, nil, http.Header{"If-Match": {"*"}}, http.StatusNotFound)
+}
+
+func TestPaging(t *testing.T) {
+	api := newTestAPI(t, nil)
+	author := api.user("author", models.RoleUser)
+	for _, title := range []string{"b", "a", "c", "a", "d"} {
+		api.createPost(author, map[string]interface{}{"title": title, "content": "内容", "status": "published"})
+	}
+
+	titles := func(result map[string]interface{}) string {
+		var titles []string
+		for _, p := range result["posts"].([]interface{}) {
+			post := p.(map[string]interface{})
+			titles = append(titles, post["title"].(string)+strconv.Itoa(int(post["id"].(float64))))
+		}
+		return strings.Join(titles, ",")
+	}
+
+	// 标题相同时按 ID 排序，向后翻页再向前翻页得到相同的结果
+	var pages []string
+	result := api.do(http.MethodGet, "/posts?sort=title&limit=2&total=true", 0, nil, http.StatusOK)
+	if result["total"] != float64(5) || result["prev_cursor"] != nil {
+		t.Fatalf("first page: total %v, prev_cursor %v", result["total"], result["prev_cursor"])
+	}
+	for {
+		pages = append(pages, titles(result))
+		next, ok := result["next_cursor"].(string)
+		if !ok {
+			break
+		}
+		result = api.do(http.MethodGet, "/posts?limit=2&cursor="+next, 0, nil, http.StatusOK)
+	}
+	if got, want := strings.Join(pages, "|"), "a3,a5|b2,c4|d6"; got != want {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+	result = api.do(http.MethodGet, "/posts?limit=2&cursor="+result["prev_cursor"].(string), 0, nil, http.StatusOK)
+	if got := titles(result); got != "b2,c4" {
+		t.Fatalf("previous page = %q, want b2,c4", got)
+	}
+
+	// 已废弃的页码分页与游标翻页的结果相同
+	if got := titles(api.do(http.MethodGet, "/posts?sort=title&limit=2&page=2", 0, nil, http.StatusOK)); got != "b2,c4" {
+		t.Fatalf("page 2 = %q, want b2,c4", got)
+	}
+
+	for _, query := range []string{"limit=0", "limit=101", "page=0", "page=1&cursor=abc", "cursor=not-a-cursor", "sort=views", "order=random"} {
+		api.do(http.MethodGet, "/posts?"+query, 0, nil, http.StatusBadRequest)
+	}
+
+	// 评论按顶级评论分页，回复跟随所属的顶级评论
+	post := api.createPost(author, map[string]interface{}{"title": "评论", "content": "内容", "status": "published"})
+	postID := mustAtoi(t, post)
+	var roots []float64
+	for i := 0; i < 3; i++ {
+		comment := api.do(http.MethodPost, "/comments", author, map[string]interface{}{"content": "评论", "post_id": postID}, http.StatusCreated)
+		roots = append(roots, comment["comment"].(map[string]interface{})["id"].(float64))
+	}
+	api.do(http.MethodPost, "/comments", author, map[string]interface{}{"content": "回复", "post_id": postID, "parent_id": roots[0]}, http.StatusCreated)
+
+	result = api.do(http.MethodGet, "/posts/"+post+"/comments?limit=2", 0, nil, http.StatusOK)
+	if n := len(result["comments"].([]interface{})); n != 2 {
+		t.Fatalf("comments on first page = %d, want 2", n)
+	}
+	result = api.do(http.MethodGet, "/posts/"+post+"/comments?limit=2&cursor="+result["next_cursor"].(string), 0, nil, http.StatusOK)
+	comments := result["comments"].([]interface{})
+	if len(comments) != 2 || comments[0].(map[string]interface{})["id"] != roots[0] || result["next_cursor"] != nil {
+		t.Fatalf("second page = %v", result)
+	}
+}
+
+func TestCommentRules(t *testing.T) {
+	defer func(depth int) { config.CommentMaxDepth = depth }(config.CommentMaxDepth)
+	config.CommentMaxDepth = 1
+
+	// 包含链接的评论进入待审核队列
+	api := newTestAPI(t, func(_ context.Context, c *models.Comment, _ *models.User) (moderation.Result, error) {
+		if strings.Contains(c.Content, "http://") {
+			return moderation.Result{Status: models.CommentStatusPending, Reason: "link"}, nil
+		}
+		return moderation.Approved, nil
+	})
+	author := api.user("author", models.RoleUser)
+	reader := api.user("reader", models.RoleUser)
+	admin := api.user("admin", models.RoleAdmin)
+
+	draft := api.createPost(author, map[string]interface{}{"title": "草稿", "content": "内容"})
+	postID := api.createPost(author, map[string]interface{}{"title": "文章", "content": "内容", "status": "published"})
+	id := mustAtoi(t, postID)
+
+	api.do(http.MethodPost, "/comments", reader, map[string]interface{}{"content": "看不到", "post_id": mustAtoi(t, draft)}, http.StatusNotFound)
+
+	result := api.do(http.MethodPost, "/comments", reader, map[string]interface{}{"content": "第一", "post_id": id}, http.StatusCreated)
+	first := uint(result["comment"].(map[string]interface{})["id"].(float64))
+	result = api.do(http.MethodPost, "/comments", author, map[string]interface{}{"content": "回复", "post_id": id, "parent_id": first}, http.StatusCreated)
+	reply := uint(result["comment"].(map[string]interface{})["id"].(float64))
+	api.do(http.MethodPost, "/comments", reader, map[string]interface{}{"content": "太深", "post_id": id, "parent_id": reply}, http.StatusBadRequest)
+
+	// 只有作者可以修改评论
+	api.do(http.MethodPut, "/comments/"+itoa(first), author, map[string]interface{}{"content": "篡改"}, http.StatusForbidden)
+	result = api.do(http.MethodPut, "/comments/"+itoa(first), reader, map[string]interface{}{"content": "修改"}, http.StatusOK)
+	if comment := result["comment"].(map[string]interface{}); comment["edited_at"] == nil {
+		t.Fatalf("edited comment = %v", comment)
+	}
+
+	// 文章作者删除有回复的评论后保留为墓碑
+	api.do(http.MethodDelete, "/comments/"+itoa(first), author, nil, http.StatusOK)
+	result = api.do(http.MethodGet, "/posts/"+postID+"/comments", 0, nil, http.StatusOK)
+	comments := result["comments"].([]interface{})
+	if len(comments) != 2 {
+		t.Fatalf("comments = %d, want 2", len(comments))
+	}
+	if tombstone := comments[0].(map[string]interface{}); tombstone["deleted"] != true || tombstone["user"] != nil {
+		t.Fatalf("tombstone = %v", tombstone)
+	}
+	api.do(http.MethodPut, "/comments/"+itoa(first), reader, map[string]interface{}{"content": "再改"}, http.StatusNotFound)
+
+	// 待审核评论在通过前不公开
+	result = api.do(http.MethodPost, "/comments", reader, map[string]interface{}{"content": "见 http://spam.example", "post_id": id}, http.StatusCreated)
+	if status := result["comment"].(map[string]interface{})["status"]; status != models.CommentStatusPending {
+		t.Fatalf("status = %v, want pending", status)
+	}
+	pending := uint(result["comment"].(map[string]interface{})["id"].(float64))
+	result = api.do(http.MethodGet, "/admin/comments", admin, nil, http.StatusOK)
+	if total := result["total"]; total != float64(1) {
+		t.Fatalf("queue total = %v, want 1", total)
+	}
+	result = api.do(http.MethodPost, "/admin/comments/approve", admin, map[string]interface{}{"ids": []uint{pending, reply}}, http.StatusOK)
+	if updated := result["updated"]; updated != float64(1) {
+		t.Fatalf("updated = %v, want 1", updated)
+	}
+	result = api.do(http.MethodGet, "/posts/"+postID+"/comments", 0, nil, http.StatusOK)
+	if n := len(result["comments"].([]interface{})); n != 3 {
+		t.Fatalf("comments = %d, want 3", n)
+	}
+}
+
+func TestUserRules(t *testing.T) {
+	api := newTestAPI(t, nil)
+	admin := api.user("admin", models.RoleAdmin)
+
+	result := api.do(http.MethodPost, "/auth/register", 0, map[string]interface{}{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated)
+	alice := uint(result["user"].(map[string]interface{})["id"].(float64))
+	api.do(http.MethodPost, "/auth/register", 0, map[string]interface{}{"username": "bob", "password": "password123", "email": "alice@example.com"}, http.StatusBadRequest)
+
+	api.do(http.MethodPut, "/admin/users/"+itoa(admin)+"/role", admin, map[string]interface{}{"role": "user"}, http.StatusBadRequest)
+	api.do(http.MethodPut, "/admin/users/9999/role", admin, map[string]interface{}{"role": "editor"}, http.StatusNotFound)
+	result = api.do(http.MethodPut, "/admin/users/"+itoa(alice)+"/role", admin, map[string]interface{}{"role": "editor"}, http.StatusOK)
+	if role := result["user"].(map[string]interface{})["role"]; role != models.RoleEditor {
+		t.Fatalf("role = %v, want editor", role)
+	}
+}
+
+func TestTokenRules(t *testing.T) {
+	api := newTestAPI(t, nil)
+	api.user("alice", models.RoleUser)
+
+	login := api.do(http.MethodPost, "/auth/login", 0, map[string]interface{}{"username": "alice", "password": "password123"}, http.StatusOK)
+	first := login["refresh_token"].(string)
+	rotated := api.do(http.MethodPost, "/auth/refresh", 0, map[string]interface{}{"refresh_token": first}, http.StatusOK)
+	second := rotated["refresh_token"].(string)
+
+	// 旧令牌被再次使用时整个令牌家族失效，包括轮换出来的新令牌
+	result := api.do(http.MethodPost, "/auth/refresh", 0, map[string]interface{}{"refresh_token": first}, http.StatusUnauthorized)
+	if code := errorCode(result); code != "token_reused" {
+		t.Fatalf("code = %s, want token_reused", code)
+	}
+	result = api.do(http.MethodPost, "/auth/refresh", 0, map[string]interface{}{"refresh_token": second}, http.StatusUnauthorized)
+	if code := errorCode(result); code != "token_reused" {
+		t.Fatalf("code = %s, want token_reused", code)
+	}
+	result = api.do(http.MethodPost, "/auth/refresh", 0, map[string]interface{}{"refresh_token": "unknown"}, http.StatusUnauthorized)
+	if code := errorCode(result); code != "invalid_token" {
+		t.Fatalf("code = %s, want invalid_token", code)
+	}
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"taskFour/migrations"
+	"taskFour/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readyTimeout 就绪检查中单个依赖的超时时间
+const readyTimeout = 2 * time.Second
+
+// 依赖检查状态
+const (
+	CheckOK      = "ok"
+	CheckFailed  = "failed"
+	CheckPending = "pending"
+)
+
+// Pinger 检查数据库能否连通，通常是 *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthHandler 就绪检查接口
+type HealthHandler struct {
+	migrator *migrations.Migrator
+	db       Pinger
+}
+
+// NewHealthHandler 创建就绪检查接口，migrator 在启动时创建一次，每次检查只读取迁移状态
+func NewHealthHandler(migrator *migrations.Migrator, db Pinger) *HealthHandler {
+	return &HealthHandler{migrator: migrator, db: db}
+}
+
+// DependencyStatus 单个依赖的检查结果
+type DependencyStatus struct {
+	Status  string   `json:"status" example:"ok"`
+	Latency string   `json:"latency,omitempty" example:"1.2ms"`
+	Error   string   `json:"error,omitempty"`
+	Current int      `json:"current,omitempty" example:"5"`
+	Latest  int      `json:"latest,omitempty" example:"5"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// ReadinessResponse 就绪检查响应
+type ReadinessResponse struct {
+	Status string                      `json:"status" example:"ok"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+// Livez 存活检查
+// @Summary 存活检查
+// @Description 进程能处理请求即返回 200，不检查数据库等依赖。/health 为兼容旧版本保留的别名
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]string "服务状态"
+// @Router /livez [get]
+// @Router /health [get]
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"message": "服务运行正常",
+	})
+}
+
+// Readyz 就绪检查
+// @Summary 就绪检查
+// @Description 检查数据库连接、数据库迁移状态以及限流使用的 Redis（配置 ratelimit.store=redis 时），全部正常时返回 200，否则返回 503，checks 中列出每个依赖的状态
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Success 200 {object} ReadinessResponse "服务可以接收流量"
+// @Failure 503 {object} ReadinessResponse "有依赖不可用"
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	checks := map[string]DependencyStatus{
+		"database": h.checkDatabase(ctx),
+	}
+	// 数据库不可用时迁移状态无从查起
+	if checks["database"].Status == CheckOK {
+		checks["migrations"] = h.checkMigrations(ctx)
+	} else {
+		checks["migrations"] = DependencyStatus{Status: CheckFailed, Error: "database unavailable"}
+	}
+	// 限流存储在 Redis 中时，Redis 不可用会让限流失效（请求直接放行），实例不应继续接收流量
+	if check, ok := checkRateLimit(ctx); ok {
+		checks["ratelimit"] = check
+	}
+
+	resp := ReadinessResponse{Status: CheckOK, Checks: checks}
+	status := http.StatusOK
+	for _, check := range checks {
+		if check.Status != CheckOK {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+	c.JSON(status, resp)
+}
+
+// checkDatabase 检查数据库能否连通
+func (h *HealthHandler) checkDatabase(ctx context.Context) DependencyStatus {
+	start := time.Now()
+	if err := h.db.PingContext(ctx); err != nil {
+		return DependencyStatus{Status: CheckFailed, Error: err.Error()}
+	}
+	return DependencyStatus{Status: CheckOK, Latency: time.Since(start).String()}
+}
+
+// checkRateLimit 检查限流存储能否连通，内存存储不需要检查，ok 为 false
+func checkRateLimit(ctx context.Context) (_ DependencyStatus, ok bool) {
+	start := time.Now()
+	checked, err := ratelimit.Ping(ctx)
+	if !checked {
+		return DependencyStatus{}, false
+	}
+	if err != nil {
+		return DependencyStatus{Status: CheckFailed, Error: err.Error()}, true
+	}
+	return DependencyStatus{Status: CheckOK, Latency: time.Since(start).String()}, true
+}
+
+// checkMigrations 检查是否有未执行的数据库迁移，数据库版本比程序新时同样视为不可用。只读，不会创建 schema_migrations 表
+func (h *HealthHandler) checkMigrations(ctx context.Context) DependencyStatus {
+	current, pending, err := h.migrator.Inspect(ctx)
+	result := DependencyStatus{Status: CheckOK, Current: current, Latest: h.migrator.Latest()}
+	if err != nil {
+		result.Status = CheckFailed
+		result.Error = err.Error()
+		return result
+	}
+	for _, m := range pending {
+		result.Pending = append(result.Pending, fmt.Sprintf("%04d_%s", m.Version, m.Name))
+	}
+	if len(pending) > 0 {
+		result.Status = CheckPending
+	}
+	return result
+}
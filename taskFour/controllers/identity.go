@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"net/http"
+	"taskFour/apperr"
+	"taskFour/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityHandler 账号身份管理和钱包登录接口
+type IdentityHandler struct {
+	identities *services.IdentityService
+	tokens     *services.TokenService
+}
+
+// NewIdentityHandler 创建身份接口，tokens 用于钱包登录成功后签发令牌
+func NewIdentityHandler(identities *services.IdentityService, tokens *services.TokenService) *IdentityHandler {
+	return &IdentityHandler{identities: identities, tokens: tokens}
+}
+
+// AddPasswordInput 设置密码输入参数
+type AddPasswordInput struct {
+	Password string `json:"password" binding:"required,min=6" example:"password123"`
+}
+
+// AddEmailInput 添加邮箱身份输入参数
+type AddEmailInput struct {
+	Email string `json:"email" binding:"required,email" example:"another@example.com"`
+}
+
+// ListIdentities 获取当前账号的身份列表
+// @Summary 获取身份列表
+// @Description 获取当前账号关联的密码、邮箱和钱包身份（需要认证）
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "成功获取身份列表"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /users/me/identities [get]
+func (h *IdentityHandler) ListIdentities(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	identities, err := h.identities.List(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// AddWalletIdentity 关联钱包地址
+// @Summary 关联钱包地址
+// @Description 先调用 /auth/nonce 获取随机数，再提交用该钱包签名的 EIP-4361 消息，校验通过后关联到当前账号（需要认证）
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body SIWELoginInput true "签名消息"
+// @Success 201 {object} map[string]interface{} "关联成功"
+// @Failure 400 {object} apperr.Response "消息格式错误"
+// @Failure 401 {object} apperr.Response "签名、域名、随机数校验失败"
+// @Failure 409 {object} apperr.Response "钱包已被关联"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /users/me/identities/wallet [post]
+func (h *IdentityHandler) AddWalletIdentity(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	var input SIWELoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	identity, err := h.identities.LinkWallet(c.Request.Context(), userID, input.Message, input.Signature)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Identity linked successfully",
+		"identity": identity,
+	})
+}
+
+// AddPasswordIdentity 设置密码
+// @Summary 设置密码
+// @Description 为没有密码的账号（如钱包登录自动创建的账号）设置密码，之后可以用用户名和密码登录（需要认证）
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body AddPasswordInput true "密码"
+// @Success 201 {object} map[string]interface{} "设置成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 409 {object} apperr.Response "账号已设置密码"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /users/me/identities/password [post]
+func (h *IdentityHandler) AddPasswordIdentity(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	var input AddPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	identity, err := h.identities.LinkPassword(c.Request.Context(), userID, input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Identity linked successfully",
+		"identity": identity,
+	})
+}
+
+// AddEmailIdentity 添加邮箱身份
+// @Summary 添加邮箱身份
+// @Description 为当前账号添加邮箱（需要认证）。邮箱未经验证，不会设为主邮箱，也不占用地址，其他账号注册或添加同一邮箱时会替换它
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body AddEmailInput true "邮箱"
+// @Success 201 {object} map[string]interface{} "添加成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 409 {object} apperr.Response "邮箱已被使用"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /users/me/identities/email [post]
+func (h *IdentityHandler) AddEmailIdentity(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+
+	var input AddEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	identity, err := h.identities.LinkEmail(c.Request.Context(), userID, input.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Identity linked successfully",
+		"identity": identity,
+	})
+}
+
+// DeleteIdentity 删除身份
+// @Summary 删除身份
+// @Description 解除当前账号的某个身份，不能删除最后一个可登录的身份（密码或钱包）（需要认证）
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "身份ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} apperr.Response "不能删除最后一个登录方式"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 404 {object} apperr.Response "身份未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /users/me/identities/{id} [delete]
+func (h *IdentityHandler) DeleteIdentity(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	id, ok := idParam(c, "Invalid identity ID")
+	if !ok {
+		return
+	}
+
+	if err := h.identities.Unlink(c.Request.Context(), userID, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identity removed successfully"})
+}
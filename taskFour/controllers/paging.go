@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"taskFour/apperr"
+	"taskFour/config"
+	"taskFour/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postSorts 文章列表支持的排序字段
+var postSorts = []string{
+	repositories.PostSortPublishedAt,
+	repositories.PostSortCreatedAt,
+	repositories.PostSortUpdatedAt,
+	repositories.PostSortTitle,
+	repositories.PostSortCommentCount,
+}
+
+// pageDeprecation 页码分页改为游标分页的时间（RFC 9745 Deprecation 响应头的格式）
+const pageDeprecation = "@1792281600" // 2026-10-18T00:00:00Z
+
+// pagination 解析 page 和 limit 查询参数，limit 默认为 defaultLimit，无效时返回 400
+func pagination(c *gin.Context, defaultLimit int) (page, limit int, ok bool) {
+	page, ok = pageParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+	limit, ok = limitParam(c, defaultLimit)
+	return page, limit, ok
+}
+
+// pageParam 解析 page 查询参数，默认为 1，必须是正整数
+func pageParam(c *gin.Context) (int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.Error(apperr.Field("page", "type", "int"))
+		return 0, false
+	}
+	if page < 1 {
+		c.Error(apperr.Field("page", "min", "1"))
+		return 0, false
+	}
+	return page, true
+}
+
+// limitParam 解析 limit 查询参数，必须是 1 到 config.PagingMaxLimit 之间的整数
+func limitParam(c *gin.Context, defaultLimit int) (int, bool) {
+	value, ok := c.GetQuery("limit")
+	if !ok {
+		return defaultLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	switch {
+	case err != nil:
+		c.Error(apperr.Field("limit", "type", "int"))
+	case limit < 1:
+		c.Error(apperr.Field("limit", "min", "1"))
+	case limit > config.PagingMaxLimit:
+		c.Error(apperr.Field("limit", "max", strconv.Itoa(config.PagingMaxLimit)))
+	default:
+		return limit, true
+	}
+	return 0, false
+}
+
+// pageRequest 解析键集分页的查询参数 cursor、limit、sort、order 和 total，无效时返回 400。
+// 带游标时可以省略 sort 和 order，使用游标中的排序方式；未指定 order 时标题升序，其余字段降序。
+// 已废弃的 page 参数仍然可用（按页码跳过记录），不能与 cursor 同时使用，响应带 Deprecation 头
+func pageRequest(c *gin.Context, sorts []string, defaultSort string, defaultLimit int) (repositories.PageRequest, bool) {
+	var req repositories.PageRequest
+	limit, ok := limitParam(c, defaultLimit)
+	if !ok {
+		return req, false
+	}
+	req.Limit = limit
+
+	if _, ok := c.GetQuery("page"); ok {
+		if c.Query("cursor") != "" {
+			c.Error(apperr.Field("page", "excluded_with", "cursor"))
+			return req, false
+		}
+		page, ok := pageParam(c)
+		if !ok {
+			return req, false
+		}
+		req.Offset = (page - 1) * limit
+		c.Header("Deprecation", pageDeprecation)
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := repositories.DecodeCursor(value)
+		if err != nil || !slices.Contains(sorts, cursor.Sort) {
+			c.Error(apperr.BadRequest("Invalid cursor"))
+			return req, false
+		}
+		req.Cursor = cursor
+		req.Sort, req.Order = cursor.Sort, cursor.Order
+	}
+
+	if sort := c.Query("sort"); sort != "" || req.Sort == "" {
+		if sort == "" {
+			sort = defaultSort
+		}
+		if !slices.Contains(sorts, sort) {
+			c.Error(apperr.Field("sort", "oneof", strings.Join(sorts, " ")))
+			return req, false
+		}
+		if req.Cursor != nil && sort != req.Sort {
+			c.Error(apperr.BadRequest("cursor does not match sort"))
+			return req, false
+		}
+		req.Sort = sort
+	}
+
+	if order := c.Query("order"); order != "" || req.Order == "" {
+		if order == "" {
+			order = repositories.OrderDesc
+			if req.Sort == repositories.PostSortTitle {
+				order = repositories.OrderAsc
+			}
+		}
+		if order != repositories.OrderAsc && order != repositories.OrderDesc {
+			c.Error(apperr.Field("order", "oneof", repositories.OrderAsc+" "+repositories.OrderDesc))
+			return req, false
+		}
+		if req.Cursor != nil && order != req.Order {
+			c.Error(apperr.BadRequest("cursor does not match order"))
+			return req, false
+		}
+		req.Order = order
+	}
+
+	if value, ok := c.GetQuery("total"); ok {
+		total, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(apperr.Field("total", "type", "bool"))
+			return req, false
+		}
+		req.Total = total
+	}
+	return req, true
+}
+
+// PageInfo 列表响应中的分页信息，没有下一页或上一页时省略对应的游标，total 只在请求 total=true 时返回
+type PageInfo struct {
+	Limit      int    `json:"limit" example:"10"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoicHVibGlzaGVkX2F0IiwibyI6ImRlc2MiLCJ2IjoiMjAyNS0wMS0wMVQwODowMDowMFoiLCJpIjo0Mn0"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty" example:"42"`
+}
+
+// pageInfo 编码下一页和上一页的游标，并设置 Link 响应头（RFC 8288，rel 为 next 和 prev），
+// 链接保留本次请求的其他查询参数，已废弃的 page 参数换成游标
+func pageInfo[T any](c *gin.Context, page repositories.Page[T], limit int) PageInfo {
+	info := PageInfo{Limit: limit, NextCursor: page.Next.Encode(), PrevCursor: page.Prev.Encode(), Total: page.Total}
+
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{{"next", info.NextCursor}, {"prev", info.PrevCursor}} {
+		if link.cursor == "" {
+			continue
+		}
+		query := c.Request.URL.Query()
+		query.Del("page")
+		query.Set("cursor", link.cursor)
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, c.Request.URL.Path, query.Encode(), link.rel))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+	return info
+}
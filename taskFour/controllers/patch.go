@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"taskFour/apperr"
+	"taskFour/services"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// 补丁格式
+const (
+	mergePatchType = "application/merge-patch+json" // RFC 7396
+	jsonPatchType  = "application/json-patch+json"  // RFC 6902
+)
+
+// PostDocument PATCH 修改的文章文档，打补丁后按与 CreatePostInput 相同的规则校验（status 另外允许 archived）。
+// category_id 为 null 时清空分类，tags 为 null 或空数组时清空标签
+type PostDocument struct {
+	Title       string     `json:"title" binding:"required,min=1,max=200" example:"我的第一篇文章"`
+	Content     string     `json:"content" binding:"required,min=1" example:"这是文章的内容..."`
+	Status      string     `json:"status" binding:"required,oneof=draft published scheduled archived" example:"published"`
+	PublishedAt *time.Time `json:"published_at" example:"2025-01-01T08:00:00Z"`
+	Tags        []string   `json:"tags" binding:"omitempty,max=10,dive,min=1,max=50" example:"区块链,以太坊"`
+	CategoryID  *uint      `json:"category_id" example:"1"`
+}
+
+// postPatch 按 Content-Type 解析补丁，返回对文章字段打补丁的函数；格式不支持时返回 415，补丁无效时返回 400
+func postPatch(contentType string, body []byte) (func(services.PostFields) (services.PostFields, error), error) {
+	var apply func(doc []byte) ([]byte, error)
+	switch contentType {
+	case mergePatchType:
+		if !json.Valid(body) {
+			return nil, apperr.BadRequest("Invalid patch document")
+		}
+		apply = func(doc []byte) ([]byte, error) { return jsonpatch.MergePatch(doc, body) }
+	case jsonPatchType:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidRequest, "Invalid patch document", err)
+		}
+		apply = patch.Apply
+	default:
+		return nil, apperr.UnsupportedMediaType("Unsupported patch format")
+	}
+
+	return func(fields services.PostFields) (services.PostFields, error) {
+		doc, err := json.Marshal(PostDocument{
+			Title:       fields.Title,
+			Content:     fields.Content,
+			Status:      fields.Status,
+			PublishedAt: fields.PublishedAt,
+			Tags:        fields.Tags,
+			CategoryID:  fields.CategoryID,
+		})
+		if err != nil {
+			return fields, apperr.Internal("Failed to update post", err)
+		}
+		patched, err := apply(doc)
+		if err != nil {
+			// 测试操作失败、路径不存在等，补丁与文章的当前状态不符
+			return fields, apperr.Wrap(apperr.CodeConflict, "Patch could not be applied", err)
+		}
+
+		var result PostDocument
+		decoder := json.NewDecoder(bytes.NewReader(patched))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&result); err != nil {
+			return fields, apperr.Bind(err)
+		}
+		if err := binding.Validator.ValidateStruct(&result); err != nil {
+			return fields, apperr.Bind(err)
+		}
+		return services.PostFields{
+			Title:       result.Title,
+			Content:     result.Content,
+			Status:      result.Status,
+			PublishedAt: result.PublishedAt,
+			Tags:        result.Tags,
+			CategoryID:  result.CategoryID,
+		}, nil
+	}, nil
+}
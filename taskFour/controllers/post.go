@@ -1,71 +1,91 @@
 package controllers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
-	"taskFour/config"
+	"taskFour/apperr"
+	"taskFour/middleware"
 	"taskFour/models"
+	"taskFour/repositories"
+	"taskFour/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // CreatePostInput 创建文章输入参数
 type CreatePostInput struct {
-	Title   string `json:"title" binding:"required,min=1,max=200" example:"我的第一篇文章"`
-	Content string `json:"content" binding:"required,min=1" example:"这是文章的内容..."`
+	Title       string     `json:"title" binding:"required,min=1,max=200" example:"我的第一篇文章"`
+	Content     string     `json:"content" binding:"required,min=1" example:"这是文章的内容..."`
+	Status      string     `json:"status" binding:"omitempty,oneof=draft published scheduled" example:"draft"`
+	PublishedAt *time.Time `json:"published_at" example:"2025-01-01T08:00:00Z"`
+	Tags        []string   `json:"tags" binding:"omitempty,max=10,dive,min=1,max=50" example:"区块链,以太坊"`
+	CategoryID  *uint      `json:"category_id" example:"1"`
 }
 
-// UpdatePostInput 更新文章输入参数
+// UpdatePostInput 更新文章输入参数，Version 为修改基于的版本号，没有 If-Match 请求头时必须提供
 type UpdatePostInput struct {
-	Title   string `json:"title" binding:"omitempty,min=1,max=200" example:"更新后的文章标题"`
-	Content string `json:"content" binding:"omitempty,min=1" example:"更新后的文章内容..."`
+	Version     *uint      `json:"version" example:"3"`
+	Title       string     `json:"title" binding:"omitempty,min=1,max=200" example:"更新后的文章标题"`
+	Content     string     `json:"content" binding:"omitempty,min=1" example:"更新后的文章内容..."`
+	Status      string     `json:"status" binding:"omitempty,oneof=draft published scheduled archived" example:"published"`
+	PublishedAt *time.Time `json:"published_at" example:"2025-01-01T08:00:00Z"`
+	Tags        *[]string  `json:"tags" binding:"omitempty,max=10,dive,min=1,max=50" example:"区块链,以太坊"`
+	CategoryID  *uint      `json:"category_id" example:"1"`
+}
+
+// PostHandler 文章接口
+type PostHandler struct {
+	posts *services.PostService
+}
+
+// NewPostHandler 创建文章接口
+func NewPostHandler(posts *services.PostService) *PostHandler {
+	return &PostHandler{posts: posts}
 }
 
 // PostsResponse 文章列表响应
 type PostsResponse struct {
 	Posts []models.Post `json:"posts"`
-	Page  int           `json:"page" example:"1"`
-	Limit int           `json:"limit" example:"10"`
+	PageInfo
 }
 
 // CreatePost 创建文章
 // @Summary 创建文章
-// @Description 创建新的博客文章（需要认证）
+// @Description 创建新的博客文章，默认保存为草稿，status 为 scheduled 时需要指定未来的 published_at（需要认证）
 // @Tags 文章
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body CreatePostInput true "文章内容"
 // @Success 201 {object} map[string]interface{} "创建成功"
-// @Failure 400 {object} map[string]interface{} "请求参数错误"
-// @Failure 401 {object} map[string]interface{} "未认证"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /posts [post]
-func CreatePost(c *gin.Context) {
-	// 原有实现保持不变...
+func (h *PostHandler) CreatePost(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 
 	var input CreatePostInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperr.Bind(err))
 		return
 	}
 
-	post := models.Post{
-		Title:   input.Title,
-		Content: input.Content,
-		UserID:  userID,
-	}
-
-	if err := config.GetDB().Create(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
+	post, err := h.posts.Create(c.Request.Context(), userID, services.CreatePostParams{
+		Title:       input.Title,
+		Content:     input.Content,
+		Status:      input.Status,
+		PublishedAt: input.PublishedAt,
+		Tags:        input.Tags,
+		CategoryID:  input.CategoryID,
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 重新加载以获取用户信息
-	config.GetDB().Preload("User").First(&post, post.ID)
-
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Post created successfully",
 		"post":    post,
@@ -74,129 +94,192 @@ func CreatePost(c *gin.Context) {
 
 // GetPosts 获取文章列表
 // @Summary 获取文章列表
-// @Description 获取分页的文章列表
+// @Description 获取已发布的文章列表，使用游标分页：响应中的 next_cursor / prev_cursor 作为 cursor 参数获取下一页 / 上一页，
+// @Description 同样的链接也在 Link 响应头中（rel="next" / rel="prev"）。带 cursor 时 sort 和 order 可以省略，指定时必须与游标一致
 // @Tags 文章
 // @Accept json
 // @Produce json
-// @Param page query int false "页码" default(1)
-// @Param limit query int false "每页数量" default(10)
+// @Param cursor query string false "分页游标"
+// @Param page query int false "已废弃，页码，不能与 cursor 同时使用"
+// @Param limit query int false "每页数量，最大为 PAGING_MAX_LIMIT" default(10)
+// @Param sort query string false "排序字段，published_at 对未发布的文章使用创建时间" Enums(published_at, created_at, updated_at, title, comment_count) default(published_at)
+// @Param order query string false "排序方向，默认 title 升序，其余降序" Enums(asc, desc)
+// @Param total query bool false "是否返回符合条件的文章总数"
+// @Param tag query string false "标签 slug"
+// @Param category query string false "分类 slug，包含子孙分类"
 // @Success 200 {object} PostsResponse "成功获取文章列表"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Header 200 {string} Link "下一页和上一页的链接"
+// @Failure 400 {object} apperr.Response "分页参数无效"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /posts [get]
-func GetPosts(c *gin.Context) {
-	// 原有实现保持不变...
-	var posts []models.Post
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
+func (h *PostHandler) GetPosts(c *gin.Context) {
+	req, ok := pageRequest(c, postSorts, repositories.PostSortPublishedAt, 10)
+	if !ok {
+		return
+	}
 
-	if err := config.GetDB().Preload("User").Offset(offset).Limit(limit).Order("created_at desc").Find(&posts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+	page, err := h.posts.Published(c.Request.Context(), repositories.PostFilter{
+		Tag:      c.Query("tag"),
+		Category: c.Query("category"),
+	}, req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
-		"page":  page,
-		"limit": limit,
-	})
+	c.JSON(http.StatusOK, PostsResponse{Posts: page.Items, PageInfo: pageInfo(c, page, req.Limit)})
 }
 
 // GetPost 获取单篇文章
 // @Summary 获取单篇文章
-// @Description 根据ID获取单篇文章的详细信息
+// @Description 根据ID获取单篇文章的详细信息，未发布的文章只有作者可以查看。
+// @Description 响应头 ETag 为 "<文章ID>-<版本号>-<评论指纹>"，文章或评论变化时改变，请求头 If-None-Match 与之匹配时返回 304
 // @Tags 文章
 // @Accept json
 // @Produce json
 // @Param id path int true "文章ID"
+// @Param If-None-Match header string false "上次响应的 ETag"
 // @Success 200 {object} map[string]interface{} "成功获取文章"
-// @Failure 400 {object} map[string]interface{} "无效的文章ID"
-// @Failure 404 {object} map[string]interface{} "文章未找到"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Header 200 {string} ETag "文章的实体标签"
+// @Success 304 "文章没有变化"
+// @Failure 400 {object} apperr.Response "无效的文章ID"
+// @Failure 404 {object} apperr.Response "文章未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /posts/{id} [get]
-func GetPost(c *gin.Context) {
-	// 原有实现保持不变...
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+func (h *PostHandler) GetPost(c *gin.Context) {
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
 		return
 	}
 
-	var post models.Post
-	if err := config.GetDB().Preload("User").Preload("Comments.User").First(&post, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post"})
+	viewerID, _ := middleware.CurrentUserID(c)
+	post, err := h.posts.Get(c.Request.Context(), viewerID, id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	etag := postReadETag(post)
+	c.Header("ETag", etag)
+	if notModified(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"post": post})
 }
 
 // UpdatePost 更新文章
 // @Summary 更新文章
-// @Description 更新指定文章的内容（仅文章作者可操作）
+// @Description 更新指定文章的内容（文章作者或拥有 post:update:any 权限的用户可操作）。
+// @Description 必须通过 If-Match 请求头（获取文章时的 ETag，或 * 表示不检查）或请求体中的 version 指定修改基于的版本，
+// @Description 文章已被他人修改时返回 412，error.current 为文章的当前状态
 // @Tags 文章
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "文章ID"
+// @Param If-Match header string false "获取文章时的 ETag"
 // @Param input body UpdatePostInput true "更新内容"
 // @Success 200 {object} map[string]interface{} "更新成功"
-// @Failure 400 {object} map[string]interface{} "请求参数错误"
-// @Failure 403 {object} map[string]interface{} "权限不足"
-// @Failure 404 {object} map[string]interface{} "文章未找到"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Header 200,412 {string} ETag "文章当前版本的实体标签"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "文章未找到"
+// @Failure 412 {object} apperr.Response "文章已被修改"
+// @Failure 428 {object} apperr.Response "没有指定修改基于的版本"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /posts/{id} [put]
-func UpdatePost(c *gin.Context) {
-	// 原有实现保持不变...
+func (h *PostHandler) UpdatePost(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
 		return
 	}
 
-	var post models.Post
-	if err := config.GetDB().First(&post, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post"})
+	var input UpdatePostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
 		return
 	}
+	version, anyVersion := ifMatchVersion(c, id, input.Version)
 
-	// 检查权限
-	if post.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own posts"})
+	post, err := h.posts.Update(c.Request.Context(), userID, id, services.UpdatePostParams{
+		Version:     version,
+		AnyVersion:  anyVersion,
+		Title:       input.Title,
+		Content:     input.Content,
+		Status:      input.Status,
+		PublishedAt: input.PublishedAt,
+		Tags:        input.Tags,
+		CategoryID:  input.CategoryID,
+	})
+	if err != nil {
+		setConflictETag(c, err)
+		c.Error(err)
 		return
 	}
 
-	var input UpdatePostInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	c.Header("ETag", postETag(post))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post updated successfully",
+		"post":    post,
+	})
+}
+
+// PatchPost 部分修改文章
+// @Summary 部分修改文章
+// @Description 以 JSON Merge Patch（RFC 7396，Content-Type: application/merge-patch+json）或 JSON Patch（RFC 6902，Content-Type: application/json-patch+json）
+// @Description 修改文章，补丁作用于 PostDocument，可以把 category_id 置为 null、清空 tags。打补丁后的文章按与创建文章相同的规则校验。
+// @Description 权限、If-Match 和版本冲突的处理与更新文章相同，但只能通过 If-Match 指定版本
+// @Tags 文章
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param If-Match header string false "获取文章时的 ETag"
+// @Param patch body PostDocument true "Merge Patch 为 PostDocument 的部分字段，JSON Patch 为操作数组"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Header 200,412 {string} ETag "文章当前版本的实体标签"
+// @Failure 400 {object} apperr.Response "补丁无效或修改后的文章校验失败"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "文章未找到"
+// @Failure 409 {object} apperr.Response "JSON Patch 无法应用，如 test 操作失败"
+// @Failure 412 {object} apperr.Response "文章已被修改"
+// @Failure 415 {object} apperr.Response "不支持的补丁格式"
+// @Failure 428 {object} apperr.Response "没有指定修改基于的版本"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /posts/{id} [patch]
+func (h *PostHandler) PatchPost(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
 		return
 	}
 
-	updates := make(map[string]interface{})
-	if input.Title != "" {
-		updates["title"] = input.Title
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperr.Bind(err))
+		return
 	}
-	if input.Content != "" {
-		updates["content"] = input.Content
+	patch, err := postPatch(c.ContentType(), body)
+	if err != nil {
+		c.Error(err)
+		return
 	}
+	version, anyVersion := ifMatchVersion(c, id, nil)
 
-	if err := config.GetDB().Model(&post).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post"})
+	post, err := h.posts.Patch(c.Request.Context(), userID, id, services.PatchPostParams{
+		Version:    version,
+		AnyVersion: anyVersion,
+		Patch:      patch,
+	})
+	if err != nil {
+		setConflictETag(c, err)
+		c.Error(err)
 		return
 	}
 
-	config.GetDB().Preload("User").First(&post, post.ID)
-
+	c.Header("ETag", postETag(post))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Post updated successfully",
 		"post":    post,
@@ -205,47 +288,77 @@ func UpdatePost(c *gin.Context) {
 
 // DeletePost 删除文章
 // @Summary 删除文章
-// @Description 删除指定文章（仅文章作者可操作）
+// @Description 删除指定文章（文章作者或拥有 post:delete:any 权限的用户可操作）
 // @Tags 文章
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "文章ID"
 // @Success 200 {object} map[string]interface{} "删除成功"
-// @Failure 400 {object} map[string]interface{} "无效的文章ID"
-// @Failure 403 {object} map[string]interface{} "权限不足"
-// @Failure 404 {object} map[string]interface{} "文章未找到"
-// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Failure 400 {object} apperr.Response "无效的文章ID"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "文章未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
 // @Router /posts/{id} [delete]
-func DeletePost(c *gin.Context) {
-	// 原有实现保持不变...
+func (h *PostHandler) DeletePost(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
 		return
 	}
 
-	var post models.Post
-	if err := config.GetDB().First(&post, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post"})
+	if err := h.posts.Delete(c.Request.Context(), userID, id); err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 检查权限
-	if post.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own posts"})
+	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
+}
+
+// GetMyPosts 获取我的文章
+// @Summary 获取我的文章
+// @Description 获取当前用户的全部文章，包括草稿、定时和归档文章，分页参数与文章列表相同，默认按修改时间倒序（需要认证）
+// @Tags 文章
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "按状态过滤" Enums(draft, published, scheduled, archived)
+// @Param cursor query string false "分页游标"
+// @Param page query int false "已废弃，页码，不能与 cursor 同时使用"
+// @Param limit query int false "每页数量，最大为 PAGING_MAX_LIMIT" default(10)
+// @Param sort query string false "排序字段" Enums(published_at, created_at, updated_at, title, comment_count) default(updated_at)
+// @Param order query string false "排序方向，默认 title 升序，其余降序" Enums(asc, desc)
+// @Param total query bool false "是否返回符合条件的文章总数"
+// @Success 200 {object} PostsResponse "成功获取文章列表"
+// @Header 200 {string} Link "下一页和上一页的链接"
+// @Failure 400 {object} apperr.Response "分页参数无效"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /users/me/posts [get]
+func (h *PostHandler) GetMyPosts(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	req, ok := pageRequest(c, postSorts, repositories.PostSortUpdatedAt, 10)
+	if !ok {
 		return
 	}
 
-	if err := config.GetDB().Delete(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete post"})
+	page, err := h.posts.ByAuthor(c.Request.Context(), userID, repositories.PostFilter{
+		Status: c.Query("status"),
+	}, req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
+	c.JSON(http.StatusOK, PostsResponse{Posts: page.Items, PageInfo: pageInfo(c, page, req.Limit)})
+}
+
+// idParam 解析路径参数 id，无效时以 message 返回 400
+func idParam(c *gin.Context, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		c.Error(apperr.BadRequest(message))
+		return 0, false
+	}
+	return uint(id), true
 }
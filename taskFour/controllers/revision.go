@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"taskFour/apperr"
+	"taskFour/middleware"
+	"taskFour/services"
+	"taskFour/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevisionDiffResponse 版本差异响应
+type RevisionDiffResponse struct {
+	From  int `json:"from" example:"1"`
+	To    int `json:"to" example:"2"`
+	Title struct {
+		From string `json:"from" example:"旧标题"`
+		To   string `json:"to" example:"新标题"`
+	} `json:"title"`
+	Diff string `json:"diff" example:"--- rev 1\n+++ rev 2\n@@ -1 +1 @@\n-旧内容\n+新内容\n"`
+}
+
+// ListRevisions 获取文章历史版本
+// @Summary 获取文章历史版本
+// @Description 获取文章的全部历史版本，按版本号倒序，未发布的文章只有作者可以查看
+// @Tags 文章
+// @Accept json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Success 200 {object} map[string]interface{} "成功获取版本列表"
+// @Failure 400 {object} apperr.Response "无效的文章ID"
+// @Failure 404 {object} apperr.Response "文章未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /posts/{id}/revisions [get]
+func (h *PostHandler) ListRevisions(c *gin.Context) {
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	viewerID, _ := middleware.CurrentUserID(c)
+	revisions, err := h.posts.Revisions(c.Request.Context(), viewerID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// DiffRevisions 比较两个版本
+// @Summary 比较两个版本
+// @Description 返回两个版本之间内容的按行 unified diff
+// @Tags 文章
+// @Accept json
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param from query int true "起始版本号"
+// @Param to query int true "目标版本号"
+// @Success 200 {object} RevisionDiffResponse "成功获取差异"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 404 {object} apperr.Response "文章或版本未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /posts/{id}/revisions/diff [get]
+func (h *PostHandler) DiffRevisions(c *gin.Context) {
+	from, err1 := strconv.Atoi(c.Query("from"))
+	to, err2 := strconv.Atoi(c.Query("to"))
+	if err1 != nil || err2 != nil {
+		c.Error(apperr.BadRequest("Invalid revision numbers"))
+		return
+	}
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	viewerID, _ := middleware.CurrentUserID(c)
+	fromRev, toRev, err := h.posts.RevisionPair(c.Request.Context(), viewerID, id, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var response RevisionDiffResponse
+	response.From = from
+	response.To = to
+	response.Title.From = fromRev.Title
+	response.Title.To = toRev.Title
+	response.Diff = utils.UnifiedDiff(fromRev.Content, toRev.Content,
+		fmt.Sprintf("rev %d", from), fmt.Sprintf("rev %d", to), 3)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreRevision 恢复到指定版本
+// @Summary 恢复到指定版本
+// @Description 用指定版本的标题和内容覆盖文章，并保存为一个新版本（仅文章作者可操作）。
+// @Description 必须通过 If-Match 请求头（获取文章时的 ETag，或 * 表示不检查）指定恢复基于的版本，文章已被他人修改时返回 412
+// @Tags 文章
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "文章ID"
+// @Param rev path int true "版本号"
+// @Param If-Match header string false "获取文章时的 ETag"
+// @Success 200 {object} map[string]interface{} "恢复成功"
+// @Header 200,412 {string} ETag "文章当前版本的实体标签"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "文章或版本未找到"
+// @Failure 412 {object} apperr.Response "文章已被修改"
+// @Failure 428 {object} apperr.Response "没有指定恢复基于的版本"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /posts/{id}/revisions/{rev}/restore [post]
+func (h *PostHandler) RestoreRevision(c *gin.Context) {
+	userID := c.MustGet("user_id").(uint)
+	id, ok := idParam(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid revision number"))
+		return
+	}
+	version, anyVersion := ifMatchVersion(c, id, nil)
+
+	post, revision, err := h.posts.RestoreRevision(c.Request.Context(), userID, id, services.RestoreRevisionParams{
+		Rev:        rev,
+		Version:    version,
+		AnyVersion: anyVersion,
+	})
+	if err != nil {
+		setConflictETag(c, err)
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", postETag(post))
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Revision restored successfully",
+		"post":     post,
+		"revision": revision,
+	})
+}
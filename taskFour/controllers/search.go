@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"taskFour/apperr"
+	"taskFour/models"
+	"taskFour/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler 全文搜索接口
+type SearchHandler struct {
+	search *services.SearchService
+}
+
+// NewSearchHandler 创建搜索接口
+func NewSearchHandler(search *services.SearchService) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+// SearchResponse 搜索响应
+type SearchResponse struct {
+	Query    string                       `json:"query" example:"区块链"`
+	Posts    []models.PostSearchResult    `json:"posts"`
+	Comments []models.CommentSearchResult `json:"comments"`
+	Page     int                          `json:"page" example:"1"`
+	Limit    int                          `json:"limit" example:"10"`
+}
+
+// Search 全文搜索
+// @Summary 全文搜索
+// @Description 搜索已发布的文章和评论，按相关度排序，返回带 <mark> 高亮的摘要。支持 "短语" 和 前缀* 查询，中文按单字索引
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Param q query string true "搜索关键词"
+// @Param type query string false "搜索范围" Enums(all, posts, comments) default(all)
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量，最大为 PAGING_MAX_LIMIT" default(10)
+// @Success 200 {object} SearchResponse "搜索结果"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		c.Error(apperr.BadRequest("Query parameter q is required"))
+		return
+	}
+
+	searchType := c.DefaultQuery("type", services.SearchAll)
+	if searchType != services.SearchAll && searchType != services.SearchPosts && searchType != services.SearchComments {
+		c.Error(apperr.BadRequest("Invalid search type"))
+		return
+	}
+
+	page, limit, ok := pagination(c, 10)
+	if !ok {
+		return
+	}
+	result, err := h.search.Search(c.Request.Context(), q, searchType, (page-1)*limit, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SearchResponse{
+		Query:    q,
+		Posts:    result.Posts,
+		Comments: result.Comments,
+		Page:     page,
+		Limit:    limit,
+	})
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+	"taskFour/apperr"
+	"taskFour/config"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SIWELoginInput 钱包登录输入参数
+type SIWELoginInput struct {
+	Message   string `json:"message" binding:"required" example:"localhost:8080 wants you to sign in with your Ethereum account:\n0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf\n\nSign in to the blog.\n\nURI: http://localhost:8080\nVersion: 1\nChain ID: 1\nNonce: 9f1c2a7b4e6d8f01a2b3c4d5\nIssued At: 2025-01-01T00:00:00Z"`
+	Signature string `json:"signature" binding:"required" example:"0x..."`
+}
+
+// NonceResponse 登录随机数响应
+type NonceResponse struct {
+	Nonce     string    `json:"nonce" example:"9f1c2a7b4e6d8f01a2b3c4d5"`
+	Domain    string    `json:"domain" example:"localhost:8080"`
+	ChainID   int       `json:"chain_id,omitempty" example:"1"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GetNonce 获取钱包登录随机数
+// @Summary 获取钱包登录随机数
+// @Description 生成一次性随机数，需要写入 EIP-4361 消息的 Nonce 字段
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Success 200 {object} NonceResponse "成功生成随机数"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /auth/nonce [get]
+func (h *IdentityHandler) GetNonce(c *gin.Context) {
+	nonce, err := h.identities.NewNonce(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NonceResponse{
+		Nonce:     nonce.Nonce,
+		Domain:    config.SIWEDomain,
+		ChainID:   config.SIWEChainID,
+		ExpiresAt: nonce.ExpiresAt,
+	})
+}
+
+// SIWELogin 钱包登录
+// @Summary 钱包登录（Sign-In with Ethereum）
+// @Description 提交 EIP-4361 消息和 personal_sign 签名，校验通过后返回与用户名密码登录相同的令牌，首次登录自动创建账号
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param input body SIWELoginInput true "签名消息"
+// @Success 200 {object} LoginResponse "登录成功"
+// @Failure 400 {object} apperr.Response "消息格式错误"
+// @Failure 401 {object} apperr.Response "签名、域名、随机数校验失败"
+// @Failure 409 {object} apperr.Response "地址形式的用户名已被占用"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /auth/siwe [post]
+func (h *IdentityHandler) SIWELogin(c *gin.Context) {
+	var input SIWELoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	user, err := h.identities.SignInWithEthereum(c.Request.Context(), input.Message, input.Signature)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pair, err := h.tokens.Issue(c.Request.Context(), user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(user, pair))
+}
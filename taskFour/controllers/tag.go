@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"net/http"
+	"taskFour/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagInput 创建/修改标签输入参数
+type TagInput struct {
+	Name string `json:"name" binding:"required,max=50" example:"以太坊"`
+	Slug string `json:"slug" binding:"omitempty,max=60" example:"ethereum"`
+}
+
+// ListTags 获取标签列表
+// @Summary 获取标签列表
+// @Description 获取全部标签及每个标签下公开文章的数量，按文章数量倒序
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "成功获取标签列表"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /tags [get]
+func (h *TaxonomyHandler) ListTags(c *gin.Context) {
+	tags, err := h.taxonomy.Tags(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// CreateTag 创建标签
+// @Summary 创建标签
+// @Description 创建标签，未指定 slug 时由名称生成（需要 taxonomy:manage 权限）
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body TagInput true "标签信息"
+// @Success 201 {object} map[string]interface{} "创建成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 409 {object} apperr.Response "标签已存在"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /tags [post]
+func (h *TaxonomyHandler) CreateTag(c *gin.Context) {
+	var input TagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	tag, err := h.taxonomy.CreateTag(c.Request.Context(), input.Name, input.Slug)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tag created successfully",
+		"tag":     tag,
+	})
+}
+
+// UpdateTag 修改标签
+// @Summary 修改标签
+// @Description 修改标签名称和 slug（需要 taxonomy:manage 权限）
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "标签ID"
+// @Param input body TagInput true "标签信息"
+// @Success 200 {object} map[string]interface{} "修改成功"
+// @Failure 400 {object} apperr.Response "请求参数错误"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "标签未找到"
+// @Failure 409 {object} apperr.Response "标签已存在"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /tags/{id} [put]
+func (h *TaxonomyHandler) UpdateTag(c *gin.Context) {
+	id, ok := idParam(c, "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var input TagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.Bind(err))
+		return
+	}
+
+	tag, err := h.taxonomy.UpdateTag(c.Request.Context(), id, input.Name, input.Slug)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag updated successfully",
+		"tag":     tag,
+	})
+}
+
+// DeleteTag 删除标签
+// @Summary 删除标签
+// @Description 删除标签并解除它与文章的关联，文章本身不受影响（需要 taxonomy:manage 权限）
+// @Tags 分类
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "标签ID"
+// @Success 200 {object} map[string]interface{} "删除成功"
+// @Failure 400 {object} apperr.Response "无效的标签ID"
+// @Failure 401 {object} apperr.Response "未认证"
+// @Failure 403 {object} apperr.Response "权限不足"
+// @Failure 404 {object} apperr.Response "标签未找到"
+// @Failure 500 {object} apperr.Response "服务器内部错误"
+// @Router /tags/{id} [delete]
+func (h *TaxonomyHandler) DeleteTag(c *gin.Context) {
+	id, ok := idParam(c, "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	if err := h.taxonomy.DeleteTag(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
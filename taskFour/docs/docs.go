@@ -24,9 +24,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "用户登录获取JWT令牌",
+        "/admin/comments": {
+            "get": {
+                "description": "按创建时间正序获取待审核（pending）或被判定为垃圾（spam）的评论（需要 comment:moderate 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,54 +34,79 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "管理"
                 ],
-                "summary": "用户登录",
+                "summary": "获取评论审核队列",
                 "parameters": [
                     {
-                        "description": "登录信息",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginInput"
-                        }
+                        "enum": [
+                            "pending",
+                            "spam"
+                        ],
+                        "type": "string",
+                        "default": "pending",
+                        "description": "审核状态",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功",
+                        "description": "成功获取审核队列",
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "401": {
-                        "description": "认证失败",
+                        "description": "未认证",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/register": {
+        "/admin/comments/approve": {
             "post": {
-                "description": "注册新用户账号",
+                "description": "将待审核或被误判为垃圾的评论标记为通过（需要 comment:moderate 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -89,23 +114,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "管理"
                 ],
-                "summary": "用户注册",
+                "summary": "批量通过评论",
                 "parameters": [
                     {
-                        "description": "注册信息",
+                        "description": "评论ID列表",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.RegisterInput"
+                            "$ref": "#/definitions/controllers.ModerateCommentsInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "注册成功",
+                    "200": {
+                        "description": "操作成功，返回实际更新的数量",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -114,28 +139,38 @@ const docTemplate = `{
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
-            }
-        },
-        "/comments": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "对文章发表评论（需要认证）",
+                ]
+            }
+        },
+        "/admin/comments/reject": {
+            "post": {
+                "description": "将待审核的评论标记为垃圾，不再公开（需要 comment:moderate 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -143,23 +178,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "评论"
+                    "管理"
                 ],
-                "summary": "创建评论",
+                "summary": "批量拒绝评论",
                 "parameters": [
                     {
-                        "description": "评论内容",
+                        "description": "评论ID列表",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.CreateCommentInput"
+                            "$ref": "#/definitions/controllers.ModerateCommentsInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "评论创建成功",
+                    "200": {
+                        "description": "操作成功，返回实际更新的数量",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -168,37 +203,38 @@ const docTemplate = `{
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "401": {
                         "description": "未认证",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
-                    "404": {
-                        "description": "文章未找到",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/health": {
-            "get": {
-                "description": "检查服务是否正常运行",
+        "/admin/comments/{id}": {
+            "delete": {
+                "description": "删除任意用户的评论，仍有回复的评论保留为墓碑（需要 comment:moderate 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -206,25 +242,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "系统"
+                    "管理"
+                ],
+                "summary": "删除违规评论",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "评论ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "健康检查",
                 "responses": {
                     "200": {
-                        "description": "服务状态",
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "无效的评论ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评论未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/posts": {
+        "/admin/users": {
             "get": {
-                "description": "获取分页的文章列表",
+                "description": "分页获取用户及其角色（需要 user:manage 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -232,9 +310,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "文章"
+                    "管理"
                 ],
-                "summary": "获取文章列表",
+                "summary": "获取用户列表",
                 "parameters": [
                     {
                         "type": "integer",
@@ -253,27 +331,47 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功获取文章列表",
+                        "description": "成功获取用户列表",
                         "schema": {
-                            "$ref": "#/definitions/controllers.PostsResponse"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "分页参数无效",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "创建新的博客文章（需要认证）",
+                ]
+            }
+        },
+        "/admin/users/{id}/role": {
+            "put": {
+                "description": "修改指定用户的角色，不能修改自己的角色（需要 user:manage 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -281,23 +379,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "文章"
+                    "管理"
                 ],
-                "summary": "创建文章",
+                "summary": "修改用户角色",
                 "parameters": [
                     {
-                        "description": "文章内容",
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "角色",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.CreatePostInput"
+                            "$ref": "#/definitions/controllers.UpdateRoleInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "创建成功",
+                    "200": {
+                        "description": "修改成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -306,30 +411,44 @@ const docTemplate = `{
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "401": {
                         "description": "未认证",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/posts/{id}": {
-            "get": {
-                "description": "根据ID获取单篇文章的详细信息",
+        "/auth/login": {
+            "post": {
+                "description": "用户登录获取JWT令牌",
                 "consumes": [
                     "application/json"
                 ],
@@ -337,56 +456,131 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "文章"
+                    "认证"
                 ],
-                "summary": "获取单篇文章",
+                "summary": "用户登录",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "登录信息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功获取文章",
+                        "description": "登录成功",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controllers.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "无效的文章ID",
+                        "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
-                    "404": {
-                        "description": "文章未找到",
+                    "401": {
+                        "description": "认证失败",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "吊销当前访问令牌，携带刷新令牌时同时吊销该次登录的所有刷新令牌（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "用户登出",
+                "parameters": [
+                    {
+                        "description": "刷新令牌",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LogoutInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登出成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/auth/nonce": {
+            "get": {
+                "description": "生成一次性随机数，需要写入 EIP-4361 消息的 Nonce 字段",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
-                "description": "更新指定文章的内容（仅文章作者可操作）",
+                "tags": [
+                    "认证"
+                ],
+                "summary": "获取钱包登录随机数",
+                "responses": {
+                    "200": {
+                        "description": "成功生成随机数",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.NonceResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌立即失效",
                 "consumes": [
                     "application/json"
                 ],
@@ -394,30 +588,75 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "文章"
+                    "认证"
                 ],
-                "summary": "更新文章",
+                "summary": "刷新令牌",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "刷新令牌",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刷新成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "刷新令牌无效或已被重复使用",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
                     },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "注册新用户账号",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "用户注册",
+                "parameters": [
                     {
-                        "description": "更新内容",
+                        "description": "注册信息",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UpdatePostInput"
+                            "$ref": "#/definitions/controllers.RegisterInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "更新成功",
+                    "201": {
+                        "description": "注册成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -426,40 +665,107 @@ const docTemplate = `{
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
-                    "403": {
-                        "description": "权限不足",
+                    "500": {
+                        "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/siwe": {
+            "post": {
+                "description": "提交 EIP-4361 消息和 personal_sign 签名，校验通过后返回与用户名密码登录相同的令牌，首次登录自动创建账号",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "钱包登录（Sign-In with Ethereum）",
+                "parameters": [
+                    {
+                        "description": "签名消息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SIWELoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
                         }
                     },
-                    "404": {
-                        "description": "文章未找到",
+                    "400": {
+                        "description": "消息格式错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "签名、域名、随机数校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "地址形式的用户名已被占用",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "获取全部分类，按父子关系组装成树",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分类"
+                ],
+                "summary": "获取分类树",
+                "responses": {
+                    "200": {
+                        "description": "成功获取分类树",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
                     }
                 }
             },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "删除指定文章（仅文章作者可操作）",
+            "post": {
+                "description": "创建分类，可指定父分类，未指定 slug 时由名称生成（需要 taxonomy:manage 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -467,60 +773,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "文章"
+                    "分类"
                 ],
-                "summary": "删除文章",
+                "summary": "创建分类",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "分类信息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CategoryInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "删除成功",
+                    "201": {
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "无效的文章ID",
+                        "description": "请求参数错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "403": {
                         "description": "权限不足",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
-                    "404": {
-                        "description": "文章未找到",
+                    "409": {
+                        "description": "slug 已存在",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/apperr.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/posts/{id}/comments": {
-            "get": {
-                "description": "获取指定文章的所有评论",
+        "/categories/{id}": {
+            "put": {
+                "description": "修改分类名称、slug 和父分类，不能移动到自身或子孙分类下（需要 taxonomy:manage 权限）",
                 "consumes": [
                     "application/json"
                 ],
@@ -528,133 +843,2499 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "评论"
+                    "分类"
                 ],
-                "summary": "获取文章评论列表",
+                "summary": "修改分类",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "文章ID",
+                        "description": "分类ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "分类信息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CategoryInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功获取评论列表",
+                        "description": "修改成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "无效的文章ID",
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "分类未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "slug 已存在",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "删除分类，子分类和文章移动到被删除分类的父分类下（需要 taxonomy:manage 权限）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分类"
+                ],
+                "summary": "删除分类",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "分类ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "无效的分类ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "分类未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/comments": {
+            "post": {
+                "description": "对文章发表评论，指定 parent_id 时回复该评论，嵌套层数不能超过 COMMENT_MAX_DEPTH（需要认证）\n评论先经过反垃圾检查，status 为 pending 时需要审核通过后才会公开，为 spam 时不会公开",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评论"
+                ],
+                "summary": "创建评论",
+                "parameters": [
+                    {
+                        "description": "评论内容",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateCommentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "评论创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章或父评论未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/comments/{id}": {
+            "put": {
+                "description": "评论作者在发表后 COMMENT_EDIT_WINDOW 时间内可以修改内容，修改后 edited_at 记录修改时间，新内容会重新经过反垃圾检查（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评论"
+                ],
+                "summary": "修改评论",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "评论ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "评论内容",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateCommentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "不是评论作者或已超过修改时限",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评论未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "评论作者、文章作者或拥有 comment:moderate 权限的用户可以删除评论，仍有回复的评论保留为墓碑（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评论"
+                ],
+                "summary": "删除评论",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "评论ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "无效的评论ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "评论未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "进程能处理请求即返回 200，不检查数据库等依赖。/health 为兼容旧版本保留的别名",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "存活检查",
+                "responses": {
+                    "200": {
+                        "description": "服务状态",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "进程能处理请求即返回 200，不检查数据库等依赖。/health 为兼容旧版本保留的别名",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "存活检查",
+                "responses": {
+                    "200": {
+                        "description": "服务状态",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/posts": {
+            "get": {
+                "description": "获取已发布的文章列表，使用游标分页：响应中的 next_cursor / prev_cursor 作为 cursor 参数获取下一页 / 上一页，\n同样的链接也在 Link 响应头中（rel=\"next\" / rel=\"prev\"）。带 cursor 时 sort 和 order 可以省略，指定时必须与游标一致",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "获取文章列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "分页游标",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "已废弃，页码，不能与 cursor 同时使用",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量，最大为 PAGING_MAX_LIMIT",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published_at",
+                            "created_at",
+                            "updated_at",
+                            "title",
+                            "comment_count"
+                        ],
+                        "type": "string",
+                        "default": "published_at",
+                        "description": "排序字段，published_at 对未发布的文章使用创建时间",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向，默认 title 升序，其余降序",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回符合条件的文章总数",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "标签 slug",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分类 slug，包含子孙分类",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取文章列表",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PostsResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "下一页和上一页的链接"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "分页参数无效",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建新的博客文章，默认保存为草稿，status 为 scheduled 时需要指定未来的 published_at（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "创建文章",
+                "parameters": [
+                    {
+                        "description": "文章内容",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreatePostInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/posts/{id}": {
+            "get": {
+                "description": "根据ID获取单篇文章的详细信息，未发布的文章只有作者可以查看。\n响应头 ETag 为 \"\u003c文章ID\u003e-\u003c版本号\u003e-\u003c评论指纹\u003e\"，文章或评论变化时改变，请求头 If-None-Match 与之匹配时返回 304",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "获取单篇文章",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "上次响应的 ETag",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取文章",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "文章的实体标签"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "文章没有变化"
+                    },
+                    "400": {
+                        "description": "无效的文章ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "更新指定文章的内容（文章作者或拥有 post:update:any 权限的用户可操作）。\n必须通过 If-Match 请求头（获取文章时的 ETag，或 * 表示不检查）或请求体中的 version 指定修改基于的版本，\n文章已被他人修改时返回 412，error.current 为文章的当前状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "更新文章",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取文章时的 ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "更新内容",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdatePostInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "文章当前版本的实体标签"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "文章已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "没有指定修改基于的版本",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "删除指定文章（文章作者或拥有 post:delete:any 权限的用户可操作）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "删除文章",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "无效的文章ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "以 JSON Merge Patch（RFC 7396，Content-Type: application/merge-patch+json）或 JSON Patch（RFC 6902，Content-Type: application/json-patch+json）\n修改文章，补丁作用于 PostDocument，可以把 category_id 置为 null、清空 tags。打补丁后的文章按与创建文章相同的规则校验。\n权限、If-Match 和版本冲突的处理与更新文章相同，但只能通过 If-Match 指定版本",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "部分修改文章",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取文章时的 ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge Patch 为 PostDocument 的部分字段，JSON Patch 为操作数组",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PostDocument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "文章当前版本的实体标签"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "补丁无效或修改后的文章校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "JSON Patch 无法应用，如 test 操作失败",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "文章已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "不支持的补丁格式",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "没有指定修改基于的版本",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/posts/{id}/comments": {
+            "get": {
+                "description": "获取指定文章审核通过的评论。按顶级评论分页，每页包含顶级评论及其全部回复，total 为顶级评论数，游标用法与文章列表相同。\nview=flat（默认）按对话顺序返回平铺列表，用 depth 表示层级；view=tree 返回嵌套的 replies。\n顶级评论默认最新的在前，回复按时间正序；已删除但仍有回复的评论保留为 deleted=true 的占位",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "评论"
+                ],
+                "summary": "获取文章评论列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "flat",
+                            "tree"
+                        ],
+                        "type": "string",
+                        "default": "flat",
+                        "description": "返回格式",
+                        "name": "view",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页游标",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "已废弃，页码，不能与 cursor 同时使用",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页顶级评论数，最大为 PAGING_MAX_LIMIT",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "排序字段",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "顶级评论的排序方向",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回顶级评论总数",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取评论列表",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CommentsResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "下一页和上一页的链接"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "无效的文章ID或分页参数",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions": {
+            "get": {
+                "description": "获取文章的全部历史版本，按版本号倒序，未发布的文章只有作者可以查看",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "获取文章历史版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取版本列表",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "无效的文章ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions/diff": {
+            "get": {
+                "description": "返回两个版本之间内容的按行 unified diff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "比较两个版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "起始版本号",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标版本号",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取差异",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RevisionDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章或版本未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{id}/revisions/{rev}/restore": {
+            "post": {
+                "description": "用指定版本的标题和内容覆盖文章，并保存为一个新版本（仅文章作者可操作）。\n必须通过 If-Match 请求头（获取文章时的 ETag，或 * 表示不检查）指定恢复基于的版本，文章已被他人修改时返回 412",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "恢复到指定版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "版本号",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取文章时的 ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "文章当前版本的实体标签"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "文章或版本未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "文章已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "没有指定恢复基于的版本",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "检查数据库连接、数据库迁移状态以及限流使用的 Redis（配置 ratelimit.store=redis 时），全部正常时返回 200，否则返回 503，checks 中列出每个依赖的状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "就绪检查",
+                "responses": {
+                    "200": {
+                        "description": "服务可以接收流量",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "有依赖不可用",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "搜索已发布的文章和评论，按相关度排序，返回带 \u003cmark\u003e 高亮的摘要。支持 \"短语\" 和 前缀* 查询，中文按单字索引",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "搜索"
+                ],
+                "summary": "全文搜索",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "搜索关键词",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "posts",
+                            "comments"
+                        ],
+                        "type": "string",
+                        "default": "all",
+                        "description": "搜索范围",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量，最大为 PAGING_MAX_LIMIT",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "搜索结果",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "获取全部标签及每个标签下公开文章的数量，按文章数量倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分类"
+                ],
+                "summary": "获取标签列表",
+                "responses": {
+                    "200": {
+                        "description": "成功获取标签列表",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建标签，未指定 slug 时由名称生成（需要 taxonomy:manage 权限）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分类"
+                ],
+                "summary": "创建标签",
+                "parameters": [
+                    {
+                        "description": "标签信息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TagInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "标签已存在",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "description": "修改标签名称和 slug（需要 taxonomy:manage 权限）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分类"
+                ],
+                "summary": "修改标签",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "标签ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "标签信息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TagInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "标签未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "标签已存在",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "删除标签并解除它与文章的关联，文章本身不受影响（需要 taxonomy:manage 权限）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分类"
+                ],
+                "summary": "删除标签",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "标签ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "无效的标签ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "标签未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/me/identities": {
+            "get": {
+                "description": "获取当前账号关联的密码、邮箱和钱包身份（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "获取身份列表",
+                "responses": {
+                    "200": {
+                        "description": "成功获取身份列表",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/me/identities/email": {
+            "post": {
+                "description": "为当前账号添加邮箱（需要认证）。邮箱未经验证，不会设为主邮箱，也不占用地址，其他账号注册或添加同一邮箱时会替换它",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "添加邮箱身份",
+                "parameters": [
+                    {
+                        "description": "邮箱",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AddEmailInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "添加成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "邮箱已被使用",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/me/identities/password": {
+            "post": {
+                "description": "为没有密码的账号（如钱包登录自动创建的账号）设置密码，之后可以用用户名和密码登录（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "设置密码",
+                "parameters": [
+                    {
+                        "description": "密码",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AddPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "设置成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "账号已设置密码",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/me/identities/wallet": {
+            "post": {
+                "description": "先调用 /auth/nonce 获取随机数，再提交用该钱包签名的 EIP-4361 消息，校验通过后关联到当前账号（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "关联钱包地址",
+                "parameters": [
+                    {
+                        "description": "签名消息",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SIWELoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "关联成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "消息格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "签名、域名、随机数校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "钱包已被关联",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/me/identities/{id}": {
+            "delete": {
+                "description": "解除当前账号的某个身份，不能删除最后一个可登录的身份（密码或钱包）（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "删除身份",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "身份ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "不能删除最后一个登录方式",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "身份未找到",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/me/posts": {
+            "get": {
+                "description": "获取当前用户的全部文章，包括草稿、定时和归档文章，分页参数与文章列表相同，默认按修改时间倒序（需要认证）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "获取我的文章",
+                "parameters": [
+                    {
+                        "enum": [
+                            "draft",
+                            "published",
+                            "scheduled",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "description": "按状态过滤",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页游标",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "已废弃，页码，不能与 cursor 同时使用",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量，最大为 PAGING_MAX_LIMIT",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "published_at",
+                            "created_at",
+                            "updated_at",
+                            "title",
+                            "comment_count"
+                        ],
+                        "type": "string",
+                        "default": "updated_at",
+                        "description": "排序字段",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向，默认 title 升序，其余降序",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回符合条件的文章总数",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功获取文章列表",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PostsResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "下一页和上一页的链接"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "分页参数无效",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "apperr.Body": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "current": {
+                    "type": "object"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperr.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Post not found"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "a5f1bf16-ac6f-484f-93f0-8d125ed79293"
+                }
+            }
+        },
+        "apperr.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "title"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "title is required"
+                },
+                "param": {
+                    "type": "string",
+                    "example": ""
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "apperr.Response": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/apperr.Body"
+                }
+            }
+        },
+        "controllers.AddEmailInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "another@example.com"
+                }
+            }
+        },
+        "controllers.AddPasswordInput": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "password123"
+                }
+            }
+        },
+        "controllers.CategoryInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "区块链"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 60,
+                    "example": "blockchain"
+                }
+            }
+        },
+        "controllers.CommentsResponse": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Comment"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoicHVibGlzaGVkX2F0IiwibyI6ImRlc2MiLCJ2IjoiMjAyNS0wMS0wMVQwODowMDowMFoiLCJpIjo0Mn0"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "controllers.CreateCommentInput": {
+            "type": "object",
+            "required": [
+                "content",
+                "post_id"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "这是一条评论"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "post_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.CreatePostInput": {
+            "type": "object",
+            "required": [
+                "content",
+                "title"
+            ],
+            "properties": {
+                "category_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "content": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "这是文章的内容..."
+                },
+                "published_at": {
+                    "type": "string",
+                    "example": "2025-01-01T08:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "published",
+                        "scheduled"
+                    ],
+                    "example": "draft"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "区块链",
+                        "以太坊"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1,
+                    "example": "我的第一篇文章"
+                }
+            }
+        },
+        "controllers.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency": {
+                    "type": "string",
+                    "example": "1.2ms"
+                },
+                "latest": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "pending": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "controllers.LoginInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "testuser"
+                }
+            }
+        },
+        "controllers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Login successful"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q3V1c2VyLXJlZnJlc2gtdG9rZW4..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user": {
+                    "type": "object",
+                    "properties": {
+                        "email": {
+                            "type": "string",
+                            "example": "test@example.com"
+                        },
+                        "id": {
+                            "type": "integer",
+                            "example": 1
+                        },
+                        "role": {
+                            "type": "string",
+                            "example": "user"
+                        },
+                        "username": {
+                            "type": "string",
+                            "example": "testuser"
+                        }
+                    }
+                }
+            }
+        },
+        "controllers.LogoutInput": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q3V1c2VyLXJlZnJlc2gtdG9rZW4..."
+                }
+            }
+        },
+        "controllers.ModerateCommentsInput": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "controllers.NonceResponse": {
+            "type": "object",
+            "properties": {
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "domain": {
+                    "type": "string",
+                    "example": "localhost:8080"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "string",
+                    "example": "9f1c2a7b4e6d8f01a2b3c4d5"
+                }
+            }
+        },
+        "controllers.PostDocument": {
+            "type": "object",
+            "required": [
+                "content",
+                "status",
+                "title"
+            ],
+            "properties": {
+                "category_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "content": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "这是文章的内容..."
+                },
+                "published_at": {
+                    "type": "string",
+                    "example": "2025-01-01T08:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "published",
+                        "scheduled",
+                        "archived"
+                    ],
+                    "example": "published"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "区块链",
+                        "以太坊"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1,
+                    "example": "我的第一篇文章"
+                }
+            }
+        },
+        "controllers.PostsResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoicHVibGlzaGVkX2F0IiwibyI6ImRlc2MiLCJ2IjoiMjAyNS0wMS0wMVQwODowMDowMFoiLCJpIjo0Mn0"
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Post"
                     }
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.CreateCommentInput": {
+        },
+        "controllers.ReadinessResponse": {
             "type": "object",
-            "required": [
-                "content",
-                "post_id"
-            ],
             "properties": {
-                "content": {
-                    "type": "string",
-                    "minLength": 1,
-                    "example": "这是一条评论"
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/controllers.DependencyStatus"
+                    }
                 },
-                "post_id": {
-                    "type": "integer",
-                    "example": 1
+                "status": {
+                    "type": "string",
+                    "example": "ok"
                 }
             }
         },
-        "controllers.CreatePostInput": {
+        "controllers.RefreshInput": {
             "type": "object",
             "required": [
-                "content",
-                "title"
+                "refresh_token"
             ],
             "properties": {
-                "content": {
-                    "type": "string",
-                    "minLength": 1,
-                    "example": "这是文章的内容..."
-                },
-                "title": {
+                "refresh_token": {
                     "type": "string",
-                    "maxLength": 200,
-                    "minLength": 1,
-                    "example": "我的第一篇文章"
+                    "example": "q3V1c2VyLXJlZnJlc2gtdG9rZW4..."
                 }
             }
         },
-        "controllers.LoginInput": {
+        "controllers.RegisterInput": {
             "type": "object",
             "required": [
+                "email",
                 "password",
                 "username"
             ],
             "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "test@example.com"
+                },
                 "password": {
                     "type": "string",
+                    "minLength": 6,
                     "example": "password123"
                 },
                 "username": {
                     "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
                     "example": "testuser"
                 }
             }
         },
-        "controllers.LoginResponse": {
+        "controllers.RevisionDiffResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "diff": {
                     "type": "string",
-                    "example": "Login successful"
+                    "example": "--- rev 1\n+++ rev 2\n@@ -1 +1 @@\n-旧内容\n+新内容\n"
                 },
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                "from": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "user": {
+                "title": {
                     "type": "object",
                     "properties": {
-                        "email": {
+                        "from": {
                             "type": "string",
-                            "example": "test@example.com"
-                        },
-                        "id": {
-                            "type": "integer",
-                            "example": 1
+                            "example": "旧标题"
                         },
-                        "username": {
+                        "to": {
                             "type": "string",
-                            "example": "testuser"
+                            "example": "新标题"
                         }
                     }
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "controllers.PostsResponse": {
+        "controllers.SIWELoginInput": {
+            "type": "object",
+            "required": [
+                "message",
+                "signature"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "localhost:8080 wants you to sign in with your Ethereum account:\n0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf\n\nSign in to the blog.\n\nURI: http://localhost:8080\nVersion: 1\nChain ID: 1\nNonce: 9f1c2a7b4e6d8f01a2b3c4d5\nIssued At: 2025-01-01T00:00:00Z"
+                },
+                "signature": {
+                    "type": "string",
+                    "example": "0x..."
+                }
+            }
+        },
+        "controllers.SearchResponse": {
             "type": "object",
             "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CommentSearchResult"
+                    }
+                },
                 "limit": {
                     "type": "integer",
                     "example": 10
@@ -666,49 +3347,159 @@ const docTemplate = `{
                 "posts": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Post"
+                        "$ref": "#/definitions/models.PostSearchResult"
                     }
+                },
+                "query": {
+                    "type": "string",
+                    "example": "区块链"
                 }
             }
         },
-        "controllers.RegisterInput": {
+        "controllers.TagInput": {
             "type": "object",
             "required": [
-                "email",
-                "password",
-                "username"
+                "name"
             ],
             "properties": {
-                "email": {
+                "name": {
                     "type": "string",
-                    "example": "test@example.com"
+                    "maxLength": 50,
+                    "example": "以太坊"
                 },
-                "password": {
+                "slug": {
                     "type": "string",
-                    "minLength": 6,
-                    "example": "password123"
+                    "maxLength": 60,
+                    "example": "ethereum"
+                }
+            }
+        },
+        "controllers.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
                 },
-                "username": {
+                "refresh_token": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "testuser"
+                    "example": "q3V1c2VyLXJlZnJlc2gtdG9rZW4..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "controllers.UpdateCommentInput": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "修改后的评论"
                 }
             }
         },
         "controllers.UpdatePostInput": {
             "type": "object",
             "properties": {
+                "category_id": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "content": {
                     "type": "string",
                     "minLength": 1,
                     "example": "更新后的文章内容..."
                 },
+                "published_at": {
+                    "type": "string",
+                    "example": "2025-01-01T08:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "published",
+                        "scheduled",
+                        "archived"
+                    ],
+                    "example": "published"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "区块链",
+                        "以太坊"
+                    ]
+                },
                 "title": {
                     "type": "string",
                     "maxLength": 200,
                     "minLength": 1,
                     "example": "更新后的文章标题"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "controllers.UpdateRoleInput": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "editor",
+                        "admin"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
@@ -721,12 +3512,36 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "depth": {
+                    "type": "integer"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "moderation_note": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
                 "post_id": {
                     "type": "integer"
                 },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Comment"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
                 "user": {
                     "$ref": "#/definitions/models.User"
                 },
@@ -735,9 +3550,42 @@ const docTemplate = `{
                 }
             }
         },
+        "models.CommentSearchResult": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.Post": {
             "type": "object",
             "properties": {
+                "category": {
+                    "$ref": "#/definitions/models.Category"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "comment_count": {
+                    "description": "CommentCount 公开的评论数（审核通过且未删除），由 PostRelations 查询，不保存",
+                    "type": "integer"
+                },
                 "comments": {
                     "type": "array",
                     "items": {
@@ -753,6 +3601,18 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "published_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
                 "title": {
                     "type": "string"
                 },
@@ -762,11 +3622,58 @@ const docTemplate = `{
                 "user": {
                     "$ref": "#/definitions/models.User"
                 },
+                "user_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Version 版本号，每次修改加一，用于乐观并发控制（ETag / If-Match）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PostSearchResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "title_highlight": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
             }
         },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -779,6 +3686,9 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "role": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
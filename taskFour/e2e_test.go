@@ -0,0 +1,666 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"taskFour/config"
+	"taskFour/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestE2E 按功能划分的端到端场景，每个场景使用独立的数据库。
+// 全部场景都执行时额外检查 setupRouter 中的每个路由至少被访问过一次
+func TestE2E(t *testing.T) {
+	scenarios := []struct {
+		name string
+		run  func(t *testing.T, s *testServer)
+	}{
+		{"probes", e2eProbes},
+		{"auth", e2eAuth},
+		{"wallet", e2eWallet},
+		{"identities", e2eIdentities},
+		{"posts", e2ePosts},
+		{"concurrency", e2eConcurrency},
+		{"patch", e2ePatch},
+		{"pagination", e2ePagination},
+		{"revisions", e2eRevisions},
+		{"comments", e2eComments},
+		{"taxonomy", e2eTaxonomy},
+		{"search", e2eSearch},
+		{"admin", e2eAdmin},
+	}
+
+	routes := newRouteRecorder()
+	ran := 0
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			ran++
+			sc.run(t, newTestServer(t, routes))
+		})
+	}
+
+	if ran == len(scenarios) && !t.Failed() {
+		if missing := routes.missing(); len(missing) > 0 {
+			t.Errorf("routes not covered by end-to-end tests:\n%s", strings.Join(missing, "\n"))
+		}
+	}
+}
+
+func e2eProbes(t *testing.T, s *testServer) {
+	s.golden("probes_livez", s.do("GET", "/livez", "", nil, http.StatusOK))
+	s.golden("probes_readyz", s.do("GET", "/readyz", "", nil, http.StatusOK))
+	s.do("GET", "/health", "", nil, http.StatusOK)
+
+	if w := s.request("GET", "/metrics", "", nil, nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "blog_http_requests_total") {
+		t.Errorf("metrics: status %d", w.Code)
+	}
+	if w := s.request("GET", "/swagger/index.html", "", nil, nil); w.Code != http.StatusOK {
+		t.Errorf("swagger: status %d", w.Code)
+	}
+	if w := s.request("GET", "/api/posts/1/unknown", "", nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown route: status %d", w.Code)
+	}
+}
+
+func e2eAuth(t *testing.T, s *testServer) {
+	s.golden("auth_register", s.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated))
+	s.golden("auth_register_duplicate", s.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "other@example.com"}, http.StatusBadRequest))
+	s.golden("auth_register_invalid", s.do("POST", "/api/auth/register", "", gin.H{"username": "al", "password": "123", "email": "not-an-email"}, http.StatusBadRequest))
+
+	login := s.do("POST", "/api/auth/login", "", gin.H{"username": "alice", "password": "password123"}, http.StatusOK)
+	s.golden("auth_login", login)
+	s.golden("auth_login_wrong_password", s.do("POST", "/api/auth/login", "", gin.H{"username": "alice", "password": "wrong-password"}, http.StatusUnauthorized))
+	s.do("POST", "/api/auth/login", "", gin.H{"username": "nobody", "password": "password123"}, http.StatusUnauthorized)
+
+	// 刷新令牌轮换后旧令牌不能再用，重复使用会吊销整个登录
+	refreshed := s.do("POST", "/api/auth/refresh", "", gin.H{"refresh_token": login["refresh_token"]}, http.StatusOK)
+	s.golden("auth_refresh", refreshed)
+	s.golden("auth_refresh_reused", s.do("POST", "/api/auth/refresh", "", gin.H{"refresh_token": login["refresh_token"]}, http.StatusUnauthorized))
+	s.do("POST", "/api/auth/refresh", "", gin.H{"refresh_token": refreshed["refresh_token"]}, http.StatusUnauthorized)
+
+	// 登出后访问令牌和刷新令牌都失效
+	user := s.register("bob")
+	s.do("POST", "/api/auth/logout", "", nil, http.StatusUnauthorized)
+	s.golden("auth_logout", s.do("POST", "/api/auth/logout", user.Token, gin.H{"refresh_token": user.RefreshToken}, http.StatusOK))
+	s.golden("auth_revoked_token", s.do("GET", "/api/users/me/posts", user.Token, nil, http.StatusUnauthorized))
+	s.do("POST", "/api/auth/refresh", "", gin.H{"refresh_token": user.RefreshToken}, http.StatusUnauthorized)
+	s.do("GET", "/api/users/me/posts", "not-a-token", nil, http.StatusUnauthorized)
+}
+
+func e2eWallet(t *testing.T, s *testServer) {
+	wallet := newTestWallet(t, 1)
+
+	s.golden("wallet_nonce", s.do("GET", "/api/auth/nonce", "", nil, http.StatusOK))
+
+	// 首次钱包登录自动创建以地址为用户名的账号，随机数只能使用一次
+	input := s.signIn(wallet)
+	login := s.do("POST", "/api/auth/siwe", "", input, http.StatusOK)
+	s.golden("wallet_login", login)
+	s.golden("wallet_nonce_reused", s.do("POST", "/api/auth/siwe", "", input, http.StatusUnauthorized))
+
+	again := s.do("POST", "/api/auth/siwe", "", s.signIn(wallet), http.StatusOK)
+	if again["user"].(map[string]interface{})["id"] != login["user"].(map[string]interface{})["id"] {
+		t.Fatalf("second wallet login created another account: %v", again["user"])
+	}
+
+	// 用其他钱包的签名冒充
+	forged := s.signIn(newTestWallet(t, 2))
+	forged["message"] = strings.Replace(forged["message"].(string), newTestWallet(t, 2).Address, wallet.Address, 1)
+	s.golden("wallet_forged_signature", s.do("POST", "/api/auth/siwe", "", forged, http.StatusUnauthorized))
+	s.golden("wallet_invalid_message", s.do("POST", "/api/auth/siwe", "", gin.H{"message": "hello", "signature": "0x00"}, http.StatusBadRequest))
+
+	// 地址形式的用户名留给钱包账号，不能抢注；此前已被注册的地址用户名返回 409 而不是 500
+	squatted := newTestWallet(t, 4)
+	s.golden("wallet_username_reserved", s.do("POST", "/api/auth/register", "", gin.H{"username": strings.ToUpper(squatted.Address), "password": "password123", "email": "squat@example.com"}, http.StatusBadRequest))
+	if err := config.GetDB().Create(&models.User{Username: squatted.Address, Password: "password123"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	s.do("POST", "/api/auth/siwe", "", s.signIn(squatted), http.StatusConflict)
+}
+
+func e2eIdentities(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	other := s.register("bob")
+
+	s.golden("identities_list", s.do("GET", "/api/users/me/identities", alice.Token, nil, http.StatusOK))
+	s.do("POST", "/api/users/me/identities/email", alice.Token, gin.H{"email": "alice@work.example.com"}, http.StatusCreated)
+	s.golden("identities_email_conflict", s.do("POST", "/api/users/me/identities/email", other.Token, gin.H{"email": "alice@example.com"}, http.StatusConflict))
+
+	wallet := newTestWallet(t, 3)
+	s.do("POST", "/api/users/me/identities/wallet", alice.Token, s.signIn(wallet), http.StatusCreated)
+	s.do("POST", "/api/users/me/identities/wallet", other.Token, s.signIn(wallet), http.StatusConflict)
+
+	identities := s.do("GET", "/api/users/me/identities", alice.Token, nil, http.StatusOK)
+	s.golden("identities_linked", identities)
+
+	// 关联后可以用钱包登录同一个账号
+	login := s.do("POST", "/api/auth/siwe", "", s.signIn(wallet), http.StatusOK)
+	if login["user"].(map[string]interface{})["id"] != alice.ID {
+		t.Fatalf("wallet login returned %v, want alice", login["user"])
+	}
+
+	for _, item := range identities["identities"].([]interface{}) {
+		identity := item.(map[string]interface{})
+		path := fmt.Sprintf("/api/users/me/identities/%v", identity["id"])
+		switch identity["provider"] {
+		case "email":
+			s.do("DELETE", path, other.Token, nil, http.StatusNotFound)
+			s.do("DELETE", path, alice.Token, nil, http.StatusOK)
+		}
+	}
+	s.do("DELETE", "/api/users/me/identities/abc", alice.Token, nil, http.StatusBadRequest)
+
+	// 只剩钱包的账号不能删除最后一种登录方式
+	walletOnly := s.do("POST", "/api/auth/siwe", "", s.signIn(newTestWallet(t, 4)), http.StatusOK)
+	list := s.do("GET", "/api/users/me/identities", walletOnly["token"].(string), nil, http.StatusOK)["identities"].([]interface{})
+	expectLen(t, list, 1)
+	last := fmt.Sprintf("/api/users/me/identities/%v", list[0].(map[string]interface{})["id"])
+	s.golden("identities_last_login_method", s.do("DELETE", last, walletOnly["token"].(string), nil, http.StatusBadRequest))
+
+	// 未验证的邮箱不会写入 users 表，也不占用地址：邮箱的主人仍然可以注册，注册后抢先添加的身份被替换
+	walletToken := walletOnly["token"].(string)
+	s.do("POST", "/api/users/me/identities/email", walletToken, gin.H{"email": "carol@example.com"}, http.StatusCreated)
+	var user models.User
+	if err := config.GetDB().First(&user, walletOnly["user"].(map[string]interface{})["id"]).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != nil {
+		t.Fatalf("primary email = %v, want unverified email not to be copied", *user.Email)
+	}
+	s.register("carol")
+	list = s.do("GET", "/api/users/me/identities", walletToken, nil, http.StatusOK)["identities"].([]interface{})
+	expectLen(t, list, 1)
+
+	// 只有钱包的账号可以设置密码，之后用地址作为用户名登录
+	s.do("POST", "/api/users/me/identities/password", walletToken, gin.H{"password": "short"}, http.StatusBadRequest)
+	s.do("POST", "/api/users/me/identities/password", walletToken, gin.H{"password": "password123"}, http.StatusCreated)
+	s.golden("identities_password_conflict", s.do("POST", "/api/users/me/identities/password", walletToken, gin.H{"password": "password456"}, http.StatusConflict))
+	s.do("POST", "/api/users/me/identities/password", alice.Token, gin.H{"password": "password456"}, http.StatusConflict)
+	username := walletOnly["user"].(map[string]interface{})["username"]
+	s.do("POST", "/api/auth/login", "", gin.H{"username": username, "password": "password123"}, http.StatusOK)
+
+	// 有两种登录方式后可以删除钱包，之后密码成为最后一种登录方式
+	list = s.do("GET", "/api/users/me/identities", walletToken, nil, http.StatusOK)["identities"].([]interface{})
+	expectLen(t, list, 2)
+	s.do("DELETE", last, walletToken, nil, http.StatusOK)
+	s.do("DELETE", last, walletToken, nil, http.StatusNotFound)
+	password := fmt.Sprintf("/api/users/me/identities/%v", list[1].(map[string]interface{})["id"])
+	s.do("DELETE", password, walletToken, nil, http.StatusBadRequest)
+}
+
+func e2ePosts(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	bob := s.register("bob")
+	editor := s.registerWithRole("erin", "editor")
+
+	post := s.do("POST", "/api/posts", alice.Token, gin.H{"title": "以太坊入门", "content": "智能合约", "status": "published", "tags": []string{"区块链", "以太坊"}}, http.StatusCreated)
+	s.golden("posts_create", post)
+	id := post["post"].(map[string]interface{})["id"]
+	path := fmt.Sprintf("/api/posts/%v", id)
+
+	s.golden("posts_create_invalid", s.do("POST", "/api/posts", alice.Token, gin.H{"title": "", "status": "unknown", "tags": []string{""}}, http.StatusBadRequest))
+	s.golden("posts_create_schedule_in_past", s.do("POST", "/api/posts", alice.Token, gin.H{"title": "定时", "content": "内容", "status": "scheduled", "published_at": "2020-01-01T00:00:00Z"}, http.StatusBadRequest))
+	s.do("POST", "/api/posts", alice.Token, gin.H{"title": "分类", "content": "内容", "category_id": 999}, http.StatusBadRequest)
+	s.do("POST", "/api/posts", "", gin.H{"title": "匿名", "content": "内容"}, http.StatusUnauthorized)
+
+	s.golden("posts_get", s.do("GET", path, "", nil, http.StatusOK))
+	s.golden("posts_get_not_found", s.do("GET", "/api/posts/999", "", nil, http.StatusNotFound))
+	s.do("GET", "/api/posts/abc", "", nil, http.StatusBadRequest)
+
+	// 草稿和定时文章只有作者和编辑可见
+	draft := s.createPost(bob, gin.H{"title": "草稿"})
+	draftPath := fmt.Sprintf("/api/posts/%v", draft["id"])
+	s.do("GET", draftPath, "", nil, http.StatusNotFound)
+	s.do("GET", draftPath, alice.Token, nil, http.StatusNotFound)
+	s.do("GET", draftPath, bob.Token, nil, http.StatusOK)
+	s.do("GET", draftPath, editor.Token, nil, http.StatusOK)
+	scheduled := s.createPost(bob, gin.H{"title": "定时", "status": "scheduled", "published_at": time.Now().Add(time.Hour).Format(time.RFC3339)})
+	s.do("GET", fmt.Sprintf("/api/posts/%v", scheduled["id"]), "", nil, http.StatusNotFound)
+
+	s.golden("posts_list", s.do("GET", "/api/posts", "", nil, http.StatusOK))
+	s.golden("posts_mine", s.do("GET", "/api/users/me/posts", bob.Token, nil, http.StatusOK))
+	expectLen(t, s.do("GET", "/api/users/me/posts?status=draft", bob.Token, nil, http.StatusOK)["posts"], 1)
+	expectLen(t, s.do("GET", "/api/posts?tag="+url.QueryEscape("以太坊"), "", nil, http.StatusOK)["posts"], 1)
+	expectLen(t, s.do("GET", "/api/posts?category=missing", "", nil, http.StatusOK)["posts"], 0)
+
+	// 只有作者和拥有 post:update:any / post:delete:any 权限的用户可以修改和删除
+	s.golden("posts_update_forbidden", s.do("PUT", path, bob.Token, gin.H{"title": "篡改"}, http.StatusForbidden))
+	s.golden("posts_update", s.do("PUT", path, alice.Token, gin.H{"title": "以太坊入门（修订）", "tags": []string{"以太坊"}, "version": 1}, http.StatusOK))
+	s.do("PUT", path, editor.Token, gin.H{"status": "archived", "version": 2}, http.StatusOK)
+	s.do("GET", path, "", nil, http.StatusNotFound)
+	s.do("PUT", "/api/posts/999", alice.Token, gin.H{"title": "不存在"}, http.StatusNotFound)
+	s.do("PUT", path, alice.Token, gin.H{"status": "unknown"}, http.StatusBadRequest)
+
+	s.golden("posts_delete_forbidden", s.do("DELETE", draftPath, alice.Token, nil, http.StatusForbidden))
+	s.golden("posts_delete", s.do("DELETE", draftPath, editor.Token, nil, http.StatusOK))
+	s.do("DELETE", path, alice.Token, nil, http.StatusOK)
+	s.do("DELETE", path, alice.Token, nil, http.StatusNotFound)
+}
+
+func e2ePagination(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	bob := s.register("bob")
+	var ids []interface{}
+	for i := 1; i <= 5; i++ {
+		ids = append(ids, s.createPost(alice, gin.H{"title": fmt.Sprintf("第 %d 篇", i), "status": "published"})["id"])
+	}
+	// 标题相同时按 ID 排序，分页边界上不重复也不遗漏
+	s.createPost(alice, gin.H{"title": "第 3 篇", "status": "published"})
+	s.createPost(alice, gin.H{"title": "草稿"})
+	for i := 0; i < 3; i++ {
+		s.createComment(bob, ids[1], fmt.Sprintf("评论 %d", i), nil)
+	}
+	// 新账号每小时只有前几条评论直接公开，换一个用户
+	s.createComment(s.register("carol"), ids[3], "评论", nil)
+
+	titles := func(response map[string]interface{}) string {
+		t.Helper()
+		var titles []string
+		for _, p := range response["posts"].([]interface{}) {
+			post := p.(map[string]interface{})
+			titles = append(titles, fmt.Sprintf("%v#%v", post["title"], post["id"]))
+		}
+		return strings.Join(titles, ",")
+	}
+
+	// 沿 next_cursor 翻到最后一页，再沿 prev_cursor 翻回第一页
+	var pages []string
+	var last map[string]interface{}
+	for path := "/api/posts?limit=2"; path != ""; {
+		w := s.request("GET", path, "", nil, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body.String())
+		}
+		last = s.do("GET", path, "", nil, http.StatusOK)
+		pages = append(pages, titles(last))
+
+		next, _ := last["next_cursor"].(string)
+		path = ""
+		if next != "" {
+			path = "/api/posts?limit=2&cursor=" + next
+			if link := w.Header().Get("Link"); !strings.Contains(link, `cursor=`+next) || !strings.Contains(link, `rel="next"`) {
+				t.Errorf("Link header %q does not point to next page", link)
+			}
+		}
+	}
+	want := "第 3 篇#6,第 5 篇#5|第 4 篇#4,第 3 篇#3|第 2 篇#2,第 1 篇#1"
+	if got := strings.Join(pages, "|"); got != want {
+		t.Errorf("pages = %q, want %q", got, want)
+	}
+	var back []string
+	for prev, _ := last["prev_cursor"].(string); prev != ""; prev, _ = last["prev_cursor"].(string) {
+		last = s.do("GET", "/api/posts?limit=2&cursor="+prev, "", nil, http.StatusOK)
+		back = append([]string{titles(last)}, back...)
+	}
+	if got := strings.Join(back, "|"); got != "第 3 篇#6,第 5 篇#5|第 4 篇#4,第 3 篇#3" {
+		t.Errorf("pages walking back = %q", got)
+	}
+
+	for query, want := range map[string]string{
+		"?sort=title&limit=3":                "第 1 篇#1,第 2 篇#2,第 3 篇#3",
+		"?sort=title&order=desc&limit=3":     "第 5 篇#5,第 4 篇#4,第 3 篇#6",
+		"?sort=created_at&order=asc&limit=2": "第 1 篇#1,第 2 篇#2",
+		"?sort=comment_count&limit=3":        "第 2 篇#2,第 4 篇#4,第 3 篇#6",
+	} {
+		if got := titles(s.do("GET", "/api/posts"+query, "", nil, http.StatusOK)); got != want {
+			t.Errorf("GET /api/posts%s = %q, want %q", query, got, want)
+		}
+	}
+
+	// 排序方式保存在游标中，翻页时可以省略
+	first := s.do("GET", "/api/posts?sort=title&limit=2", "", nil, http.StatusOK)
+	second := s.do("GET", "/api/posts?limit=2&cursor="+first["next_cursor"].(string), "", nil, http.StatusOK)
+	if got := titles(second); got != "第 3 篇#3,第 3 篇#6" {
+		t.Errorf("second page by title = %q", got)
+	}
+	s.golden("pagination_cursor_sort_mismatch", s.do("GET", "/api/posts?sort=created_at&cursor="+first["next_cursor"].(string), "", nil, http.StatusBadRequest))
+
+	s.golden("pagination_total", s.do("GET", "/api/posts?limit=2&total=true", "", nil, http.StatusOK))
+	s.golden("pagination_mine", s.do("GET", "/api/users/me/posts?limit=1&total=true&status=draft", alice.Token, nil, http.StatusOK))
+	s.golden("pagination_past_end", s.do("GET", "/api/posts?limit=2&category=missing", "", nil, http.StatusOK))
+
+	for _, query := range []string{"limit=0", "limit=-1", "limit=abc", "limit=101", "page=0", "page=abc", "cursor=abc", "sort=views", "order=up", "total=maybe"} {
+		s.do("GET", "/api/posts?"+query, "", nil, http.StatusBadRequest)
+	}
+	s.golden("pagination_invalid_limit", s.do("GET", "/api/posts?limit=abc", "", nil, http.StatusBadRequest))
+
+	// 已废弃的 page 参数仍然可用，结果与游标翻页相同，响应带 Deprecation 头，Link 中的链接改用游标
+	w := s.request("GET", "/api/posts?limit=2&page=2", "", nil, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Deprecation") == "" {
+		t.Fatalf("page=2: status %d, Deprecation %q", w.Code, w.Header().Get("Deprecation"))
+	}
+	if link := w.Header().Get("Link"); strings.Contains(link, "page=") || !strings.Contains(link, `rel="prev"`) {
+		t.Errorf("page=2 Link header %q", link)
+	}
+	var byPage map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &byPage); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(byPage); got != "第 4 篇#4,第 3 篇#3" {
+		t.Errorf("page=2 = %q", got)
+	}
+	next := s.do("GET", "/api/posts?limit=2&cursor="+byPage["next_cursor"].(string), "", nil, http.StatusOK)
+	if got := titles(next); got != "第 2 篇#2,第 1 篇#1" {
+		t.Errorf("page after page=2 = %q", got)
+	}
+	s.golden("pagination_page_with_cursor", s.do("GET", "/api/posts?page=2&cursor="+first["next_cursor"].(string), "", nil, http.StatusBadRequest))
+
+	admin := s.registerWithRole("root", "admin")
+	s.do("GET", "/api/admin/users?page=0", admin.Token, nil, http.StatusBadRequest)
+	s.do("GET", "/api/admin/comments?limit=1000", admin.Token, nil, http.StatusBadRequest)
+}
+
+func e2eConcurrency(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	bob := s.register("bob")
+	editor := s.registerWithRole("erin", "editor")
+
+	post := s.createPost(alice, gin.H{"title": "并发", "content": "内容", "status": "published"})
+	path := fmt.Sprintf("/api/posts/%v", post["id"])
+
+	// 读取时返回带评论指纹的 ETag，If-None-Match 匹配时返回 304
+	w := s.request("GET", path, "", nil, nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.HasPrefix(etag, `"1-1-`) {
+		t.Fatalf("get: status %d, etag %q", w.Code, etag)
+	}
+	if w := s.request("GET", path, "", nil, http.Header{"If-None-Match": {`"1-0", W/` + etag}}); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("if-none-match: status %d, etag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// 详情中带有评论，新增、修改和删除评论都会改变 ETag
+	comment := s.createComment(bob, post["id"], "沙发", nil)
+	expectChanged := func(step string) {
+		t.Helper()
+		w := s.request("GET", path, "", nil, http.Header{"If-None-Match": {etag}})
+		if w.Code != http.StatusOK {
+			t.Errorf("after %s: status %d, etag %q", step, w.Code, w.Header().Get("ETag"))
+		}
+		etag = w.Header().Get("ETag")
+	}
+	expectChanged("comment")
+	s.do("PUT", fmt.Sprintf("/api/comments/%v", comment["id"]), bob.Token, gin.H{"content": "板凳"}, http.StatusOK)
+	expectChanged("comment edit")
+	s.do("DELETE", fmt.Sprintf("/api/comments/%v", comment["id"]), bob.Token, nil, http.StatusOK)
+	expectChanged("comment delete")
+
+	// 修改必须指定基于的版本，If-Match 优先于请求体中的 version，读取的 ETag 作为 If-Match 时只比较版本号
+	s.golden("concurrency_version_required", s.do("PUT", path, alice.Token, gin.H{"title": "没有版本"}, http.StatusPreconditionRequired))
+	w = s.request("PUT", path, alice.Token, gin.H{"title": "作者修改", "version": 9}, http.Header{"If-Match": {etag}})
+	written := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || written != `"1-2"` {
+		t.Fatalf("if-match: status %d, etag %q", w.Code, written)
+	}
+	if w := s.request("GET", path, "", nil, nil); !strings.HasPrefix(w.Header().Get("ETag"), `"1-2-`) {
+		t.Errorf("get after write: status %d, etag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// 编辑基于旧版本或其他文章的 ETag 修改时返回 412、文章的当前状态和当前 ETag
+	w = s.request("PUT", path, editor.Token, gin.H{"title": "编辑修改"}, http.Header{"If-Match": {etag}})
+	if w.Code != http.StatusPreconditionFailed || w.Header().Get("ETag") != written {
+		t.Fatalf("stale if-match: status %d, etag %q", w.Code, w.Header().Get("ETag"))
+	}
+	s.golden("concurrency_stale_version", s.do("PUT", path, editor.Token, gin.H{"title": "编辑修改", "version": 1}, http.StatusPreconditionFailed))
+	for _, tag := range []string{"not-an-etag", `"2-2"`, `W/"1-2"`} {
+		if w := s.request("PUT", path, editor.Token, gin.H{"title": "编辑修改"}, http.Header{"If-Match": {tag}}); w.Code != http.StatusPreconditionFailed {
+			t.Errorf("if-match %s: status %d", tag, w.Code)
+		}
+	}
+
+	// If-Match: * 不检查版本
+	w = s.request("PUT", path, editor.Token, gin.H{"title": "编辑修改"}, http.Header{"If-Match": {"*"}})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1-3"` {
+		t.Errorf("if-match any: status %d, etag %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func e2ePatch(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	bob := s.register("bob")
+	editor := s.registerWithRole("erin", "editor")
+
+	tech := s.createCategory(editor, "技术", "tech", nil)
+	post := s.createPost(alice, gin.H{"title": "补丁", "content": "内容", "status": "published", "tags": []string{"Go", "JSON"}, "category_id": tech["id"]})
+	path := fmt.Sprintf("/api/posts/%v", post["id"])
+
+	patch := func(token, contentType, ifMatch string, body interface{}, want int) map[string]interface{} {
+		t.Helper()
+		header := http.Header{"Content-Type": {contentType}}
+		if ifMatch != "" {
+			header.Set("If-Match", ifMatch)
+		}
+		w := s.request("PATCH", path, token, body, header)
+		if w.Code != want {
+			t.Fatalf("PATCH %s: status %d, want %d: %s", path, w.Code, want, w.Body.String())
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("PATCH %s: invalid JSON: %s", path, w.Body.String())
+		}
+		return response
+	}
+	const mergePatch, jsonPatch = "application/merge-patch+json", "application/json-patch+json"
+
+	// Merge Patch 中的 null 清空分类，只修改出现的字段
+	s.golden("patch_merge", patch(alice.Token, mergePatch, `"1-1"`, gin.H{"title": "补丁（修订）", "category_id": nil}, http.StatusOK))
+
+	// JSON Patch 可以先用 test 操作确认当前值，不符时返回 409
+	patch(alice.Token, jsonPatch, `"1-2"`, []gin.H{{"op": "test", "path": "/title", "value": "补丁"}, {"op": "remove", "path": "/tags/0"}}, http.StatusConflict)
+	result := patch(alice.Token, jsonPatch, `"1-2"`, []gin.H{
+		{"op": "test", "path": "/title", "value": "补丁（修订）"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "replace", "path": "/status", "value": "archived"},
+	}, http.StatusOK)
+	updated := result["post"].(map[string]interface{})
+	expectLen(t, updated["tags"], 1)
+	if updated["status"] != "archived" || updated["version"] != float64(3) {
+		t.Fatalf("json patch: %v", updated)
+	}
+	patch(alice.Token, mergePatch, `"1-3"`, gin.H{"tags": nil, "status": "published"}, http.StatusOK)
+	expectLen(t, s.do("GET", path, "", nil, http.StatusOK)["post"].(map[string]interface{})["tags"], 0)
+
+	// 修改后的文章按创建文章的规则校验，补丁格式和版本的检查与 PUT 相同
+	s.golden("patch_invalid_post", patch(alice.Token, mergePatch, `"1-4"`, gin.H{"title": "", "tags": []string{""}, "status": "unknown"}, http.StatusBadRequest))
+	patch(alice.Token, mergePatch, `"1-4"`, gin.H{"extra": 1}, http.StatusBadRequest)
+	patch(alice.Token, mergePatch, `"1-4"`, gin.H{"title": 1}, http.StatusBadRequest)
+	patch(alice.Token, jsonPatch, `"1-4"`, gin.H{"op": "remove"}, http.StatusBadRequest)
+	patch(alice.Token, jsonPatch, `"1-4"`, []gin.H{{"op": "remove", "path": "/title"}}, http.StatusBadRequest)
+	s.golden("patch_unsupported_type", patch(alice.Token, "application/json", `"1-4"`, gin.H{"title": "普通 JSON"}, http.StatusUnsupportedMediaType))
+	patch(alice.Token, mergePatch, "", gin.H{"title": "没有版本"}, http.StatusPreconditionRequired)
+	patch(alice.Token, mergePatch, `"1-3"`, gin.H{"title": "旧版本"}, http.StatusPreconditionFailed)
+	patch(bob.Token, mergePatch, `"1-4"`, gin.H{"title": "篡改"}, http.StatusForbidden)
+	patch(editor.Token, mergePatch, "*", gin.H{"content": "编辑修改"}, http.StatusOK)
+}
+
+func e2eRevisions(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	bob := s.register("bob")
+
+	post := s.createPost(alice, gin.H{"title": "版本", "content": "第一行\n第二行", "status": "published"})
+	path := fmt.Sprintf("/api/posts/%v", post["id"])
+	s.do("PUT", path, alice.Token, gin.H{"content": "第一行\n第二行（修改）\n第三行", "version": 1}, http.StatusOK)
+
+	s.golden("revisions_list", s.do("GET", path+"/revisions", "", nil, http.StatusOK))
+	s.golden("revisions_diff", s.do("GET", path+"/revisions/diff?from=1&to=2", "", nil, http.StatusOK))
+	s.do("GET", path+"/revisions/diff?from=1", "", nil, http.StatusBadRequest)
+	s.do("GET", path+"/revisions/diff?from=1&to=9", "", nil, http.StatusNotFound)
+
+	// 恢复与 PATCH 相同，通过 If-Match 指定基于的版本，响应头返回恢复后的 ETag
+	restore := func(token string, rev int, version uint, want int) *httptest.ResponseRecorder {
+		t.Helper()
+		header := http.Header{}
+		if version > 0 {
+			header.Set("If-Match", fmt.Sprintf(`"%v-%d"`, post["id"], version))
+		}
+		w := s.request("POST", fmt.Sprintf("%s/revisions/%d/restore", path, rev), token, nil, header)
+		if w.Code != want {
+			t.Fatalf("restore revision %d: status %d, want %d: %s", rev, w.Code, want, w.Body.String())
+		}
+		return w
+	}
+	restore(bob.Token, 1, 2, http.StatusForbidden)
+	restore(alice.Token, 1, 0, http.StatusPreconditionRequired)
+	if etag := restore(alice.Token, 1, 1, http.StatusPreconditionFailed).Header().Get("ETag"); etag != fmt.Sprintf(`"%v-2"`, post["id"]) {
+		t.Fatalf("stale restore returned ETag %s", etag)
+	}
+	w := restore(alice.Token, 1, 2, http.StatusOK)
+	if etag := w.Header().Get("ETag"); etag != fmt.Sprintf(`"%v-3"`, post["id"]) {
+		t.Fatalf("restore returned ETag %s", etag)
+	}
+	var restored map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &restored); err != nil {
+		t.Fatal(err)
+	}
+	s.golden("revisions_restore", restored)
+	expectLen(t, s.do("GET", path+"/revisions", "", nil, http.StatusOK)["revisions"], 3)
+	restore(alice.Token, 9, 3, http.StatusNotFound)
+
+	// 草稿的版本历史只有作者可见
+	draft := s.createPost(alice, gin.H{"title": "草稿"})
+	s.do("GET", fmt.Sprintf("/api/posts/%v/revisions", draft["id"]), bob.Token, nil, http.StatusNotFound)
+	s.do("GET", fmt.Sprintf("/api/posts/%v/revisions", draft["id"]), alice.Token, nil, http.StatusOK)
+}
+
+func e2eComments(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	bob := s.register("bob")
+	carol := s.register("carol")
+
+	post := s.createPost(alice, gin.H{"title": "评论", "status": "published"})
+	postID := post["id"]
+	draft := s.createPost(alice, gin.H{"title": "草稿"})
+
+	root := s.do("POST", "/api/comments", bob.Token, gin.H{"content": "写得好", "post_id": postID}, http.StatusCreated)
+	s.golden("comments_create", root)
+	rootID := root["comment"].(map[string]interface{})["id"]
+	reply := s.createComment(alice, postID, "谢谢", rootID)
+	s.createComment(carol, postID, "同意", reply["id"])
+
+	s.golden("comments_draft_post", s.do("POST", "/api/comments", bob.Token, gin.H{"content": "看不到", "post_id": draft["id"]}, http.StatusNotFound))
+	s.do("POST", "/api/comments", bob.Token, gin.H{"content": "回复不存在的评论", "post_id": postID, "parent_id": 999}, http.StatusNotFound)
+	s.do("POST", "/api/comments", bob.Token, gin.H{"post_id": postID}, http.StatusBadRequest)
+
+	s.golden("comments_tree", s.do("GET", fmt.Sprintf("/api/posts/%v/comments?view=tree", postID), "", nil, http.StatusOK))
+	s.golden("comments_flat", s.do("GET", fmt.Sprintf("/api/posts/%v/comments", postID), "", nil, http.StatusOK))
+	s.do("GET", fmt.Sprintf("/api/posts/%v/comments?view=table", postID), "", nil, http.StatusBadRequest)
+	s.do("GET", fmt.Sprintf("/api/posts/%v/comments", draft["id"]), "", nil, http.StatusNotFound)
+
+	// 只有作者可以修改；评论作者、文章作者可以删除，有回复的评论保留为墓碑
+	s.golden("comments_update_forbidden", s.do("PUT", fmt.Sprintf("/api/comments/%v", rootID), carol.Token, gin.H{"content": "篡改"}, http.StatusForbidden))
+	s.golden("comments_update", s.do("PUT", fmt.Sprintf("/api/comments/%v", rootID), bob.Token, gin.H{"content": "写得很好"}, http.StatusOK))
+	s.do("DELETE", fmt.Sprintf("/api/comments/%v", rootID), carol.Token, nil, http.StatusForbidden)
+	s.do("DELETE", fmt.Sprintf("/api/comments/%v", rootID), alice.Token, nil, http.StatusOK)
+	s.do("PUT", fmt.Sprintf("/api/comments/%v", rootID), bob.Token, gin.H{"content": "已删除"}, http.StatusNotFound)
+	s.golden("comments_tombstone", s.do("GET", fmt.Sprintf("/api/posts/%v/comments?view=tree", postID), "", nil, http.StatusOK))
+	s.do("DELETE", "/api/comments/999", bob.Token, nil, http.StatusNotFound)
+
+	// 按顶级评论分页，每页带上顶级评论下的全部回复
+	s.createComment(carol, postID, "第二条", nil)
+	s.createComment(carol, postID, "第三条", nil)
+	path := fmt.Sprintf("/api/posts/%v/comments", postID)
+	first := s.do("GET", path+"?view=tree&limit=2&total=true", "", nil, http.StatusOK)
+	expectLen(t, first["comments"], 2)
+	if first["total"] != float64(3) || first["next_cursor"] == nil {
+		t.Fatalf("first page of comments: total %v, next_cursor %v", first["total"], first["next_cursor"])
+	}
+	rest := s.do("GET", path+"?view=tree&limit=2&cursor="+first["next_cursor"].(string), "", nil, http.StatusOK)
+	s.golden("comments_next_page", rest)
+	oldest := s.do("GET", path+"?order=asc&limit=1", "", nil, http.StatusOK)
+	expectLen(t, oldest["comments"], 3)
+	if oldest["comments"].([]interface{})[0].(map[string]interface{})["id"] != rootID {
+		t.Errorf("oldest thread should start with comment %v: %v", rootID, oldest["comments"])
+	}
+	s.do("GET", path+"?sort=title", "", nil, http.StatusBadRequest)
+	s.do("GET", path+"?limit=0", "", nil, http.StatusBadRequest)
+}
+
+func e2eTaxonomy(t *testing.T, s *testServer) {
+	editor := s.registerWithRole("erin", "editor")
+	user := s.register("bob")
+
+	tech := s.createCategory(editor, "技术", "tech", nil)
+	golang := s.createCategory(editor, "Go", "go", tech["id"])
+	s.do("POST", "/api/categories", user.Token, gin.H{"name": "越权"}, http.StatusForbidden)
+	s.golden("taxonomy_category_duplicate", s.do("POST", "/api/categories", editor.Token, gin.H{"name": "Tech", "slug": "tech"}, http.StatusConflict))
+	s.do("PUT", fmt.Sprintf("/api/categories/%v", tech["id"]), editor.Token, gin.H{"name": "技术", "parent_id": golang["id"]}, http.StatusBadRequest)
+	s.do("PUT", fmt.Sprintf("/api/categories/%v", golang["id"]), editor.Token, gin.H{"name": "Golang", "slug": "golang", "parent_id": tech["id"]}, http.StatusOK)
+	s.golden("taxonomy_categories", s.do("GET", "/api/categories", "", nil, http.StatusOK))
+
+	s.createPost(user, gin.H{"title": "Go 并发", "status": "published", "category_id": golang["id"], "tags": []string{"Go", "并发"}})
+	expectLen(t, s.do("GET", "/api/posts?category=tech", "", nil, http.StatusOK)["posts"], 1)
+
+	tag := s.do("POST", "/api/tags", editor.Token, gin.H{"name": "Rust"}, http.StatusCreated)["tag"].(map[string]interface{})
+	s.do("POST", "/api/tags", editor.Token, gin.H{"name": "rust"}, http.StatusConflict)
+	s.do("POST", "/api/tags", user.Token, gin.H{"name": "越权"}, http.StatusForbidden)
+	s.do("PUT", fmt.Sprintf("/api/tags/%v", tag["id"]), editor.Token, gin.H{"name": "Rust 语言", "slug": "rust-lang"}, http.StatusOK)
+	s.golden("taxonomy_tags", s.do("GET", "/api/tags", "", nil, http.StatusOK))
+	s.do("DELETE", fmt.Sprintf("/api/tags/%v", tag["id"]), editor.Token, nil, http.StatusOK)
+	s.do("DELETE", fmt.Sprintf("/api/tags/%v", tag["id"]), editor.Token, nil, http.StatusNotFound)
+
+	// 删除分类时子分类和文章移到父分类
+	s.do("DELETE", fmt.Sprintf("/api/categories/%v", golang["id"]), editor.Token, nil, http.StatusOK)
+	expectLen(t, s.do("GET", "/api/posts?category=tech", "", nil, http.StatusOK)["posts"], 1)
+	s.do("DELETE", fmt.Sprintf("/api/categories/%v", golang["id"]), editor.Token, nil, http.StatusNotFound)
+}
+
+func e2eSearch(t *testing.T, s *testServer) {
+	alice := s.register("alice")
+	post := s.createPost(alice, gin.H{"title": "以太坊智能合约", "content": "Solidity 是编写智能合约的语言", "status": "published"})
+	s.createPost(alice, gin.H{"title": "智能合约草稿", "content": "草稿不会被搜索到"})
+	s.createComment(alice, post["id"], "智能合约的安全问题", nil)
+
+	s.golden("search_all", s.do("GET", "/api/search?q="+url.QueryEscape("智能合约"), "", nil, http.StatusOK))
+	expectLen(t, s.do("GET", "/api/search?type=comments&q="+url.QueryEscape("安全"), "", nil, http.StatusOK)["comments"], 1)
+	s.golden("search_missing_query", s.do("GET", "/api/search", "", nil, http.StatusBadRequest))
+	s.golden("search_invalid_limit", s.do("GET", "/api/search?q=go&limit=-1", "", nil, http.StatusBadRequest))
+	s.do("GET", "/api/search?q=go&limit=1000", "", nil, http.StatusBadRequest)
+	s.do("GET", "/api/search?q=go&page=abc", "", nil, http.StatusBadRequest)
+}
+
+func e2eAdmin(t *testing.T, s *testServer) {
+	admin := s.register("root")
+	if err := bootstrapAdmin("root"); err != nil {
+		t.Fatal(err)
+	}
+	bob := s.register("bob")
+	carol := s.register("carol")
+
+	s.golden("admin_forbidden", s.do("GET", "/api/admin/users", bob.Token, nil, http.StatusForbidden))
+	s.golden("admin_users", s.do("GET", "/api/admin/users", admin.Token, nil, http.StatusOK))
+	s.do("PUT", fmt.Sprintf("/api/admin/users/%v/role", admin.ID), admin.Token, gin.H{"role": "user"}, http.StatusBadRequest)
+	s.do("PUT", fmt.Sprintf("/api/admin/users/%v/role", bob.ID), admin.Token, gin.H{"role": "owner"}, http.StatusBadRequest)
+	s.do("PUT", "/api/admin/users/999/role", admin.Token, gin.H{"role": "editor"}, http.StatusNotFound)
+	s.golden("admin_update_role", s.do("PUT", fmt.Sprintf("/api/admin/users/%v/role", bob.ID), admin.Token, gin.H{"role": "editor"}, http.StatusOK))
+
+	// 链接过多的评论进入审核队列，编辑可以审核
+	post := s.createPost(carol, gin.H{"title": "审核", "status": "published"})
+	links := "https://a.example https://b.example https://c.example"
+	pending := s.createComment(carol, post["id"], "推广 "+links, nil)
+	other := s.createComment(carol, post["id"], "再推广 "+links, nil)
+	if pending["status"] != "pending" || other["status"] != "pending" {
+		t.Fatalf("comments with links should be pending: %v %v", pending["status"], other["status"])
+	}
+	expectLen(t, s.do("GET", fmt.Sprintf("/api/posts/%v/comments", post["id"]), "", nil, http.StatusOK)["comments"], 0)
+
+	s.golden("admin_moderation_queue", s.do("GET", "/api/admin/comments", bob.Token, nil, http.StatusOK))
+	s.do("GET", "/api/admin/comments?status=approved", bob.Token, nil, http.StatusBadRequest)
+	s.do("GET", "/api/admin/comments", carol.Token, nil, http.StatusForbidden)
+
+	s.golden("admin_approve", s.do("POST", "/api/admin/comments/approve", bob.Token, gin.H{"ids": []interface{}{pending["id"]}}, http.StatusOK))
+	s.golden("admin_reject", s.do("POST", "/api/admin/comments/reject", bob.Token, gin.H{"ids": []interface{}{pending["id"], other["id"]}}, http.StatusOK))
+	expectLen(t, s.do("GET", "/api/admin/comments?status=spam", bob.Token, nil, http.StatusOK)["comments"], 1)
+	s.do("POST", "/api/admin/comments/approve", bob.Token, gin.H{"ids": []interface{}{}}, http.StatusBadRequest)
+
+	s.do("DELETE", fmt.Sprintf("/api/admin/comments/%v", pending["id"]), carol.Token, nil, http.StatusForbidden)
+	s.do("DELETE", fmt.Sprintf("/api/admin/comments/%v", pending["id"]), bob.Token, nil, http.StatusOK)
+	s.do("DELETE", fmt.Sprintf("/api/admin/comments/%v", pending["id"]), bob.Token, nil, http.StatusNotFound)
+	expectLen(t, s.do("GET", fmt.Sprintf("/api/posts/%v/comments", post["id"]), "", nil, http.StatusOK)["comments"], 0)
+}
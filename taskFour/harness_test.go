@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"taskFour/config"
+	"taskFour/models"
+	"taskFour/ratelimit"
+	"taskFour/utils"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/gin-gonic/gin"
+)
+
+// 端到端测试工具：每个测试使用独立的 SQLite 内存库和完整的 setupRouter，
+// 通过 HTTP 注册、登录和创建数据，响应与 testdata/golden 中的期望结果比较。
+// 修改接口后用 go test -run TestE2E -update 重新生成期望结果，并检查 diff
+
+var updateGolden = flag.Bool("update", false, "用实际响应覆盖 testdata/golden 中的期望结果")
+
+// testServer 一个测试独占的服务实例
+type testServer struct {
+	*apiClient
+	t *testing.T
+}
+
+// testUser 已登录的用户
+type testUser struct {
+	ID           float64
+	Username     string
+	Token        string
+	RefreshToken string
+}
+
+// newTestServer 创建使用全新内存库的服务，关闭限流以免测试之间互相影响。routes 不为 nil 时记录访问过的路由
+func newTestServer(t *testing.T, routes *routeRecorder) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+
+	auth, api, write := config.RateLimitAuth, config.RateLimitAPI, config.RateLimitWrite
+	config.RateLimitAuth, config.RateLimitAPI, config.RateLimitWrite = ratelimit.Limit{}, ratelimit.Limit{}, ratelimit.Limit{}
+	ratelimit.SetStore(ratelimit.NewMemoryStore())
+	t.Cleanup(func() {
+		config.RateLimitAuth, config.RateLimitAPI, config.RateLimitWrite = auth, api, write
+	})
+
+	router := setupRouter(migrator)
+	if routes != nil {
+		routes.add(router.Routes())
+	}
+	return &testServer{apiClient: &apiClient{t: t, router: router, routes: routes}, t: t}
+}
+
+// register 注册并登录用户，密码统一为 password123，邮箱为 用户名@example.com
+func (s *testServer) register(username string) *testUser {
+	s.t.Helper()
+	s.do("POST", "/api/auth/register", "", gin.H{"username": username, "password": "password123", "email": username + "@example.com"}, http.StatusCreated)
+	login := s.do("POST", "/api/auth/login", "", gin.H{"username": username, "password": "password123"}, http.StatusOK)
+	return &testUser{
+		ID:           login["user"].(map[string]interface{})["id"].(float64),
+		Username:     username,
+		Token:        login["token"].(string),
+		RefreshToken: login["refresh_token"].(string),
+	}
+}
+
+// registerWithRole 注册用户并直接在数据库中设置角色，角色每次请求时读取，已签发的令牌立即生效
+func (s *testServer) registerWithRole(username, role string) *testUser {
+	s.t.Helper()
+	user := s.register(username)
+	if err := db.Model(&models.User{}).Where("id = ?", uint(user.ID)).Update("role", role).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	return user
+}
+
+// createPost 创建文章，未指定的标题、内容使用默认值，返回响应中的文章
+func (s *testServer) createPost(author *testUser, fields gin.H) map[string]interface{} {
+	s.t.Helper()
+	body := gin.H{"title": "测试文章", "content": "测试内容"}
+	for k, v := range fields {
+		body[k] = v
+	}
+	return s.do("POST", "/api/posts", author.Token, body, http.StatusCreated)["post"].(map[string]interface{})
+}
+
+// createComment 发表评论，parentID 为 nil 时是顶级评论
+func (s *testServer) createComment(author *testUser, postID interface{}, content string, parentID interface{}) map[string]interface{} {
+	s.t.Helper()
+	body := gin.H{"content": content, "post_id": postID}
+	if parentID != nil {
+		body["parent_id"] = parentID
+	}
+	return s.do("POST", "/api/comments", author.Token, body, http.StatusCreated)["comment"].(map[string]interface{})
+}
+
+// createCategory 创建分类，parentID 为 nil 时是顶级分类
+func (s *testServer) createCategory(manager *testUser, name, slug string, parentID interface{}) map[string]interface{} {
+	s.t.Helper()
+	body := gin.H{"name": name, "slug": slug}
+	if parentID != nil {
+		body["parent_id"] = parentID
+	}
+	return s.do("POST", "/api/categories", manager.Token, body, http.StatusCreated)["category"].(map[string]interface{})
+}
+
+// signIn 获取随机数并用钱包签名 EIP-4361 消息，返回 /auth/siwe 的请求体
+func (s *testServer) signIn(w *testWallet) gin.H {
+	s.t.Helper()
+	nonce := s.do("GET", "/api/auth/nonce", "", nil, http.StatusOK)["nonce"].(string)
+	return w.sign(s.t, nonce)
+}
+
+// golden 比较 JSON 响应与 testdata/golden/<name>.json，时间、令牌、随机数等每次不同的值先替换为占位符
+func (s *testServer) golden(name string, response interface{}) {
+	s.t.Helper()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(normalizeGolden("", response)); err != nil {
+		s.t.Fatal(err)
+	}
+	got := buf.Bytes()
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			s.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			s.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		s.t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		s.t.Errorf("response differs from %s (run with -update to accept):\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
+
+var timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+
+// volatileKeys 每次运行都不同的字段
+var volatileKeys = map[string]bool{
+	"token":         true,
+	"refresh_token": true,
+	"nonce":         true,
+	"request_id":    true,
+	"latency":       true,
+	"next_cursor":   true,
+	"prev_cursor":   true,
+}
+
+func normalizeGolden(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = normalizeGolden(k, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeGolden(key, item)
+		}
+		return out
+	case string:
+		if volatileKeys[key] && v != "" {
+			return "<" + key + ">"
+		}
+		if timestampPattern.MatchString(v) {
+			return "<timestamp>"
+		}
+	}
+	return value
+}
+
+// routeRecorder 记录测试访问过的路由，用于检查 setupRouter 中的每个路由都被覆盖
+type routeRecorder struct {
+	routes map[string]bool // "METHOD /path/:param" -> 是否访问过
+}
+
+func newRouteRecorder() *routeRecorder {
+	return &routeRecorder{routes: make(map[string]bool)}
+}
+
+func (r *routeRecorder) add(routes gin.RoutesInfo) {
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if _, ok := r.routes[key]; !ok {
+			r.routes[key] = false
+		}
+	}
+}
+
+func (r *routeRecorder) record(method, path string) {
+	for key := range r.routes {
+		routeMethod, pattern, _ := strings.Cut(key, " ")
+		if routeMethod == method && matchRoute(pattern, path) {
+			r.routes[key] = true
+		}
+	}
+}
+
+// missing 没有被访问过的路由
+func (r *routeRecorder) missing() []string {
+	var missing []string
+	for key, hit := range r.routes {
+		if !hit {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// matchRoute 按 gin 的规则匹配 :param 和 *wildcard
+func matchRoute(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return len(pathParts) >= i
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
+
+// testWallet 用固定私钥签名的以太坊钱包，地址在每次运行中相同
+type testWallet struct {
+	key     *secp256k1.PrivateKey
+	Address string
+}
+
+func newTestWallet(t *testing.T, seed byte) *testWallet {
+	t.Helper()
+	key := secp256k1.PrivKeyFromBytes(bytes.Repeat([]byte{seed}, 32))
+	pub := key.PubKey().SerializeUncompressed()[1:]
+	address, err := utils.ChecksumAddress("0x" + hex.EncodeToString(utils.Keccak256(pub)[12:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testWallet{key: key, Address: address}
+}
+
+// sign 生成包含 nonce 的 EIP-4361 消息和 personal_sign 签名（r || s || v）
+func (w *testWallet) sign(t *testing.T, nonce string) gin.H {
+	t.Helper()
+	message := fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n%s\n\nSign in to the blog.\n\nURI: http://%s\nVersion: 1\nChain ID: 1\nNonce: %s\nIssued At: %s",
+		config.SIWEDomain, w.Address, config.SIWEDomain, nonce, time.Now().UTC().Format(time.RFC3339))
+
+	compact := ecdsa.SignCompact(w.key, utils.PersonalSignHash([]byte(message)), false)
+	signature := append(append([]byte{}, compact[1:]...), compact[0]-27)
+	return gin.H{"message": message, "signature": "0x" + hex.EncodeToString(signature)}
+}
@@ -0,0 +1,659 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/config"
+	"taskFour/metrics"
+	"taskFour/migrations"
+	"taskFour/models"
+	"taskFour/ratelimit"
+	"taskFour/tracing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm/logger"
+)
+
+// 集成测试矩阵：默认只在 SQLite 内存库上运行；设置 TEST_MYSQL_DSN / TEST_POSTGRES_DSN 后
+// 对 MySQL 和 PostgreSQL 运行同一套 API 流程（docker-compose.test.yml 提供本地容器）。
+// 外部数据库会被回滚到空库后重新迁移，请使用专门的测试库。
+
+type testBackend struct {
+	driver string
+	dsn    string
+}
+
+func testBackends() []testBackend {
+	backends := []testBackend{{driver: "sqlite", dsn: ":memory:"}}
+	if dsn := os.Getenv("TEST_MYSQL_DSN"); dsn != "" {
+		backends = append(backends, testBackend{driver: "mysql", dsn: dsn})
+	}
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		backends = append(backends, testBackend{driver: "postgres", dsn: dsn})
+	}
+	return backends
+}
+
+func TestAPIAcrossBackends(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, backend := range testBackends() {
+		t.Run(backend.driver, func(t *testing.T) {
+			migrator := setupTestDatabase(t, backend)
+			runAPIScenario(t, setupRouter(migrator))
+
+			pending, err := migrator.Pending()
+			if err != nil || len(pending) != 0 {
+				t.Fatalf("pending migrations after test: %v %v", pending, err)
+			}
+		})
+	}
+}
+
+func TestProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	api := &apiClient{t: t, router: setupRouter(migrator)}
+
+	api.do("GET", "/livez", "", nil, http.StatusOK)
+	api.do("GET", "/health", "", nil, http.StatusOK)
+	ready := api.do("GET", "/readyz", "", nil, http.StatusOK)
+	if ready["status"] != "ok" {
+		t.Fatalf("readyz = %v", ready)
+	}
+
+	// 有未执行的迁移时不就绪
+	if err := migrator.Down(1); err != nil {
+		t.Fatal(err)
+	}
+	ready = api.do("GET", "/readyz", "", nil, http.StatusServiceUnavailable)
+	checks := ready["checks"].(map[string]interface{})
+	if status := checks["migrations"].(map[string]interface{})["status"]; status != "pending" {
+		t.Fatalf("migrations check = %v", checks["migrations"])
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 限流使用 Redis 时检查 Redis，Redis 不可用时不就绪
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer client.Close()
+	ratelimit.SetStore(ratelimit.NewRedisStore(client, "ratelimit:"))
+	defer ratelimit.SetStore(ratelimit.NewMemoryStore())
+	ready = api.do("GET", "/readyz", "", nil, http.StatusOK)
+	if status := ready["checks"].(map[string]interface{})["ratelimit"].(map[string]interface{})["status"]; status != "ok" {
+		t.Fatalf("ratelimit check = %v", ready["checks"])
+	}
+	redisServer.Close()
+	ready = api.do("GET", "/readyz", "", nil, http.StatusServiceUnavailable)
+	if status := ready["checks"].(map[string]interface{})["ratelimit"].(map[string]interface{})["status"]; status != "failed" {
+		t.Fatalf("ratelimit check = %v", ready["checks"])
+	}
+	ratelimit.SetStore(ratelimit.NewMemoryStore())
+
+	// 数据库关闭后不就绪，存活检查不受影响
+	if err := config.CloseDatabase(); err != nil {
+		t.Fatal(err)
+	}
+	ready = api.do("GET", "/readyz", "", nil, http.StatusServiceUnavailable)
+	checks = ready["checks"].(map[string]interface{})
+	if status := checks["database"].(map[string]interface{})["status"]; status != "failed" {
+		t.Fatalf("database check = %v", checks["database"])
+	}
+	api.do("GET", "/livez", "", nil, http.StatusOK)
+}
+
+func TestMigrateOnStartup(t *testing.T) {
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	autoMigrate, allowPending := config.DBAutoMigrate, config.DBAllowPendingMigrations
+	t.Cleanup(func() { config.DBAutoMigrate, config.DBAllowPendingMigrations = autoMigrate, allowPending })
+	if err := migrator.Down(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭自动迁移时有未执行的迁移拒绝启动，显式允许后只提示
+	config.DBAutoMigrate, config.DBAllowPendingMigrations = false, false
+	if err := migrateOnStartup(migrator); err == nil || !strings.Contains(err.Error(), "1 pending migration") {
+		t.Fatalf("migrateOnStartup with pending migrations = %v", err)
+	}
+	config.DBAllowPendingMigrations = true
+	if err := migrateOnStartup(migrator); err != nil {
+		t.Fatalf("migrateOnStartup with allow_pending_migrations = %v", err)
+	}
+
+	config.DBAutoMigrate = true
+	if err := migrateOnStartup(migrator); err != nil {
+		t.Fatal(err)
+	}
+	config.DBAutoMigrate, config.DBAllowPendingMigrations = false, false
+	if err := migrateOnStartup(migrator); err != nil {
+		t.Fatalf("migrateOnStartup after migrating = %v", err)
+	}
+}
+
+// 本地时区不是 UTC 时，定时文章仍然到点才可见、才会被调度器发布
+func TestScheduledPostsOutsideUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("CST", 8*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	api := &apiClient{t: t, router: setupRouter(migrator)}
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated)
+	token := api.login("alice")
+
+	api.do("POST", "/api/posts", token, gin.H{"title": "现在发布", "content": "timezone", "status": "published"}, http.StatusCreated)
+	api.do("POST", "/api/posts", token, gin.H{"title": "六小时后", "content": "timezone", "status": "scheduled",
+		"published_at": time.Now().Add(6 * time.Hour).Format(time.RFC3339)}, http.StatusCreated)
+
+	expectLen(t, api.do("GET", "/api/posts", "", nil, http.StatusOK)["posts"], 1)
+	expectLen(t, api.do("GET", "/api/search?type=posts&q=timezone", "", nil, http.StatusOK)["posts"], 1)
+	if n, err := models.PublishDuePosts(db, time.Now()); err != nil || n != 0 {
+		t.Fatalf("PublishDuePosts = %d, %v, want 0", n, err)
+	}
+	if n, err := models.PublishDuePosts(db, time.Now().Add(7*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("PublishDuePosts after 7h = %d, %v, want 1", n, err)
+	}
+}
+
+// 反垃圾规则使用 config.Apply 之后的配置，而不是包初始化时的默认值
+func TestSpamConfigApplied(t *testing.T) {
+	t.Setenv("SPAM_BLOCKLIST", "casino")
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocklist := config.SpamBlocklist
+	t.Cleanup(func() { config.SpamBlocklist = blocklist })
+	cfg.Apply()
+
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	api := &apiClient{t: t, router: setupRouter(migrator)}
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated)
+	token := api.login("alice")
+	post := api.do("POST", "/api/posts", token, gin.H{"title": "标题", "content": "内容", "status": "published"}, http.StatusCreated)["post"].(map[string]interface{})
+
+	comment := api.do("POST", "/api/comments", token, gin.H{"content": "best CASINO deals", "post_id": post["id"]}, http.StatusCreated)["comment"].(map[string]interface{})
+	if comment["status"] != models.CommentStatusSpam {
+		t.Fatalf("comment status = %v, want spam", comment["status"])
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	router := setupRouter(migrator)
+
+	for header, keep := range map[string]bool{"gateway-7f3a": true, "": false, "bad id\n": false} {
+		req := httptest.NewRequest("GET", "/livez", nil)
+		if header != "" {
+			req.Header.Set("X-Request-ID", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		got := w.Header().Get("X-Request-ID")
+		if keep && got != header || !keep && (got == "" || got == header) {
+			t.Errorf("X-Request-ID %q: response header %q", header, got)
+		}
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	api := &apiClient{t: t, router: setupRouter(migrator)}
+
+	api.do("POST", "/api/auth/login", "", gin.H{"username": "nobody", "password": "password123"}, http.StatusUnauthorized)
+	api.do("GET", "/api/posts/12345", "", nil, http.StatusNotFound)
+	api.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/no/such/route", nil))
+
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/metrics status %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`blog_http_requests_total{method="GET",route="/api/posts/:id",status="404"}`,
+		`blog_http_requests_total{method="GET",route="unmatched",status="404"}`,
+		`blog_http_request_duration_seconds_bucket{method="POST",route="/api/auth/login",status="401"`,
+		`blog_login_failures_total{method="password"}`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics missing %s", want)
+		}
+	}
+}
+
+// exportedSpan stdouttrace 导出的 span 中用到的字段
+func TestErrorEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	router := setupRouter(migrator)
+	router.GET("/test/panic", func(c *gin.Context) { panic("boom") })
+	router.GET("/test/written", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		c.Error(errors.New("after response"))
+	})
+
+	cases := []struct {
+		method, path, body, lang string
+		status                   int
+		code                     apperr.Code
+		message                  string
+	}{
+		{"POST", "/api/auth/register", `{"username":"al","password":"password123"}`, "", http.StatusBadRequest, apperr.CodeValidationFailed, "Validation failed"},
+		{"POST", "/api/auth/login", `{"username":`, "", http.StatusBadRequest, apperr.CodeInvalidRequest, "Malformed request body"},
+		{"POST", "/api/auth/login", `{"username":"nobody","password":"x"}`, "zh-CN", http.StatusUnauthorized, apperr.CodeInvalidCredentials, "用户名或密码错误"},
+		{"GET", "/api/posts/999", "", "zh-CN,zh;q=0.9", http.StatusNotFound, apperr.CodeNotFound, "文章不存在"},
+		{"GET", "/api/posts/abc", "", "en", http.StatusBadRequest, apperr.CodeInvalidRequest, "Invalid post ID"},
+		{"GET", "/api/users/me/posts", "", "", http.StatusUnauthorized, apperr.CodeUnauthorized, "Authorization header is required"},
+		{"POST", "/api/auth/logout", "", "", http.StatusUnauthorized, apperr.CodeInvalidToken, "Invalid token"},
+		{"GET", "/test/panic", "", "", http.StatusInternalServerError, apperr.CodeInternal, "Internal server error"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", tc.lang)
+		if tc.path == "/api/auth/logout" {
+			req.Header.Set("Authorization", "Bearer not-a-jwt")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response apperr.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s %s: invalid JSON %s", tc.method, tc.path, w.Body.String())
+		}
+		got := response.Error
+		if w.Code != tc.status || got.Code != tc.code || got.Message != tc.message {
+			t.Errorf("%s %s: %d %s %q, want %d %s %q", tc.method, tc.path, w.Code, got.Code, got.Message, tc.status, tc.code, tc.message)
+		}
+		if got.RequestID == "" || got.RequestID != w.Header().Get("X-Request-ID") {
+			t.Errorf("%s %s: request_id %q does not match header", tc.method, tc.path, got.RequestID)
+		}
+	}
+
+	// 校验失败时列出每个字段
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/auth/register", strings.NewReader(`{"username":"al","email":"bad"}`)))
+	var response apperr.Response
+	json.Unmarshal(w.Body.Bytes(), &response)
+	fields := map[string]string{}
+	for _, detail := range response.Error.Details {
+		fields[detail.Field] = detail.Rule
+	}
+	if len(fields) != 3 || fields["username"] != "min" || fields["password"] != "required" || fields["email"] != "email" {
+		t.Errorf("unexpected details %+v", response.Error.Details)
+	}
+
+	// 已经写出响应时不再输出第二个响应
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test/written", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"ok":true}` {
+		t.Errorf("response written twice: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	auth, write := config.RateLimitAuth, config.RateLimitWrite
+	config.RateLimitAuth = ratelimit.Limit{Requests: 4, Period: time.Minute}
+	config.RateLimitWrite = ratelimit.Limit{Requests: 1, Period: time.Minute}
+	ratelimit.SetStore(ratelimit.NewMemoryStore())
+	t.Cleanup(func() {
+		config.RateLimitAuth, config.RateLimitWrite = auth, write
+		ratelimit.SetStore(ratelimit.NewMemoryStore())
+	})
+	router := setupRouter(migrator)
+	api := &apiClient{t: t, router: router}
+
+	// 认证接口按 IP 限流，未配置可信代理时伪造 X-Forwarded-For 无效
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated)
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "bob", "password": "password123", "email": "bob@example.com"}, http.StatusCreated)
+	alice := api.login("alice")
+	bob := api.login("bob")
+
+	req := httptest.NewRequest("GET", "/api/auth/nonce", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("fifth auth request: status %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("RateLimit-Policy") != "4;w=60" {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+
+	// 认证接口始终按 IP 计数，携带其他账号的令牌也不能绕过
+	w = api.request("POST", "/api/auth/login", bob, gin.H{"username": "alice", "password": "password123"}, nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("login with a bearer token after the IP limit: status %d, want 429", w.Code)
+	}
+
+	// 其他 IP 不受影响
+	req = httptest.NewRequest("GET", "/api/auth/nonce", nil)
+	req.RemoteAddr = "198.51.100.7:1234"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "3" {
+		t.Fatalf("other IP: status %d, remaining %q", w.Code, w.Header().Get("RateLimit-Remaining"))
+	}
+
+	// 写操作按用户限流，同一 IP 的其他用户不受影响；响应头取剩余次数最少的限流器
+	newPost := gin.H{"title": "限流", "content": "内容"}
+	api.do("POST", "/api/posts", alice, newPost, http.StatusCreated)
+	api.do("POST", "/api/posts", alice, newPost, http.StatusTooManyRequests)
+	api.do("POST", "/api/posts", bob, newPost, http.StatusCreated)
+
+	req = httptest.NewRequest("GET", "/api/posts", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Policy") != fmt.Sprintf("%d;w=60", config.RateLimitAPI.Requests) {
+		t.Errorf("api limit headers: %v", w.Header())
+	}
+	if got := testutil.ToFloat64(metrics.RateLimited.WithLabelValues("write")); got < 1 {
+		t.Errorf("rate_limited_total{group=write} = %v", got)
+	}
+}
+
+type exportedSpan struct {
+	Name        string
+	SpanContext struct{ TraceID, SpanID string }
+	Parent      struct{ TraceID, SpanID string }
+}
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	migrator := setupTestDatabase(t, testBackend{driver: "sqlite", dsn: ":memory:"})
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{ServiceName: "blog-test", Exporter: tracing.ExporterFile, File: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	api := &apiClient{t: t, router: setupRouter(migrator)}
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated)
+	token := api.login("alice")
+	post := api.do("POST", "/api/posts", token, gin.H{"title": "Traced", "content": "content", "status": "published"}, http.StatusCreated)
+	postID := int(post["post"].(map[string]interface{})["id"].(float64))
+
+	// 带上游的 traceparent 请求，span 应当延续同一条链路
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/posts/%d", postID), nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET post: %d %s", w.Code, w.Body.String())
+	}
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var server *exportedSpan
+	byID := make(map[string]exportedSpan)
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var span exportedSpan
+		if err := decoder.Decode(&span); err != nil {
+			t.Fatal(err)
+		}
+		if span.SpanContext.TraceID != traceID {
+			continue
+		}
+		byID[span.SpanContext.SpanID] = span
+		if span.Name == "GET /api/posts/:id" {
+			server = &span
+		}
+	}
+	if server == nil || server.Parent.SpanID != parentID {
+		t.Fatalf("server span not continued from traceparent: %+v", server)
+	}
+
+	// 文章查询是请求 span 的子 span，Preload 的查询是文章查询的子 span
+	var postsQuery, usersQuery *exportedSpan
+	for _, span := range byID {
+		span := span
+		switch span.Name {
+		case "gorm.query posts":
+			if span.Parent.SpanID == server.SpanContext.SpanID {
+				postsQuery = &span
+			}
+		case "gorm.query users":
+			usersQuery = &span
+		}
+	}
+	if postsQuery == nil {
+		t.Fatalf("no posts query under the server span: %+v", byID)
+	}
+	if usersQuery == nil || byID[usersQuery.Parent.SpanID].Name != "gorm.query posts" {
+		t.Fatalf("preload query not nested under posts query: %+v", usersQuery)
+	}
+}
+
+// setupTestDatabase 连接数据库，回滚全部迁移后重新执行，并替换全局连接
+func setupTestDatabase(t *testing.T, backend testBackend) *migrations.Migrator {
+	t.Helper()
+
+	database, err := config.OpenDatabase(backend.driver, backend.dsn, logger.Discard)
+	if err != nil {
+		t.Fatalf("open %s: %v", backend.driver, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := migrations.New(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.To(0); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	config.DB = database
+	db = database
+	if err := models.SetupSearch(database); err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func runAPIScenario(t *testing.T, router http.Handler) {
+	api := &apiClient{t: t, router: router}
+
+	// 账号：alice 为管理员，bob 为普通用户
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "alice", "password": "password123", "email": "alice@example.com"}, http.StatusCreated)
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "bob", "password": "password123", "email": "bob@example.com"}, http.StatusCreated)
+	api.do("POST", "/api/auth/register", "", gin.H{"username": "bob", "password": "password123", "email": "bob2@example.com"}, http.StatusBadRequest)
+	if err := bootstrapAdmin("alice"); err != nil {
+		t.Fatal(err)
+	}
+	alice := api.login("alice")
+	bob := api.login("bob")
+	api.do("GET", "/api/admin/users", bob, nil, http.StatusForbidden)
+	api.do("GET", "/api/admin/users", alice, nil, http.StatusOK)
+
+	// 分类和标签
+	tech := api.do("POST", "/api/categories", alice, gin.H{"name": "技术"}, http.StatusCreated)["category"].(map[string]interface{})
+	chain := api.do("POST", "/api/categories", alice, gin.H{"name": "区块链", "slug": "blockchain", "parent_id": tech["id"]}, http.StatusCreated)["category"].(map[string]interface{})
+	api.do("PUT", fmt.Sprintf("/api/categories/%v", tech["id"]), alice, gin.H{"name": "技术", "parent_id": chain["id"]}, http.StatusBadRequest)
+
+	// 文章：两篇公开、一篇草稿，重复的标签复用同一条记录
+	post := api.do("POST", "/api/posts", alice, gin.H{
+		"title":       "以太坊智能合约入门",
+		"content":     "Solidity 是编写 Smart Contract 的主流语言。",
+		"status":      "published",
+		"tags":        []string{"区块链", "以太坊"},
+		"category_id": chain["id"],
+	}, http.StatusCreated)["post"].(map[string]interface{})
+	postID := post["id"]
+	api.do("POST", "/api/posts", bob, gin.H{"title": "Go 并发", "content": "goroutine 与 channel", "status": "published", "tags": []string{"以太坊", "Go"}}, http.StatusCreated)
+	api.do("POST", "/api/posts", bob, gin.H{"title": "草稿", "content": "还没写完", "tags": []string{"以太坊"}}, http.StatusCreated)
+
+	expectLen(t, api.do("GET", "/api/posts", "", nil, http.StatusOK)["posts"], 2)
+	expectLen(t, api.do("GET", "/api/posts?tag="+url.QueryEscape("以太坊"), "", nil, http.StatusOK)["posts"], 2)
+	expectLen(t, api.do("GET", "/api/posts?category="+url.QueryEscape(tech["slug"].(string)), "", nil, http.StatusOK)["posts"], 1)
+	expectLen(t, api.do("GET", "/api/users/me/posts", bob, nil, http.StatusOK)["posts"], 2)
+
+	tags := api.do("GET", "/api/tags", "", nil, http.StatusOK)["tags"].([]interface{})
+	if top := tags[0].(map[string]interface{}); top["name"] != "以太坊" || top["post_count"] != float64(2) {
+		t.Fatalf("unexpected top tag: %v", top)
+	}
+
+	// 修改文章会生成历史版本
+	api.do("PUT", fmt.Sprintf("/api/posts/%v", postID), bob, gin.H{"title": "改标题"}, http.StatusForbidden)
+	api.do("PUT", fmt.Sprintf("/api/posts/%v", postID), alice, gin.H{"content": "Solidity 是编写智能合约的主流语言。", "tags": []string{"以太坊"}, "version": 1}, http.StatusOK)
+	expectLen(t, api.do("GET", fmt.Sprintf("/api/posts/%v/revisions", postID), "", nil, http.StatusOK)["revisions"], 2)
+
+	// 评论、回复、修改和墓碑
+	root := api.do("POST", "/api/comments", bob, gin.H{"content": "智能合约写得很清楚", "post_id": postID}, http.StatusCreated)["comment"].(map[string]interface{})
+	reply := api.do("POST", "/api/comments", alice, gin.H{"content": "谢谢", "post_id": postID, "parent_id": root["id"]}, http.StatusCreated)["comment"].(map[string]interface{})
+	api.do("PUT", fmt.Sprintf("/api/comments/%v", reply["id"]), bob, gin.H{"content": "改别人的评论"}, http.StatusForbidden)
+	api.do("PUT", fmt.Sprintf("/api/comments/%v", root["id"]), bob, gin.H{"content": "智能合约写得非常清楚"}, http.StatusOK)
+
+	tree := api.do("GET", fmt.Sprintf("/api/posts/%v/comments?view=tree", postID), "", nil, http.StatusOK)["comments"].([]interface{})
+	expectLen(t, tree, 1)
+	expectLen(t, tree[0].(map[string]interface{})["replies"], 1)
+
+	// 搜索：SQLite 使用 FTS5，其余数据库使用 LIKE，结果格式一致
+	search := api.do("GET", "/api/search?q="+url.QueryEscape("智能合约"), "", nil, http.StatusOK)
+	expectLen(t, search["posts"], 1)
+	expectLen(t, search["comments"], 1)
+	if snippet := search["comments"].([]interface{})[0].(map[string]interface{})["snippet"].(string); !strings.Contains(snippet, "<mark>") {
+		t.Fatalf("snippet without highlight: %q", snippet)
+	}
+	expectLen(t, api.do("GET", "/api/search?type=posts&q="+url.QueryEscape("smart"), "", nil, http.StatusOK)["posts"], 0)
+	expectLen(t, api.do("GET", "/api/search?type=posts&q=goroutine", "", nil, http.StatusOK)["posts"], 1)
+
+	api.do("DELETE", fmt.Sprintf("/api/comments/%v", root["id"]), bob, nil, http.StatusOK)
+	flat := api.do("GET", fmt.Sprintf("/api/posts/%v/comments", postID), "", nil, http.StatusOK)["comments"].([]interface{})
+	expectLen(t, flat, 2)
+	if first := flat[0].(map[string]interface{}); first["deleted"] != true || first["content"] != "" {
+		t.Fatalf("expected tombstone, got %v", first)
+	}
+	expectLen(t, api.do("GET", "/api/search?type=comments&q="+url.QueryEscape("智能合约"), "", nil, http.StatusOK)["comments"], 0)
+
+	// 删除分类时文章移到父分类，删除文章时级联清理评论、版本和标签关联
+	api.do("DELETE", fmt.Sprintf("/api/categories/%v", chain["id"]), alice, nil, http.StatusOK)
+	moved := api.do("GET", fmt.Sprintf("/api/posts/%v", postID), "", nil, http.StatusOK)["post"].(map[string]interface{})
+	if moved["category_id"] != tech["id"] {
+		t.Fatalf("post not moved to parent category: %v", moved["category_id"])
+	}
+	api.do("DELETE", fmt.Sprintf("/api/posts/%v", postID), bob, nil, http.StatusForbidden)
+	api.do("DELETE", fmt.Sprintf("/api/posts/%v", postID), alice, nil, http.StatusOK)
+	api.do("GET", fmt.Sprintf("/api/posts/%v", postID), "", nil, http.StatusNotFound)
+	expectLen(t, api.do("GET", "/api/search?q="+url.QueryEscape("智能合约"), "", nil, http.StatusOK)["posts"], 0)
+
+	tags = api.do("GET", "/api/tags", "", nil, http.StatusOK)["tags"].([]interface{})
+	for _, tag := range tags {
+		tag := tag.(map[string]interface{})
+		if tag["name"] == "区块链" {
+			api.do("DELETE", fmt.Sprintf("/api/tags/%v", tag["id"]), alice, nil, http.StatusOK)
+		}
+	}
+	expectLen(t, api.do("GET", "/api/tags", "", nil, http.StatusOK)["tags"], 2)
+}
+
+// apiClient 通过 httptest 调用路由并检查状态码
+type apiClient struct {
+	t      *testing.T
+	router http.Handler
+	routes *routeRecorder // 不为 nil 时记录访问过的路由
+}
+
+func (a *apiClient) login(username string) string {
+	a.t.Helper()
+	response := a.do("POST", "/api/auth/login", "", gin.H{"username": username, "password": "password123"}, http.StatusOK)
+	return response["token"].(string)
+}
+
+func (a *apiClient) do(method, path, token string, body interface{}, want int) map[string]interface{} {
+	a.t.Helper()
+
+	w := a.request(method, path, token, body, nil)
+	if w.Code != want {
+		a.t.Fatalf("%s %s: status %d, want %d: %s", method, path, w.Code, want, w.Body.String())
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		a.t.Fatalf("%s %s: invalid JSON: %s", method, path, w.Body.String())
+	}
+	return response
+}
+
+// request 发送请求并返回原始响应，body 不为 nil 时编码为 JSON，header 中的值覆盖默认请求头
+func (a *apiClient) request(method, path, token string, body interface{}, header http.Header) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			a.t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+
+	if a.routes != nil {
+		a.routes.record(method, req.URL.Path)
+	}
+	return w
+}
+
+func expectLen(t *testing.T, value interface{}, want int) {
+	t.Helper()
+	items, _ := value.([]interface{})
+	if len(items) != want {
+		t.Fatalf("got %d items, want %d: %v", len(items), want, value)
+	}
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// wakeup 定时文章变化时唤醒调度器重新计算下一次执行时间
+var wakeup = make(chan struct{}, 1)
+
+// NotifyScheduleChanged 通知调度器有文章被设为定时发布或取消定时
+func NotifyScheduleChanged() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// StartPostScheduler 启动后台 goroutine，到点把定时文章改为已发布
+// 调度器会睡眠到下一篇定时文章的发布时间，最长不超过 interval。
+// ctx 取消后调度器退出，返回的 channel 在 goroutine 结束时关闭
+func StartPostScheduler(ctx context.Context, db *gorm.DB, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-wakeup:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			case <-timer.C:
+			}
+
+			now := time.Now().UTC()
+			if n, err := models.PublishDuePosts(db, now); err != nil {
+				slog.Error("Failed to publish scheduled posts", "error", err)
+			} else if n > 0 {
+				slog.Info("Published scheduled posts", "count", n)
+			}
+
+			timer.Reset(nextDelay(db, now, interval))
+		}
+	}()
+	return done
+}
+
+func nextDelay(db *gorm.DB, now time.Time, interval time.Duration) time.Duration {
+	next, err := models.NextScheduledAt(db)
+	if err != nil {
+		slog.Error("Failed to load next scheduled post", "error", err)
+		return interval
+	}
+	if next == nil {
+		return interval
+	}
+	if d := next.Sub(now); d < interval {
+		if d < 0 {
+			return 0
+		}
+		return d
+	}
+	return interval
+}
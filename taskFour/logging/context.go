@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey struct{}
+
+// Fields 一次请求的日志字段，由请求 ID 中间件放入请求的 context
+type Fields struct {
+	RequestID string
+	Route     string // 路由模板，如 /api/posts/:id
+	UserID    uint   // 认证中间件识别出用户后设置
+	Start     time.Time
+}
+
+// NewContext 返回携带请求日志字段的 context
+func NewContext(ctx context.Context, fields *Fields) context.Context {
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// FromContext 取出请求日志字段，不在请求中时返回 nil
+func FromContext(ctx context.Context) *Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextKey{}).(*Fields)
+	return fields
+}
+
+// SetUserID 记录当前请求的用户，之后的日志都会带上 user_id
+func SetUserID(ctx context.Context, userID uint) {
+	if fields := FromContext(ctx); fields != nil {
+		fields.UserID = userID
+	}
+}
+
+// Handler 把 context 中的请求字段和 OpenTelemetry 的 trace_id、span_id 附加到每条日志上
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler 包装 next，日志使用 *Context 系列方法（如 slog.InfoContext）时附加请求字段
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if fields := FromContext(ctx); fields != nil {
+		record.AddAttrs(slog.String("request_id", fields.RequestID))
+		if fields.Route != "" {
+			record.AddAttrs(slog.String("route", fields.Route))
+		}
+		if fields.UserID != 0 {
+			record.AddAttrs(slog.Uint64("user_id", uint64(fields.UserID)))
+		}
+		if !fields.Start.IsZero() {
+			record.AddAttrs(slog.Float64("latency_ms", milliseconds(time.Since(fields.Start))))
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// milliseconds 毫秒数，保留三位小数
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
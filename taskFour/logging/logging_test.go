@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// capture 把默认 logger 换成写入缓冲区的 JSON logger，测试结束后恢复
+func capture(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestHandlerAddsRequestFields(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+
+	ctx := NewContext(context.Background(), &Fields{RequestID: "req-1", Route: "/api/posts/:id", Start: time.Now()})
+	slog.InfoContext(ctx, "before auth")
+	SetUserID(ctx, 42)
+	slog.InfoContext(ctx, "after auth")
+	slog.Info("no request")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines: %s", len(lines), buf)
+	}
+	if lines[0]["request_id"] != "req-1" || lines[0]["route"] != "/api/posts/:id" || lines[0]["user_id"] != nil {
+		t.Errorf("unexpected fields: %v", lines[0])
+	}
+	if _, ok := lines[0]["latency_ms"].(float64); !ok {
+		t.Errorf("missing latency_ms: %v", lines[0])
+	}
+	if lines[1]["user_id"] != float64(42) {
+		t.Errorf("user_id not attached: %v", lines[1])
+	}
+	if _, ok := lines[2]["request_id"]; ok {
+		t.Errorf("request fields outside a request: %v", lines[2])
+	}
+}
+
+func TestHandlerAddsTraceIDs(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	slog.InfoContext(ctx, "traced")
+
+	lines := decodeLines(t, buf)
+	if lines[0]["trace_id"] != traceID.String() || lines[0]["span_id"] != spanID.String() {
+		t.Errorf("trace ids not attached: %v", lines[0])
+	}
+}
+
+func TestGormLoggerLevels(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+	l := NewGormLogger(100 * time.Millisecond)
+	ctx := NewContext(context.Background(), &Fields{RequestID: "req-2"})
+	sql := func() (string, int64) { return "SELECT 1", 1 }
+
+	l.Trace(ctx, time.Now(), sql, nil)                         // debug，不输出
+	l.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)      // 未找到记录不算错误
+	l.Trace(ctx, time.Now().Add(-time.Second), sql, nil)       // 慢查询
+	l.Trace(ctx, time.Now(), sql, errors.New("no such table")) // 错误
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %s", len(lines), buf)
+	}
+	if lines[0]["level"] != "WARN" || lines[0]["msg"] != "slow sql" || lines[0]["request_id"] != "req-2" {
+		t.Errorf("unexpected slow query log: %v", lines[0])
+	}
+	if lines[1]["level"] != "ERROR" || lines[1]["error"] != "no such table" || lines[1]["sql"] != "SELECT 1" {
+		t.Errorf("unexpected error log: %v", lines[1])
+	}
+}
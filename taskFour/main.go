@@ -1,13 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"taskFour/config"
 	"taskFour/controllers"
+	"taskFour/jobs"
+	"taskFour/logging"
+	"taskFour/metrics"
 	"taskFour/middleware"
+	"taskFour/migrations"
 	"taskFour/models"
+	"taskFour/moderation"
+	"taskFour/ratelimit"
+	"taskFour/repositories"
+	"taskFour/services"
+	"taskFour/tracing"
 
 	_ "taskFour/docs" // 重要：导入自动生成的docs包
 
@@ -41,109 +56,325 @@ var db *gorm.DB
 // @description JWT认证令牌，格式: "Bearer {token}"
 
 func main() {
+	// 加载配置：默认值 < 配置文件 < 环境变量 < 命令行参数
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	// 查看生效配置子命令：config print
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] != "migrate" {
+		log.Fatalf("Unknown command %q, run with -h for usage", args[0])
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+	cfg.Apply()
+	if cfg.Mode == config.ModeProd {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// 设置日志，migrate 子命令的日志输出到标准错误
+	logOptions := cfg.Log.LoggingOptions()
+	if len(args) > 0 && args[0] == "migrate" {
+		logOptions.File = "stderr"
+	}
+	_, logFile, err := logging.Setup(logOptions)
+	if err != nil {
+		log.Fatal("Failed to open log file:", err)
+	}
+	defer logFile.Close()
+
+	// 默认密钥只在 dev 模式下允许使用，仍然提示，避免忘记修改就部署
+	if cfg.Auth.JWTSecret == config.DefaultJWTSecret {
+		log.Println("WARNING: using the default JWT secret, set auth.jwt_secret (JWT_SECRET) before deploying")
+	}
+
 	// 初始化数据库
-	var err error
 	err = config.ConnectDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	db = config.GetDB()
+	if err := metrics.RegisterDB(db); err != nil {
+		log.Fatal("Failed to register database metrics:", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal("Failed to register database tracing:", err)
+	}
 
-	// 自动迁移数据库表
-	err = db.AutoMigrate(&models.User{}, &models.Post{}, &models.Comment{})
+	migrator, err := migrations.New(db)
 	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	// 数据库迁移子命令：migrate up|down [n]|status|to N
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(migrator, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 执行数据库迁移，数据库版本比程序新、或未自动迁移且有未执行的迁移时拒绝启动
+	if err := migrateOnStartup(migrator); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
-	// 设置日志
-	setupLogger()
+	// 初始化全文索引
+	if err := models.SetupSearch(db); err != nil {
+		log.Fatal("Failed to setup full-text search:", err)
+	}
+
+	// 初始化管理员
+	if err := bootstrapAdmin(config.AdminUsername); err != nil {
+		log.Fatal("Failed to bootstrap admin:", err)
+	}
+
+	// 清理过期的令牌记录
+	if err := services.NewTokenService(repositories.NewTokenRepository(db)).PurgeExpired(context.Background()); err != nil {
+		log.Println("Failed to purge expired tokens:", err)
+	}
+
+	// 收到 SIGINT/SIGTERM 时取消 ctx，停止调度器并关闭服务器
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.TracingOptions())
+	if err != nil {
+		log.Fatal("Failed to setup tracing:", err)
+	}
+
+	// 初始化限流存储
+	rateLimitStore, closeRateLimitStore, err := cfg.RateLimit.OpenStore(ctx)
+	if err != nil {
+		log.Fatal("Failed to setup rate limit store:", err)
+	}
+	ratelimit.SetStore(rateLimitStore)
+
+	// 启动定时发布调度器
+	schedulerDone := jobs.StartPostScheduler(ctx, db, config.PostSchedulerInterval)
 
 	// 初始化Gin路由
-	router := setupRouter()
+	router := setupRouter(migrator)
 
 	// 启动服务器
-	log.Println("Server starting on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	server := &http.Server{
+		Addr:    cfg.Server.Addr(),
+		Handler: router,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Println("Server starting on " + server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	var runErr error
+	select {
+	case runErr = <-serverErr:
+	case <-ctx.Done():
+		log.Println("Shutting down server...")
+	}
+	stop()
+
+	// 等待进行中的请求完成，超时后强制关闭
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server forced to shut down:", err)
+	}
+	<-schedulerDone
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Println("Failed to flush traces:", err)
 	}
+
+	if err := closeRateLimitStore(); err != nil {
+		log.Println("Failed to close rate limit store:", err)
+	}
+	if err := config.CloseDatabase(); err != nil {
+		log.Println("Failed to close database:", err)
+	}
+	if runErr != nil {
+		log.Fatal("Failed to start server:", runErr)
+	}
+	log.Println("Server exited")
 }
 
-// setupRouter 配置路由
-func setupRouter() *gin.Engine {
-	router := gin.Default()
+// setupRouter 配置路由，migrator 为启动时创建的迁移器，供就绪检查使用
+func setupRouter(migrator *migrations.Migrator) *gin.Engine {
+	router := gin.New()
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
+	}
 
-	// 全局中间件
+	// 全局中间件：链路追踪最先执行，之后的日志和 SQL 都能关联到请求的 span
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.Recovery())
 	router.Use(middleware.ErrorHandler())
 
+	// 仓储和服务，处理器和中间件只通过服务或仓储访问数据
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to get database connection:", err)
+	}
+	userRepo := repositories.NewUserRepository(db)
+	postRepo := repositories.NewPostRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	tokenRepo := repositories.NewTokenRepository(db)
+	middleware.SetRepositories(tokenRepo, userRepo)
+	tokenService := services.NewTokenService(tokenRepo)
+	userHandler := controllers.NewUserHandler(services.NewUserService(userRepo), tokenService)
+	postHandler := controllers.NewPostHandler(services.NewPostService(postRepo, commentRepo, userRepo))
+	commentHandler := controllers.NewCommentHandler(services.NewCommentService(commentRepo, postRepo, userRepo, moderation.ForDB(db)))
+	searchHandler := controllers.NewSearchHandler(services.NewSearchService(postRepo, commentRepo))
+	taxonomyHandler := controllers.NewTaxonomyHandler(services.NewTaxonomyService(repositories.NewCategoryRepository(db), repositories.NewTagRepository(db)))
+	identityHandler := controllers.NewIdentityHandler(services.NewIdentityService(repositories.NewIdentityRepository(db), userRepo, tokenRepo), tokenService)
+
 	// Swagger路由
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// 健康检查
-	router.GET("/health", healthCheck)
+	// Prometheus 指标
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// 健康检查：存活和就绪探针，/health 为兼容旧版本保留。就绪检查复用启动时的迁移器，不再每次加载迁移脚本
+	healthHandler := controllers.NewHealthHandler(migrator, sqlDB)
+	router.GET("/livez", controllers.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", controllers.Livez)
 
 	// API路由分组 - 添加这部分缺失的路由配置
 	api := router.Group("/api")
+	api.Use(middleware.RateLimit("api", config.RateLimitAPI, middleware.RateLimitByUser))
 	{
-		// 认证路由
+		// 认证路由，始终按客户端 IP 单独限流，防止暴力破解
 		auth := api.Group("/auth")
+		auth.Use(middleware.RateLimit("auth", config.RateLimitAuth, middleware.RateLimitByIP))
 		{
-			auth.POST("/register", controllers.Register)
-			auth.POST("/login", controllers.Login)
+			auth.POST("/register", userHandler.Register)
+			auth.POST("/login", userHandler.Login)
+			auth.GET("/nonce", identityHandler.GetNonce)
+			auth.POST("/siwe", identityHandler.SIWELogin)
+			auth.POST("/refresh", userHandler.Refresh)
+			auth.POST("/logout", middleware.AuthMiddleware(), userHandler.Logout)
 		}
 
+		// 搜索路由
+		api.GET("/search", searchHandler.Search)
+
 		// 文章路由
 		posts := api.Group("/posts")
 		{
-			posts.GET("", controllers.GetPosts)
-			posts.GET("/:id", controllers.GetPost)
-			posts.GET("/:id/comments", controllers.GetPostComments)
+			posts.GET("", postHandler.GetPosts)
+			posts.GET("/:id", middleware.OptionalAuthMiddleware(), postHandler.GetPost)
+			posts.GET("/:id/comments", middleware.OptionalAuthMiddleware(), commentHandler.GetPostComments)
+			posts.GET("/:id/revisions", middleware.OptionalAuthMiddleware(), postHandler.ListRevisions)
+			posts.GET("/:id/revisions/diff", middleware.OptionalAuthMiddleware(), postHandler.DiffRevisions)
 
 			// 需要认证的路由
 			authPosts := posts.Group("")
-			authPosts.Use(middleware.AuthMiddleware())
+			authPosts.Use(middleware.AuthMiddleware(), middleware.RateLimit("write", config.RateLimitWrite, middleware.RateLimitByUser))
 			{
-				authPosts.POST("", controllers.CreatePost)
-				authPosts.PUT("/:id", controllers.UpdatePost)
-				authPosts.DELETE("/:id", controllers.DeletePost)
+				authPosts.POST("", middleware.RequirePermission(models.PermPostCreate), postHandler.CreatePost)
+				authPosts.PUT("/:id", postHandler.UpdatePost)
+				authPosts.PATCH("/:id", postHandler.PatchPost)
+				authPosts.DELETE("/:id", postHandler.DeletePost)
+				authPosts.POST("/:id/revisions/:rev/restore", postHandler.RestoreRevision)
+			}
+		}
+
+		// 标签路由
+		tags := api.Group("/tags")
+		{
+			tags.GET("", taxonomyHandler.ListTags)
+
+			manageTags := tags.Group("")
+			manageTags.Use(middleware.AuthMiddleware(), middleware.RequirePermission(models.PermTaxonomyManage))
+			{
+				manageTags.POST("", taxonomyHandler.CreateTag)
+				manageTags.PUT("/:id", taxonomyHandler.UpdateTag)
+				manageTags.DELETE("/:id", taxonomyHandler.DeleteTag)
+			}
+		}
+
+		// 分类路由
+		categories := api.Group("/categories")
+		{
+			categories.GET("", taxonomyHandler.ListCategories)
+
+			manageCategories := categories.Group("")
+			manageCategories.Use(middleware.AuthMiddleware(), middleware.RequirePermission(models.PermTaxonomyManage))
+			{
+				manageCategories.POST("", taxonomyHandler.CreateCategory)
+				manageCategories.PUT("/:id", taxonomyHandler.UpdateCategory)
+				manageCategories.DELETE("/:id", taxonomyHandler.DeleteCategory)
 			}
 		}
 
 		// 评论路由
 		comments := api.Group("/comments")
-		comments.Use(middleware.AuthMiddleware())
+		comments.Use(middleware.AuthMiddleware(), middleware.RateLimit("write", config.RateLimitWrite, middleware.RateLimitByUser))
+		{
+			comments.POST("", middleware.RequirePermission(models.PermCommentCreate), commentHandler.CreateComment)
+			comments.PUT("/:id", commentHandler.UpdateComment)
+			comments.DELETE("/:id", commentHandler.DeleteComment)
+		}
+
+		// 账号路由
+		me := api.Group("/users/me")
+		me.Use(middleware.AuthMiddleware())
 		{
-			comments.POST("", controllers.CreateComment)
+			me.GET("/posts", postHandler.GetMyPosts)
+			me.GET("/identities", identityHandler.ListIdentities)
+			me.POST("/identities/password", identityHandler.AddPasswordIdentity)
+			me.POST("/identities/wallet", identityHandler.AddWalletIdentity)
+			me.POST("/identities/email", identityHandler.AddEmailIdentity)
+			me.DELETE("/identities/:id", identityHandler.DeleteIdentity)
+		}
+
+		// 管理路由
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware())
+		{
+			admin.GET("/users", middleware.RequirePermission(models.PermUserManage), userHandler.ListUsers)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermUserManage), userHandler.UpdateUserRole)
+			admin.GET("/comments", middleware.RequirePermission(models.PermCommentModerate), commentHandler.ListModerationQueue)
+			admin.POST("/comments/approve", middleware.RequirePermission(models.PermCommentModerate), commentHandler.ApproveComments)
+			admin.POST("/comments/reject", middleware.RequirePermission(models.PermCommentModerate), commentHandler.RejectComments)
+			admin.DELETE("/comments/:id", middleware.RequirePermission(models.PermCommentModerate), commentHandler.ModerateDeleteComment)
 		}
 	}
 
 	return router
 }
 
-// healthCheck 健康检查
-// @Summary 健康检查
-// @Description 检查服务是否正常运行
-// @Tags 系统
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string "服务状态"
-// @Router /health [get]
-func healthCheck(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"status":  "ok",
-		"message": "服务运行正常",
-	})
-}
-
-// setupLogger 设置日志
-func setupLogger() {
-	// 创建日志文件
-	file, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatal("Failed to open log file:", err)
+// bootstrapAdmin 将配置的用户提升为管理员
+func bootstrapAdmin(username string) error {
+	if username == "" {
+		return nil
 	}
 
-	// 设置日志输出
-	log.SetOutput(file)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+	result := db.Model(&models.User{}).Where("username = ?", username).Update("role", models.RoleAdmin)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("Admin user %q not found, register it and restart to grant admin role", username)
+	}
+	return nil
 }
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPluginObservesQueries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&widget{}); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Create(&widget{Name: "a"})
+	var widgets []widget
+	db.Find(&widgets)
+	db.Model(&widget{}).Where("id = ?", 1).Update("name", "b")
+
+	for _, operation := range []string{"create", "query", "update"} {
+		var m dto.Metric
+		if err := DBQueryDuration.WithLabelValues(operation, "widgets").(prometheus.Histogram).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m.GetHistogram().GetSampleCount() == 0 {
+			t.Errorf("no %s samples for table widgets", operation)
+		}
+	}
+	if got := testutil.ToFloat64(HTTPRequestsInFlight); got != 0 {
+		t.Errorf("in flight = %v", got)
+	}
+}
@@ -1,14 +1,32 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"fmt"
 	"strings"
+	"taskFour/apperr"
 	"taskFour/config"
+	"taskFour/logging"
+	"taskFour/repositories"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
+// 令牌吊销列表和用户角色通过仓储读取，启动时由 SetRepositories 设置
+var (
+	tokenRepo repositories.TokenRepository
+	userRepo  repositories.UserRepository
+)
+
+// SetRepositories 设置认证和权限中间件使用的仓储，需要在处理请求之前调用
+func SetRepositories(tokens repositories.TokenRepository, users repositories.UserRepository) {
+	tokenRepo = tokens
+	userRepo = users
+}
+
 type Claims struct {
 	UserID uint `json:"user_id"`
 	jwt.RegisteredClaims
@@ -18,33 +36,97 @@ func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			abortWithError(c, apperr.Unauthorized("Authorization header is required"))
 			return
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		claims := &Claims{}
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return config.JWTSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+		claims, err := authenticate(c.Request.Context(), authHeader)
+		if err != nil {
+			abortWithError(c, err)
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
+		setCurrentUser(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware 可选认证，携带有效令牌时设置 user_id，否则按匿名用户处理
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if claims, err := authenticate(c.Request.Context(), authHeader); err == nil {
+				setCurrentUser(c, claims)
+			}
+		}
 		c.Next()
 	}
 }
 
+// setCurrentUser 记录当前登录用户，之后的日志都会带上 user_id
+func setCurrentUser(c *gin.Context, claims *Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("claims", claims)
+	logging.SetUserID(c.Request.Context(), claims.UserID)
+}
+
+// CurrentUserID 获取当前登录用户ID，未登录时返回 false
+func CurrentUserID(c *gin.Context) (uint, bool) {
+	if v, ok := c.Get("user_id"); ok {
+		return v.(uint), true
+	}
+	return 0, false
+}
+
+// authenticate 校验 Authorization 头
+func authenticate(ctx context.Context, authHeader string) (*Claims, *apperr.Error) {
+	tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidToken, "Invalid token", err)
+	}
+
+	// 检查令牌是否已被吊销（登出）
+	revoked, err := tokenRepo.AccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, apperr.Internal("Database error", err)
+	}
+	if revoked {
+		return nil, apperr.New(apperr.CodeInvalidToken, "Token has been revoked")
+	}
+	return claims, nil
+}
+
+// ParseToken 解析并校验访问令牌（签名算法、过期时间、jti）
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return config.JWTSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("invalid token: missing jti or exp")
+	}
+	return claims, nil
+}
+
+// GenerateToken 生成短期访问令牌，包含 exp/iat/jti
 func GenerateToken(userID uint) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID:           userID,
-		RegisteredClaims: jwt.RegisteredClaims{},
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   fmt.Sprint(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(config.AccessTokenTTL)),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
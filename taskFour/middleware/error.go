@@ -1,24 +1,50 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 
+	"taskFour/apperr"
+
 	"github.com/gin-gonic/gin"
 )
 
+// ErrorHandler 请求处理完成后把 c.Error 记录的最后一个错误输出为统一的错误响应，
+// 不是 *apperr.Error 的错误按 500 处理且不向客户端暴露原因。响应已经写出时只记录日志
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
-		if len(c.Errors) > 0 {
-			for _, e := range c.Errors {
-				log.Printf("Error: %v", e.Err)
-			}
-
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error",
-			})
+		if len(c.Errors) == 0 {
+			return
+		}
+		for _, e := range c.Errors {
+			logError(c, apperr.From(e.Err))
 		}
+		if c.Writer.Written() {
+			return
+		}
+		renderError(c, apperr.From(c.Errors.Last().Err))
+	}
+}
+
+// abortWithError 终止请求，错误响应由 ErrorHandler 输出
+func abortWithError(c *gin.Context, err *apperr.Error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// renderError 按 Accept-Language 选择语言输出错误响应
+func renderError(c *gin.Context, err *apperr.Error) {
+	lang := apperr.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	c.AbortWithStatusJSON(err.Status(), err.Response(lang, c.GetString("request_id")))
+}
+
+// logError 服务器错误连同原因记录为 error，客户端错误只在 debug 级别记录
+func logError(c *gin.Context, err *apperr.Error) {
+	level := slog.LevelDebug
+	if err.Status() >= http.StatusInternalServerError {
+		level = slog.LevelError
 	}
+	slog.Log(c.Request.Context(), level, "request error", "code", err.Code, "error", err.Error())
 }
@@ -1,30 +1,42 @@
 package middleware
 
 import (
-	"log"
-	"time"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"taskFour/apperr"
 
 	"github.com/gin-gonic/gin"
 )
 
+// LoggerMiddleware 请求结束后记录访问日志，需要放在 RequestID 之后，
+// 请求 ID、用户、路由和耗时由 logging.Handler 附加
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		start := time.Now()
-
 		c.Next()
 
-		latency := time.Since(start)
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
-		path := c.Request.URL.Path
-
-		log.Printf("| %3d | %13v | %15s | %-7s %s",
-			statusCode,
-			latency,
-			clientIP,
-			method,
-			path,
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+			"user_agent", c.Request.UserAgent(),
 		)
 	}
 }
+
+// Recovery 捕获 handler 中的 panic，连同调用栈写入日志后返回 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered", "error", err, "stack", string(debug.Stack()))
+		renderError(c, apperr.New(apperr.CodeInternal, "Internal server error"))
+	})
+}
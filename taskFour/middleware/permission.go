@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log/slog"
+	"taskFour/apperr"
+	"taskFour/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 要求当前用户拥有全部指定权限，需要放在 AuthMiddleware 之后
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := CurrentRole(c)
+		if err != nil {
+			abortWithError(c, apperr.Internal("Failed to load user role", err))
+			return
+		}
+
+		for _, p := range permissions {
+			if !models.HasPermission(role, p) {
+				abortWithError(c, apperr.Forbidden("Permission denied"))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentRole 获取当前用户的角色，角色每次请求从仓储读取，修改后立即生效
+func CurrentRole(c *gin.Context) (string, error) {
+	if role, ok := c.Get("role"); ok {
+		return role.(string), nil
+	}
+
+	user, err := userRepo.Find(c.Request.Context(), c.MustGet("user_id").(uint))
+	if err != nil {
+		return "", err
+	}
+
+	c.Set("role", user.Role)
+	return user.Role, nil
+}
+
+// HasPermission 判断当前用户是否拥有指定权限，供控制器做资源级别的判断
+func HasPermission(c *gin.Context, permission string) bool {
+	role, err := CurrentRole(c)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to load role", "error", err)
+		return false
+	}
+	return models.HasPermission(role, permission)
+}
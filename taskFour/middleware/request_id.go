@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"taskFour/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader 请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID 客户端或网关传入的请求 ID 只接受常见字符，避免日志注入
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID 沿用请求头中的 X-Request-ID，没有或不合法时生成新的，写回响应头，
+// 并把请求 ID 和路由放入请求的 context，之后通过 slog.*Context 记录的日志都会带上
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := logging.NewContext(c.Request.Context(), &logging.Fields{
+			RequestID: id,
+			Route:     c.FullPath(),
+			Start:     time.Now(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", id))
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"taskFour/config"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// untracedPaths 探针和指标接口调用频繁，不创建 span
+var untracedPaths = map[string]bool{
+	"/livez":   true,
+	"/readyz":  true,
+	"/health":  true,
+	"/metrics": true,
+}
+
+// Tracing 为每个请求创建 server span，从 traceparent 请求头延续上游的链路，
+// span 名为 "方法 路由模板"，如 GET /api/posts/:id
+func Tracing() gin.HandlerFunc {
+	return otelgin.Middleware(config.TracingServiceName,
+		otelgin.WithFilter(func(r *http.Request) bool {
+			return !untracedPaths[r.URL.Path]
+		}),
+	)
+}
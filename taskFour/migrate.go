@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"taskFour/config"
+	"taskFour/migrations"
+)
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(migrator *migrations.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [n] | status | to <version>")
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", n)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			var err error
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid migration count %q", args[1])
+			}
+		}
+		return migrator.Down(n)
+	case "to":
+		if len(args) < 2 {
+			return errors.New("usage: migrate to <version>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid migration version %q", args[1])
+		}
+		return migrator.To(version)
+	case "status":
+		statuses, err := migrator.Status()
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-24s %s\n", s.Version, s.Name, applied)
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+	return nil
+}
+
+// migrateOnStartup 启动时检查数据库版本，DB_AUTO_MIGRATE 开启时执行未执行的迁移。
+// 关闭时有未执行的迁移则拒绝启动，设置 DB_ALLOW_PENDING_MIGRATIONS 后只提示
+func migrateOnStartup(migrator *migrations.Migrator) error {
+	if config.DBAutoMigrate {
+		_, err := migrator.Up()
+		return err
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if !config.DBAllowPendingMigrations {
+		return fmt.Errorf("database has %d pending migration(s), run \"migrate up\" to apply them "+
+			"or set database.allow_pending_migrations to start anyway", len(pending))
+	}
+	log.Printf("Database has %d pending migration(s), run \"migrate up\" to apply them", len(pending))
+	return nil
+}
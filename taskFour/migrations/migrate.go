@@ -0,0 +1,376 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 每种数据库一个目录，版本号和名称必须一一对应
+//
+//go:embed sqlite/*.sql mysql/*.sql postgres/*.sql
+var files embed.FS
+
+// ErrSchemaAhead 数据库中已经应用了当前程序不认识的迁移（通常是用更新的版本迁移过）
+var ErrSchemaAhead = errors.New("database schema is ahead of this binary")
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移的执行状态
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// schemaMigration schema_migrations 表的一行
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 执行内嵌的 SQL 迁移，已执行的版本记录在 schema_migrations 表中
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 创建迁移器，按数据库类型加载程序内嵌的迁移脚本
+func New(db *gorm.DB) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	if _, err := fs.Stat(files, dialect); err != nil {
+		return nil, fmt.Errorf("no migrations for database %q", dialect)
+	}
+	migrations, err := load(files, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load 读取目录下的 NNNN_name.up.sql / NNNN_name.down.sql，按版本号排序
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, m[2])
+		}
+		if m[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down scripts", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest 程序内嵌的最新版本号
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Current 数据库当前的版本号（已执行的最大版本），没有执行过任何迁移时为 0
+func (m *Migrator) Current() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for version := range applied {
+		current = max(current, version)
+	}
+	return current, nil
+}
+
+// Check 数据库版本比程序新时返回 ErrSchemaAhead
+func (m *Migrator) Check() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	return m.checkApplied(applied)
+}
+
+// Pending 返回尚未执行的迁移
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkApplied(applied); err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Inspect 只读地查询数据库当前版本和未执行的迁移，供就绪检查反复调用。
+// 与 Current、Pending 不同，不会创建 schema_migrations 表，表不存在时全部迁移都视为未执行
+func (m *Migrator) Inspect(ctx context.Context) (current int, pending []Migration, err error) {
+	db := m.db.WithContext(ctx)
+	applied := make(map[int]schemaMigration)
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		var rows []schemaMigration
+		if err := db.Order("version").Find(&rows).Error; err != nil {
+			return 0, nil, err
+		}
+		for _, row := range rows {
+			applied[row.Version] = row
+			current = max(current, row.Version)
+		}
+	}
+	if err := m.checkApplied(applied); err != nil {
+		return current, nil, err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return current, pending, nil
+}
+
+// Status 返回每个迁移的执行状态
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, m.checkApplied(applied)
+}
+
+// Up 执行全部未执行的迁移，返回执行的数量
+func (m *Migrator) Up() (int, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return 0, err
+	}
+	for _, migration := range pending {
+		if err := m.apply(migration, true); err != nil {
+			return 0, err
+		}
+	}
+	return len(pending), nil
+}
+
+// Down 按版本倒序回滚最近的 n 个迁移
+func (m *Migrator) Down(n int) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if err := m.checkApplied(applied); err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+		if _, ok := applied[m.migrations[i].Version]; !ok {
+			continue
+		}
+		if err := m.apply(m.migrations[i], false); err != nil {
+			return err
+		}
+		n--
+	}
+	return nil
+}
+
+// To 迁移到指定版本：执行小于等于该版本的未执行迁移，回滚大于该版本的已执行迁移
+func (m *Migrator) To(version int) error {
+	if version < 0 || version > m.Latest() {
+		return fmt.Errorf("unknown migration version %d (latest is %d)", version, m.Latest())
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if err := m.checkApplied(applied); err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > version {
+			if err := m.apply(migration, false); err != nil {
+				return err
+			}
+		}
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+			if err := m.apply(migration, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// apply 在一个事务中执行迁移脚本并更新 schema_migrations。
+// MySQL 的 DDL 会隐式提交事务，脚本中途失败时需要手工修复后再重试
+func (m *Migrator) apply(migration Migration, up bool) error {
+	script, direction := migration.Down, "down"
+	if up {
+		script, direction = migration.Up, "up"
+	}
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+		}
+		return tx.Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+	slog.Info("Migration applied", "version", migration.Version, "name", migration.Name, "direction", direction)
+	return nil
+}
+
+// applied 读取已执行的迁移，首次运行时创建 schema_migrations 表
+func (m *Migrator) applied() (map[int]schemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var rows []schemaMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// ensureTable 创建 schema_migrations 表。引入迁移之前创建的数据库已经有初始表结构，
+// 此时把版本 1 记为已执行，后续迁移在其基础上继续
+func (m *Migrator) ensureTable() error {
+	if m.db.Migrator().HasTable(&schemaMigration{}) {
+		return nil
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)").Error; err != nil {
+			return err
+		}
+		if !tx.Migrator().HasTable("users") || len(m.migrations) == 0 {
+			return nil
+		}
+		slog.Info("Existing database without schema_migrations, marking initial migration as applied")
+		return tx.Create(&schemaMigration{Version: m.migrations[0].Version, Name: m.migrations[0].Name, AppliedAt: time.Now().UTC()}).Error
+	})
+}
+
+func (m *Migrator) checkApplied(applied map[int]schemaMigration) error {
+	known := make(map[int]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+	for version, row := range applied {
+		if !known[version] {
+			return fmt.Errorf("%w: migration %d_%s is not known (latest is %d)", ErrSchemaAhead, version, row.Name, m.Latest())
+		}
+	}
+	return nil
+}
+
+// splitStatements 按行尾分号拆分脚本，跳过注释行，不依赖驱动的多语句支持
+func splitStatements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
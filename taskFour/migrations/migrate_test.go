@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// 各数据库的迁移必须版本号和名称一一对应，schema_migrations 的记录才能互相对照
+func TestDialectsHaveSameMigrations(t *testing.T) {
+	base, err := load(files, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dialect := range []string{"mysql", "postgres"} {
+		migrations, err := load(files, dialect)
+		if err != nil {
+			t.Fatalf("%s: %v", dialect, err)
+		}
+		if len(migrations) != len(base) {
+			t.Fatalf("%s has %d migrations, sqlite has %d", dialect, len(migrations), len(base))
+		}
+		for i, m := range migrations {
+			if m.Version != base[i].Version || m.Name != base[i].Name {
+				t.Errorf("%s migration %d_%s does not match sqlite %d_%s", dialect, m.Version, m.Name, base[i].Version, base[i].Name)
+			}
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := "-- comment\nCREATE TABLE a (id integer);\n\nINSERT INTO a\nVALUES (1);\nSELECT 1"
+	stmts := splitStatements(script)
+	want := []string{"CREATE TABLE a (id integer);", "INSERT INTO a\nVALUES (1);", "SELECT 1"}
+	if len(stmts) != len(want) {
+		t.Fatalf("got %q", stmts)
+	}
+	for i := range want {
+		if stmts[i] != want[i] {
+			t.Errorf("statement %d: got %q, want %q", i, stmts[i], want[i])
+		}
+	}
+}
+
+// Inspect 供就绪检查反复调用，不能创建 schema_migrations 表
+func TestInspectIsReadOnly(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, pending, err := migrator.Inspect(context.Background())
+	if err != nil || current != 0 || len(pending) != len(migrator.migrations) {
+		t.Fatalf("empty database: current %d, %d pending, %v", current, len(pending), err)
+	}
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		t.Fatal("Inspect created schema_migrations")
+	}
+
+	if err := migrator.To(1); err != nil {
+		t.Fatal(err)
+	}
+	current, pending, err = migrator.Inspect(context.Background())
+	if err != nil || current != 1 || len(pending) != len(migrator.migrations)-1 {
+		t.Fatalf("after first migration: current %d, %d pending, %v", current, len(pending), err)
+	}
+
+	// 数据库版本比程序新
+	if err := db.Create(&schemaMigration{Version: migrator.Latest() + 1, Name: "future"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := migrator.Inspect(context.Background()); !errors.Is(err, ErrSchemaAhead) {
+		t.Fatalf("schema ahead: err = %v", err)
+	}
+}
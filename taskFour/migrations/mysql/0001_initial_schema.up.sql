@@ -0,0 +1,6 @@
+-- 初始表结构：用户、文章、评论
+CREATE TABLE `users` (`id` bigint unsigned AUTO_INCREMENT,`username` varchar(100) NOT NULL,`password` longtext NOT NULL,`email` varchar(191) NOT NULL,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_users_username` (`username`),UNIQUE INDEX `idx_users_email` (`email`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `posts` (`id` bigint unsigned AUTO_INCREMENT,`title` varchar(200) NOT NULL,`content` longtext NOT NULL,`user_id` bigint unsigned NOT NULL,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`),CONSTRAINT `fk_users_posts` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `comments` (`id` bigint unsigned AUTO_INCREMENT,`content` longtext NOT NULL,`user_id` bigint unsigned NOT NULL,`post_id` bigint unsigned NOT NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),CONSTRAINT `fk_posts_comments` FOREIGN KEY (`post_id`) REFERENCES `posts`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_users_comments` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
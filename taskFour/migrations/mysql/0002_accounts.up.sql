@@ -0,0 +1,15 @@
+-- 账号：角色、钱包用户的空邮箱、刷新令牌、吊销的访问令牌、登录随机数、身份
+ALTER TABLE `users` MODIFY `email` varchar(191) NULL, ADD COLUMN `role` varchar(20) NOT NULL DEFAULT 'user';
+UPDATE `users` SET `email` = NULL WHERE `email` = '';
+
+CREATE TABLE `refresh_tokens` (`id` bigint unsigned AUTO_INCREMENT,`user_id` bigint unsigned NOT NULL,`token_hash` varchar(64) NOT NULL,`family_id` varchar(36) NOT NULL,`expires_at` datetime(3) NOT NULL,`revoked_at` datetime(3) NULL,`replaced_by` bigint unsigned NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_refresh_tokens_user_id` (`user_id`),UNIQUE INDEX `idx_refresh_tokens_token_hash` (`token_hash`),INDEX `idx_refresh_tokens_family_id` (`family_id`),CONSTRAINT `fk_refresh_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `revoked_tokens` (`id` bigint unsigned AUTO_INCREMENT,`jti` varchar(36) NOT NULL,`user_id` bigint unsigned NOT NULL,`expires_at` datetime(3) NOT NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_revoked_tokens_jti` (`jti`),INDEX `idx_revoked_tokens_user_id` (`user_id`),INDEX `idx_revoked_tokens_expires_at` (`expires_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `auth_nonces` (`id` bigint unsigned AUTO_INCREMENT,`nonce` varchar(32) NOT NULL,`expires_at` datetime(3) NOT NULL,`used_at` datetime(3) NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_auth_nonces_nonce` (`nonce`),INDEX `idx_auth_nonces_expires_at` (`expires_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `user_identities` (`id` bigint unsigned AUTO_INCREMENT,`user_id` bigint unsigned NOT NULL,`provider` varchar(20) NOT NULL,`subject` varchar(255) NOT NULL,`verified_at` datetime(3) NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),INDEX `idx_user_identities_user_id` (`user_id`),UNIQUE INDEX `idx_identity_provider_subject` (`provider`,`subject`),CONSTRAINT `fk_users_identities` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 为已有用户补齐密码和邮箱身份
+INSERT INTO `user_identities` (`user_id`,`provider`,`subject`,`verified_at`,`created_at`) SELECT `id`,'password',`username`,`created_at`,CURRENT_TIMESTAMP(3) FROM `users` WHERE `password` <> '';
+INSERT INTO `user_identities` (`user_id`,`provider`,`subject`,`created_at`) SELECT `id`,'email',`email`,CURRENT_TIMESTAMP(3) FROM `users` WHERE `email` IS NOT NULL;
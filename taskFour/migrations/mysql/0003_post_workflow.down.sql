@@ -0,0 +1,4 @@
+DROP TABLE `post_revisions`;
+DROP INDEX `idx_posts_published_at` ON `posts`;
+DROP INDEX `idx_posts_status` ON `posts`;
+ALTER TABLE `posts` DROP COLUMN `published_at`, DROP COLUMN `status`;
@@ -0,0 +1,9 @@
+-- 文章状态、发布时间和历史版本
+ALTER TABLE `posts` ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'published', ADD COLUMN `published_at` datetime(3) NULL;
+CREATE INDEX `idx_posts_status` ON `posts`(`status`);
+CREATE INDEX `idx_posts_published_at` ON `posts`(`published_at`);
+
+-- 已有文章视为在创建时发布
+UPDATE `posts` SET `published_at` = `created_at` WHERE `status` = 'published' AND `published_at` IS NULL;
+
+CREATE TABLE `post_revisions` (`id` bigint unsigned AUTO_INCREMENT,`post_id` bigint unsigned NOT NULL,`rev` bigint NOT NULL,`editor_id` bigint unsigned NOT NULL,`title` varchar(200) NOT NULL,`content` longtext NOT NULL,`restored_from` bigint NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_post_revision` (`post_id`,`rev`),CONSTRAINT `fk_post_revisions_editor` FOREIGN KEY (`editor_id`) REFERENCES `users`(`id`),CONSTRAINT `fk_post_revisions_post` FOREIGN KEY (`post_id`) REFERENCES `posts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
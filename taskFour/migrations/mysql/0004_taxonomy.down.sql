@@ -0,0 +1,6 @@
+ALTER TABLE `posts` DROP FOREIGN KEY `fk_posts_category`;
+ALTER TABLE `posts` DROP INDEX `idx_posts_category_id`, DROP COLUMN `category_id`;
+
+DROP TABLE `post_tags`;
+DROP TABLE `tags`;
+DROP TABLE `categories`;
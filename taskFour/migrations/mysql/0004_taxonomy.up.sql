@@ -0,0 +1,9 @@
+-- 分类（树形）、标签以及文章与标签的关联
+CREATE TABLE `categories` (`id` bigint unsigned AUTO_INCREMENT,`name` varchar(50) NOT NULL,`slug` varchar(60) NOT NULL,`parent_id` bigint unsigned NULL,`created_at` datetime(3) NULL,`updated_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_categories_slug` (`slug`),INDEX `idx_categories_parent_id` (`parent_id`),CONSTRAINT `fk_categories_children` FOREIGN KEY (`parent_id`) REFERENCES `categories`(`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tags` (`id` bigint unsigned AUTO_INCREMENT,`name` varchar(50) NOT NULL,`slug` varchar(60) NOT NULL,`created_at` datetime(3) NULL,PRIMARY KEY (`id`),UNIQUE INDEX `idx_tags_name` (`name`),UNIQUE INDEX `idx_tags_slug` (`slug`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 外键强制生效，删除文章或标签时级联删除关联
+CREATE TABLE `post_tags` (`post_id` bigint unsigned NOT NULL,`tag_id` bigint unsigned NOT NULL,PRIMARY KEY (`post_id`,`tag_id`),INDEX `idx_post_tags_tag_id` (`tag_id`),CONSTRAINT `fk_post_tags_post` FOREIGN KEY (`post_id`) REFERENCES `posts`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_post_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `posts` ADD COLUMN `category_id` bigint unsigned NULL, ADD INDEX `idx_posts_category_id` (`category_id`), ADD CONSTRAINT `fk_posts_category` FOREIGN KEY (`category_id`) REFERENCES `categories`(`id`) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+DROP INDEX `idx_comments_status` ON `comments`;
+DROP INDEX `idx_comments_parent_id` ON `comments`;
+ALTER TABLE `comments` DROP COLUMN `edited_at`, DROP COLUMN `moderation_note`, DROP COLUMN `status`, DROP COLUMN `deleted`, DROP COLUMN `depth`, DROP COLUMN `parent_id`;
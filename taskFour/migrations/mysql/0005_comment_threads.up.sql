@@ -0,0 +1,4 @@
+-- 评论回复、墓碑、修改时间和审核状态
+ALTER TABLE `comments` ADD COLUMN `parent_id` bigint unsigned NULL, ADD COLUMN `depth` bigint NOT NULL DEFAULT 0, ADD COLUMN `deleted` boolean NOT NULL DEFAULT false, ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'approved', ADD COLUMN `moderation_note` varchar(255) NULL, ADD COLUMN `edited_at` datetime(3) NULL;
+CREATE INDEX `idx_comments_parent_id` ON `comments`(`parent_id`);
+CREATE INDEX `idx_comments_status` ON `comments`(`status`);
@@ -0,0 +1,2 @@
+-- 文章版本号，用于乐观并发控制
+ALTER TABLE `posts` ADD COLUMN `version` bigint unsigned NOT NULL DEFAULT 1;
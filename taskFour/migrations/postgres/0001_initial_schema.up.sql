@@ -0,0 +1,8 @@
+-- 初始表结构：用户、文章、评论
+CREATE TABLE users (id bigserial PRIMARY KEY,username varchar(100) NOT NULL,password text NOT NULL,email varchar(191) NOT NULL,created_at timestamptz,updated_at timestamptz);
+CREATE UNIQUE INDEX idx_users_email ON users(email);
+CREATE UNIQUE INDEX idx_users_username ON users(username);
+
+CREATE TABLE posts (id bigserial PRIMARY KEY,title varchar(200) NOT NULL,content text NOT NULL,user_id bigint NOT NULL,created_at timestamptz,updated_at timestamptz,CONSTRAINT fk_users_posts FOREIGN KEY (user_id) REFERENCES users(id));
+
+CREATE TABLE comments (id bigserial PRIMARY KEY,content text NOT NULL,user_id bigint NOT NULL,post_id bigint NOT NULL,created_at timestamptz,CONSTRAINT fk_posts_comments FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,CONSTRAINT fk_users_comments FOREIGN KEY (user_id) REFERENCES users(id));
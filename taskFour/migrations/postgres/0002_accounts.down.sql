@@ -0,0 +1,9 @@
+DROP TABLE user_identities;
+DROP TABLE auth_nonces;
+DROP TABLE revoked_tokens;
+DROP TABLE refresh_tokens;
+
+-- 旧表结构要求邮箱非空，钱包用户使用占位邮箱
+UPDATE users SET email = username || '@wallet.invalid' WHERE email IS NULL;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,26 @@
+-- 账号：角色、钱包用户的空邮箱、刷新令牌、吊销的访问令牌、登录随机数、身份
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD COLUMN role varchar(20) NOT NULL DEFAULT 'user';
+UPDATE users SET email = NULL WHERE email = '';
+
+CREATE TABLE refresh_tokens (id bigserial PRIMARY KEY,user_id bigint NOT NULL,token_hash varchar(64) NOT NULL,family_id varchar(36) NOT NULL,expires_at timestamptz NOT NULL,revoked_at timestamptz,replaced_by bigint,created_at timestamptz,CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+
+CREATE TABLE revoked_tokens (id bigserial PRIMARY KEY,jti varchar(36) NOT NULL,user_id bigint NOT NULL,expires_at timestamptz NOT NULL,created_at timestamptz);
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+CREATE INDEX idx_revoked_tokens_user_id ON revoked_tokens(user_id);
+CREATE UNIQUE INDEX idx_revoked_tokens_jti ON revoked_tokens(jti);
+
+CREATE TABLE auth_nonces (id bigserial PRIMARY KEY,nonce varchar(32) NOT NULL,expires_at timestamptz NOT NULL,used_at timestamptz,created_at timestamptz);
+CREATE INDEX idx_auth_nonces_expires_at ON auth_nonces(expires_at);
+CREATE UNIQUE INDEX idx_auth_nonces_nonce ON auth_nonces(nonce);
+
+CREATE TABLE user_identities (id bigserial PRIMARY KEY,user_id bigint NOT NULL,provider varchar(20) NOT NULL,subject varchar(255) NOT NULL,verified_at timestamptz,created_at timestamptz,CONSTRAINT fk_users_identities FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE);
+CREATE UNIQUE INDEX idx_identity_provider_subject ON user_identities(provider,subject);
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+-- 为已有用户补齐密码和邮箱身份
+INSERT INTO user_identities (user_id,provider,subject,verified_at,created_at) SELECT id,'password',username,created_at,CURRENT_TIMESTAMP FROM users WHERE password <> '';
+INSERT INTO user_identities (user_id,provider,subject,created_at) SELECT id,'email',email,CURRENT_TIMESTAMP FROM users WHERE email IS NOT NULL;
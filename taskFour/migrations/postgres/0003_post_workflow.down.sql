@@ -0,0 +1,5 @@
+DROP TABLE post_revisions;
+DROP INDEX idx_posts_published_at;
+DROP INDEX idx_posts_status;
+ALTER TABLE posts DROP COLUMN published_at;
+ALTER TABLE posts DROP COLUMN status;
@@ -0,0 +1,11 @@
+-- 文章状态、发布时间和历史版本
+ALTER TABLE posts ADD COLUMN status varchar(20) NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN published_at timestamptz;
+CREATE INDEX idx_posts_status ON posts(status);
+CREATE INDEX idx_posts_published_at ON posts(published_at);
+
+-- 已有文章视为在创建时发布
+UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+CREATE TABLE post_revisions (id bigserial PRIMARY KEY,post_id bigint NOT NULL,rev bigint NOT NULL,editor_id bigint NOT NULL,title varchar(200) NOT NULL,content text NOT NULL,restored_from bigint,created_at timestamptz,CONSTRAINT fk_post_revisions_editor FOREIGN KEY (editor_id) REFERENCES users(id),CONSTRAINT fk_post_revisions_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE);
+CREATE UNIQUE INDEX idx_post_revision ON post_revisions(post_id,rev);
@@ -0,0 +1,6 @@
+DROP INDEX idx_posts_category_id;
+ALTER TABLE posts DROP COLUMN category_id;
+
+DROP TABLE post_tags;
+DROP TABLE tags;
+DROP TABLE categories;
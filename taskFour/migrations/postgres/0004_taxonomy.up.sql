@@ -0,0 +1,15 @@
+-- 分类（树形）、标签以及文章与标签的关联
+CREATE TABLE categories (id bigserial PRIMARY KEY,name varchar(50) NOT NULL,slug varchar(60) NOT NULL,parent_id bigint,created_at timestamptz,updated_at timestamptz,CONSTRAINT fk_categories_children FOREIGN KEY (parent_id) REFERENCES categories(id));
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+CREATE UNIQUE INDEX idx_categories_slug ON categories(slug);
+
+CREATE TABLE tags (id bigserial PRIMARY KEY,name varchar(50) NOT NULL,slug varchar(60) NOT NULL,created_at timestamptz);
+CREATE UNIQUE INDEX idx_tags_slug ON tags(slug);
+CREATE UNIQUE INDEX idx_tags_name ON tags(name);
+
+-- 外键强制生效，删除文章或标签时级联删除关联
+CREATE TABLE post_tags (post_id bigint NOT NULL,tag_id bigint NOT NULL,PRIMARY KEY (post_id,tag_id),CONSTRAINT fk_post_tags_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,CONSTRAINT fk_post_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE);
+CREATE INDEX idx_post_tags_tag_id ON post_tags(tag_id);
+
+ALTER TABLE posts ADD COLUMN category_id bigint CONSTRAINT fk_posts_category REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX idx_posts_category_id ON posts(category_id);
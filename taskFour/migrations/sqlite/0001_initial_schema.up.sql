@@ -0,0 +1,8 @@
+-- 初始表结构：用户、文章、评论
+CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` text NOT NULL,`password` text NOT NULL,`email` text NOT NULL,`created_at` datetime,`updated_at` datetime);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`);
+
+CREATE TABLE `posts` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text NOT NULL,`content` text NOT NULL,`user_id` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_users_posts` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+
+CREATE TABLE `comments` (`id` integer PRIMARY KEY AUTOINCREMENT,`content` text NOT NULL,`user_id` integer NOT NULL,`post_id` integer NOT NULL,`created_at` datetime,CONSTRAINT `fk_posts_comments` FOREIGN KEY (`post_id`) REFERENCES `posts`(`id`) ON DELETE CASCADE,CONSTRAINT `fk_users_comments` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
@@ -0,0 +1,12 @@
+DROP TABLE `user_identities`;
+DROP TABLE `auth_nonces`;
+DROP TABLE `revoked_tokens`;
+DROP TABLE `refresh_tokens`;
+
+-- 旧表结构要求邮箱非空，钱包用户使用占位邮箱
+CREATE TABLE `users_old` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` text NOT NULL,`password` text NOT NULL,`email` text NOT NULL,`created_at` datetime,`updated_at` datetime);
+INSERT INTO `users_old` (`id`,`username`,`password`,`email`,`created_at`,`updated_at`) SELECT `id`,`username`,`password`,COALESCE(`email`,`username` || '@wallet.invalid'),`created_at`,`updated_at` FROM `users`;
+DROP TABLE `users`;
+ALTER TABLE `users_old` RENAME TO `users`;
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`);
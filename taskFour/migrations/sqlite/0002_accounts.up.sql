@@ -0,0 +1,29 @@
+-- 账号：角色、钱包用户的空邮箱、刷新令牌、吊销的访问令牌、登录随机数、身份
+CREATE TABLE `users_new` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` text NOT NULL,`password` text NOT NULL,`email` text,`role` text NOT NULL DEFAULT "user",`created_at` datetime,`updated_at` datetime);
+INSERT INTO `users_new` (`id`,`username`,`password`,`email`,`created_at`,`updated_at`) SELECT `id`,`username`,`password`,NULLIF(`email`,''),`created_at`,`updated_at` FROM `users`;
+DROP TABLE `users`;
+ALTER TABLE `users_new` RENAME TO `users`;
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`);
+
+CREATE TABLE `refresh_tokens` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`token_hash` text NOT NULL,`family_id` text NOT NULL,`expires_at` datetime NOT NULL,`revoked_at` datetime,`replaced_by` integer,`created_at` datetime,CONSTRAINT `fk_refresh_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE);
+CREATE INDEX `idx_refresh_tokens_family_id` ON `refresh_tokens`(`family_id`);
+CREATE UNIQUE INDEX `idx_refresh_tokens_token_hash` ON `refresh_tokens`(`token_hash`);
+CREATE INDEX `idx_refresh_tokens_user_id` ON `refresh_tokens`(`user_id`);
+
+CREATE TABLE `revoked_tokens` (`id` integer PRIMARY KEY AUTOINCREMENT,`jti` text NOT NULL,`user_id` integer NOT NULL,`expires_at` datetime NOT NULL,`created_at` datetime);
+CREATE INDEX `idx_revoked_tokens_expires_at` ON `revoked_tokens`(`expires_at`);
+CREATE INDEX `idx_revoked_tokens_user_id` ON `revoked_tokens`(`user_id`);
+CREATE UNIQUE INDEX `idx_revoked_tokens_jti` ON `revoked_tokens`(`jti`);
+
+CREATE TABLE `auth_nonces` (`id` integer PRIMARY KEY AUTOINCREMENT,`nonce` text NOT NULL,`expires_at` datetime NOT NULL,`used_at` datetime,`created_at` datetime);
+CREATE INDEX `idx_auth_nonces_expires_at` ON `auth_nonces`(`expires_at`);
+CREATE UNIQUE INDEX `idx_auth_nonces_nonce` ON `auth_nonces`(`nonce`);
+
+CREATE TABLE `user_identities` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`provider` text NOT NULL,`subject` text NOT NULL,`verified_at` datetime,`created_at` datetime,CONSTRAINT `fk_users_identities` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+CREATE UNIQUE INDEX `idx_identity_provider_subject` ON `user_identities`(`provider`,`subject`);
+CREATE INDEX `idx_user_identities_user_id` ON `user_identities`(`user_id`);
+
+-- 为已有用户补齐密码和邮箱身份
+INSERT INTO `user_identities` (`user_id`,`provider`,`subject`,`verified_at`,`created_at`) SELECT `id`,'password',`username`,`created_at`,CURRENT_TIMESTAMP FROM `users` WHERE `password` <> '';
+INSERT INTO `user_identities` (`user_id`,`provider`,`subject`,`created_at`) SELECT `id`,'email',`email`,CURRENT_TIMESTAMP FROM `users` WHERE `email` IS NOT NULL;
@@ -0,0 +1,11 @@
+-- SQLite 不能删除带外键的列，重建 posts 表
+CREATE TABLE `posts_old` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text NOT NULL,`content` text NOT NULL,`status` text NOT NULL DEFAULT "published",`published_at` datetime,`user_id` integer NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_users_posts` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`));
+INSERT INTO `posts_old` (`id`,`title`,`content`,`status`,`published_at`,`user_id`,`created_at`,`updated_at`) SELECT `id`,`title`,`content`,`status`,`published_at`,`user_id`,`created_at`,`updated_at` FROM `posts`;
+DROP TABLE `posts`;
+ALTER TABLE `posts_old` RENAME TO `posts`;
+CREATE INDEX `idx_posts_status` ON `posts`(`status`);
+CREATE INDEX `idx_posts_published_at` ON `posts`(`published_at`);
+
+DROP TABLE `post_tags`;
+DROP TABLE `tags`;
+DROP TABLE `categories`;
@@ -0,0 +1,13 @@
+-- 分类（树形）、标签以及文章与标签的关联
+CREATE TABLE `categories` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`slug` text NOT NULL,`parent_id` integer,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_categories_children` FOREIGN KEY (`parent_id`) REFERENCES `categories`(`id`));
+CREATE INDEX `idx_categories_parent_id` ON `categories`(`parent_id`);
+CREATE UNIQUE INDEX `idx_categories_slug` ON `categories`(`slug`);
+
+CREATE TABLE `tags` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`slug` text NOT NULL,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_tags_slug` ON `tags`(`slug`);
+CREATE UNIQUE INDEX `idx_tags_name` ON `tags`(`name`);
+
+CREATE TABLE `post_tags` (`post_id` integer,`tag_id` integer,PRIMARY KEY (`post_id`,`tag_id`),CONSTRAINT `fk_post_tags_post` FOREIGN KEY (`post_id`) REFERENCES `posts`(`id`),CONSTRAINT `fk_post_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`));
+
+ALTER TABLE `posts` ADD COLUMN `category_id` integer CONSTRAINT `fk_posts_category` REFERENCES `categories`(`id`) ON DELETE SET NULL;
+CREATE INDEX `idx_posts_category_id` ON `posts`(`category_id`);
@@ -0,0 +1,8 @@
+DROP INDEX `idx_comments_status`;
+DROP INDEX `idx_comments_parent_id`;
+ALTER TABLE `comments` DROP COLUMN `edited_at`;
+ALTER TABLE `comments` DROP COLUMN `moderation_note`;
+ALTER TABLE `comments` DROP COLUMN `status`;
+ALTER TABLE `comments` DROP COLUMN `deleted`;
+ALTER TABLE `comments` DROP COLUMN `depth`;
+ALTER TABLE `comments` DROP COLUMN `parent_id`;
@@ -0,0 +1,9 @@
+-- 评论回复、墓碑、修改时间和审核状态
+ALTER TABLE `comments` ADD COLUMN `parent_id` integer;
+ALTER TABLE `comments` ADD COLUMN `depth` integer NOT NULL DEFAULT 0;
+ALTER TABLE `comments` ADD COLUMN `deleted` numeric NOT NULL DEFAULT false;
+ALTER TABLE `comments` ADD COLUMN `status` text NOT NULL DEFAULT "approved";
+ALTER TABLE `comments` ADD COLUMN `moderation_note` text;
+ALTER TABLE `comments` ADD COLUMN `edited_at` datetime;
+CREATE INDEX `idx_comments_parent_id` ON `comments`(`parent_id`);
+CREATE INDEX `idx_comments_status` ON `comments`(`status`);
@@ -0,0 +1,2 @@
+-- 文章版本号，用于乐观并发控制
+ALTER TABLE `posts` ADD COLUMN `version` integer NOT NULL DEFAULT 1;
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Category 文章分类，通过 ParentID 组成树形结构，一篇文章属于一个分类
+type Category struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `gorm:"not null;size:50" json:"name"`
+	Slug      string     `gorm:"uniqueIndex;not null;size:60" json:"slug"`
+	ParentID  *uint      `gorm:"index" json:"parent_id"`
+	Parent    *Category  `gorm:"foreignKey:ParentID" json:"-"`
+	Children  []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ErrCategoryNotFound 引用的分类不存在
+var ErrCategoryNotFound = errors.New("category not found")
+
+// CheckCategory 校验分类是否存在，id 为 nil 时不检查
+func CheckCategory(db *gorm.DB, id *uint) error {
+	if id == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&Category{}).Where("id = ?", *id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// CategoryTree 加载全部分类并组装成树
+func CategoryTree(db *gorm.DB) ([]Category, error) {
+	var all []Category
+	if err := db.Order("name asc").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint)
+	byID := make(map[uint]*Category, len(all))
+	var roots []uint
+	for i := range all {
+		byID[all[i].ID] = &all[i]
+		if all[i].ParentID == nil {
+			roots = append(roots, all[i].ID)
+		} else {
+			children[*all[i].ParentID] = append(children[*all[i].ParentID], all[i].ID)
+		}
+	}
+
+	var build func(id uint) Category
+	build = func(id uint) Category {
+		node := *byID[id]
+		for _, childID := range children[id] {
+			node.Children = append(node.Children, build(childID))
+		}
+		return node
+	}
+
+	tree := make([]Category, 0, len(roots))
+	for _, id := range roots {
+		tree = append(tree, build(id))
+	}
+	return tree, nil
+}
+
+// CategoryDescendantIDs 返回分类自身及其所有子孙分类的ID
+func CategoryDescendantIDs(db *gorm.DB, rootID uint) ([]uint, error) {
+	var all []Category
+	if err := db.Select("id", "parent_id").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint)
+	for _, c := range all {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
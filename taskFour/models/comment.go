@@ -1,15 +1,148 @@
 package models
 
 import (
+	"errors"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusSpam     = "spam"
 )
 
+// Comment 文章评论，ParentID 不为空时是对另一条评论的回复
 type Comment struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
-	User      User      `gorm:"foreignKey:UserID" json:"user"`
-	PostID    uint      `gorm:"not null" json:"post_id"`
-	Post      Post      `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"-"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Content        string     `gorm:"type:text;not null" json:"content"`
+	UserID         uint       `gorm:"not null" json:"user_id"`
+	User           *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	PostID         uint       `gorm:"not null" json:"post_id"`
+	Post           Post       `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"-"`
+	ParentID       *uint      `gorm:"index" json:"parent_id"`
+	Depth          int        `gorm:"not null;default:0" json:"depth"`
+	Deleted        bool       `gorm:"not null;default:false" json:"deleted"`
+	Status         string     `gorm:"not null;default:approved;index;size:20" json:"status"`
+	ModerationNote string     `gorm:"size:255" json:"moderation_note,omitempty"`
+	Replies        []Comment  `gorm:"-" json:"replies,omitempty"`
+	EditedAt       *time.Time `json:"edited_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AfterSave GORM钩子，同步全文索引
+func (c *Comment) AfterSave(tx *gorm.DB) error {
+	if !searchEnabled(tx) || c.ID == 0 || c.Content == "" || c.Deleted {
+		return nil
+	}
+	return indexComment(tx, c)
+}
+
+// AfterDelete GORM钩子，删除评论的全文索引
+func (c *Comment) AfterDelete(tx *gorm.DB) error {
+	if !searchEnabled(tx) || c.ID == 0 {
+		return nil
+	}
+	return unindexComment(tx, c.ID)
+}
+
+// ApprovedComments 只查询审核通过的评论
+func ApprovedComments(db *gorm.DB) *gorm.DB {
+	return db.Where("comments.status = ?", CommentStatusApproved)
+}
+
+// Redact 隐藏已删除评论的作者，内容在删除时已经清空
+func (c *Comment) Redact() {
+	if c.Deleted {
+		c.User = nil
+	}
+}
+
+// DeleteComment 删除评论。仍有回复的评论保留为墓碑（清空内容并标记 deleted），
+// 避免回复失去上下文；没有回复的评论直接删除，并向上清理已经没有回复的墓碑
+func DeleteComment(tx *gorm.DB, c *Comment) error {
+	var replies int64
+	if err := tx.Model(&Comment{}).Where("parent_id = ?", c.ID).Count(&replies).Error; err != nil {
+		return err
+	}
+
+	if replies > 0 {
+		if err := tx.Model(c).Updates(map[string]interface{}{"content": "", "deleted": true}).Error; err != nil {
+			return err
+		}
+		if !searchEnabled(tx) {
+			return nil
+		}
+		return unindexComment(tx, c.ID)
+	}
+
+	if err := tx.Delete(c).Error; err != nil {
+		return err
+	}
+	if c.ParentID == nil {
+		return nil
+	}
+
+	var parent Comment
+	err := tx.Where("id = ? AND deleted = ?", *c.ParentID, true).First(&parent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return DeleteComment(tx, &parent)
+}
+
+// CommentTree 把评论组装成树，顶级评论和同一评论下的回复都保持在 comments 中的顺序
+func CommentTree(comments []Comment) []Comment {
+	children := make(map[uint][]int)
+	byID := make(map[uint]bool, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = true
+	}
+
+	var roots []int
+	for i, c := range comments {
+		// 父评论不在列表中时按顶级评论处理
+		if c.ParentID != nil && byID[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var build func(i int) Comment
+	build = func(i int) Comment {
+		node := comments[i]
+		node.Replies = nil
+		for _, j := range children[node.ID] {
+			node.Replies = append(node.Replies, build(j))
+		}
+		return node
+	}
+
+	tree := make([]Comment, 0, len(roots))
+	for _, i := range roots {
+		tree = append(tree, build(i))
+	}
+	return tree
+}
+
+// FlattenCommentTree 按深度优先顺序展开评论树，每条评论通过 Depth 表示层级
+func FlattenCommentTree(tree []Comment) []Comment {
+	flat := make([]Comment, 0, len(tree))
+	var walk func(nodes []Comment)
+	walk = func(nodes []Comment) {
+		for _, node := range nodes {
+			replies := node.Replies
+			node.Replies = nil
+			flat = append(flat, node)
+			walk(replies)
+		}
+	}
+	walk(tree)
+	return flat
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 身份类型
+const (
+	IdentityPassword = "password"
+	IdentityEmail    = "email"
+	IdentityEthereum = "ethereum"
+)
+
+// UserIdentity 账号关联的登录身份，一个账号可以有密码、多个邮箱和多个钱包地址
+// 密码身份的 Subject 为用户名，密码哈希仍保存在 users 表
+type UserIdentity struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Provider   string     `gorm:"not null;size:20;uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject    string     `gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject" json:"subject"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsLoginMethod 是否可以用来登录，邮箱身份只用于联系和找回
+func (i *UserIdentity) IsLoginMethod() bool {
+	return i.Provider == IdentityPassword || i.Provider == IdentityEthereum
+}
+
+// ReleaseUnverifiedEmail 删除任意账号上未验证的 email 身份。未验证的邮箱不占用地址，
+// 注册或其他账号添加同一邮箱时替换，防止抢先添加他人的邮箱阻止其注册
+func ReleaseUnverifiedEmail(tx *gorm.DB, email string) error {
+	return tx.Where("provider = ? AND subject = ? AND verified_at IS NULL", IdentityEmail, email).Delete(&UserIdentity{}).Error
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// AuthNonce 钱包登录使用的一次性随机数，防止签名被重放
+type AuthNonce struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Nonce     string     `gorm:"uniqueIndex;not null;size:32" json:"nonce"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
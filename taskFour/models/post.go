@@ -2,15 +2,108 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// 文章状态
+const (
+	PostStatusDraft     = "draft"
+	PostStatusPublished = "published"
+	PostStatusScheduled = "scheduled"
+	PostStatusArchived  = "archived"
 )
 
 type Post struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Title     string    `gorm:"not null;size:200" json:"title"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
-	User      User      `gorm:"foreignKey:UserID" json:"user"`
-	Comments  []Comment `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"comments,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Title       string     `gorm:"not null;size:200" json:"title"`
+	Content     string     `gorm:"type:text;not null" json:"content"`
+	Status      string     `gorm:"not null;size:20;default:published;index" json:"status"`
+	PublishedAt *time.Time `gorm:"index" json:"published_at"`
+	UserID      uint       `gorm:"not null" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID" json:"user"`
+	CategoryID  *uint      `gorm:"index" json:"category_id"`
+	// Version 版本号，每次修改加一，用于乐观并发控制（ETag / If-Match）
+	Version  uint      `gorm:"not null;default:1" json:"version"`
+	Category *Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"category,omitempty"`
+	Tags     []Tag     `gorm:"many2many:post_tags" json:"tags"`
+	Comments []Comment `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"comments,omitempty"`
+	// CommentCount 公开的评论数（审核通过且未删除），由 PostRelations 查询，不保存
+	CommentCount int64     `gorm:"->;-:migration" json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsVisible 文章是否对所有人可见：已发布，或定时发布的时间已到
+func (p *Post) IsVisible(now time.Time) bool {
+	switch p.Status {
+	case PostStatusPublished:
+		return true
+	case PostStatusScheduled:
+		return p.PublishedAt != nil && !p.PublishedAt.After(now)
+	}
+	return false
+}
+
+// VisiblePosts 只查询公开可见的文章，定时发布的文章到点即可见，不依赖调度器的执行时机
+func VisiblePosts(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		query, args := visiblePostsCondition(now)
+		return db.Where(query, args...)
+	}
+}
+
+func visiblePostsCondition(now time.Time) (string, []interface{}) {
+	return "posts.status = ? OR (posts.status = ? AND posts.published_at <= ?)",
+		[]interface{}{PostStatusPublished, PostStatusScheduled, now.UTC()}
+}
+
+// PostCommentCount 统计文章公开评论数的子查询，用于查询 CommentCount 和按评论数排序
+const PostCommentCount = "(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.status = '" +
+	CommentStatusApproved + "' AND NOT comments.deleted)"
+
+// PostRelations 预加载文章的作者、分类和标签，并查询评论数
+func PostRelations(db *gorm.DB) *gorm.DB {
+	return db.Select("posts.*, " + PostCommentCount + " AS comment_count").
+		Preload("User").Preload("Category").Preload("Tags")
+}
+
+// NextVersion 修改文章时版本号加一，所有修改文章的 UPDATE 都要带上
+var NextVersion = gorm.Expr("version + 1")
+
+// PublishDuePosts 将到期的定时文章改为已发布，返回更新的数量
+func PublishDuePosts(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Model(&Post{}).
+		Where("status = ? AND published_at <= ?", PostStatusScheduled, now.UTC()).
+		Updates(map[string]interface{}{"status": PostStatusPublished, "version": NextVersion})
+	return result.RowsAffected, result.Error
+}
+
+// NextScheduledAt 返回下一篇定时文章的发布时间，没有时返回 nil
+func NextScheduledAt(db *gorm.DB) (*time.Time, error) {
+	var post Post
+	err := db.Select("id", "published_at").
+		Where("status = ?", PostStatusScheduled).
+		Order("published_at asc").
+		Limit(1).Find(&post).Error
+	if err != nil || post.ID == 0 {
+		return nil, err
+	}
+	return post.PublishedAt, nil
+}
+
+// AfterSave GORM钩子，同步全文索引
+func (p *Post) AfterSave(tx *gorm.DB) error {
+	if !searchEnabled(tx) || p.ID == 0 || p.Title == "" {
+		return nil
+	}
+	return indexPost(tx, p)
+}
+
+// BeforeDelete GORM钩子，删除文章及其评论的全文索引
+func (p *Post) BeforeDelete(tx *gorm.DB) error {
+	if !searchEnabled(tx) || p.ID == 0 {
+		return nil
+	}
+	return unindexPost(tx, p.ID)
 }
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostRevision 文章的历史版本，每次修改标题或内容都会保存一份完整快照
+type PostRevision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	PostID       uint      `gorm:"not null;uniqueIndex:idx_post_revision" json:"post_id"`
+	Post         Post      `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"-"`
+	Rev          int       `gorm:"not null;uniqueIndex:idx_post_revision" json:"rev"`
+	EditorID     uint      `gorm:"not null" json:"editor_id"`
+	Editor       User      `gorm:"foreignKey:EditorID" json:"editor"`
+	Title        string    `gorm:"not null;size:200" json:"title"`
+	Content      string    `gorm:"type:text;not null" json:"content"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreateRevision 为文章当前内容保存一个新版本，需要在事务中调用
+// 升级前创建的文章还没有版本记录时，先把修改前的内容保存为第 1 版
+func CreateRevision(tx *gorm.DB, before, after *Post, editorID uint, restoredFrom *int) (*PostRevision, error) {
+	var latest int
+	if err := tx.Model(&PostRevision{}).Where("post_id = ?", after.ID).
+		Select("COALESCE(MAX(rev), 0)").Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+
+	if latest == 0 && before != nil {
+		base := PostRevision{
+			PostID:    before.ID,
+			Rev:       1,
+			EditorID:  before.UserID,
+			Title:     before.Title,
+			Content:   before.Content,
+			CreatedAt: before.UpdatedAt,
+		}
+		if err := tx.Create(&base).Error; err != nil {
+			return nil, err
+		}
+		latest = 1
+	}
+
+	revision := PostRevision{
+		PostID:       after.ID,
+		Rev:          latest + 1,
+		EditorID:     editorID,
+		Title:        after.Title,
+		Content:      after.Content,
+		RestoredFrom: restoredFrom,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+// 用户角色
+const (
+	RoleUser   = "user"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// 权限标识，格式为 资源:操作[:范围]
+const (
+	PermPostCreate      = "post:create"
+	PermPostUpdateAny   = "post:update:any"
+	PermPostDeleteAny   = "post:delete:any"
+	PermCommentCreate   = "comment:create"
+	PermCommentModerate = "comment:moderate"
+	PermUserManage      = "user:manage"
+	PermTaxonomyManage  = "taxonomy:manage"
+)
+
+// rolePermissions 角色权限表，高级角色包含低级角色的全部权限
+var rolePermissions = map[string][]string{
+	RoleUser: {
+		PermPostCreate,
+		PermCommentCreate,
+	},
+	RoleEditor: {
+		PermPostCreate,
+		PermCommentCreate,
+		PermPostUpdateAny,
+		PermPostDeleteAny,
+		PermCommentModerate,
+		PermTaxonomyManage,
+	},
+	RoleAdmin: {
+		PermPostCreate,
+		PermCommentCreate,
+		PermPostUpdateAny,
+		PermPostDeleteAny,
+		PermCommentModerate,
+		PermTaxonomyManage,
+		PermUserManage,
+	},
+}
+
+// ValidRole 判断角色是否存在
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 判断角色是否拥有指定权限
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissions 返回角色拥有的权限列表
+func RolePermissions(role string) []string {
+	return append([]string(nil), rolePermissions[role]...)
+}
@@ -0,0 +1,310 @@
+package models
+
+import (
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// SQLite 下全文检索使用 FTS5。unicode61 分词器会把连续的中日韩文字当成一个词，
+// 所以写入索引前在每个 CJK 字符两侧插入私有区字符 U+E000 并把它声明为分隔符，
+// 按单字建立索引；查询时把中文词转换成相邻单字组成的短语，读取摘要时再去掉分隔符。
+const (
+	cjkSeparator = '\ue000'
+	markOpen     = "\ue001"
+	markClose    = "\ue002"
+)
+
+var ftsTables = []string{
+	"CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(title, content, tokenize=\"unicode61 remove_diacritics 2 separators '" + string(cjkSeparator) + "'\")",
+	"CREATE VIRTUAL TABLE IF NOT EXISTS comments_fts USING fts5(content, tokenize=\"unicode61 remove_diacritics 2 separators '" + string(cjkSeparator) + "'\")",
+}
+
+// searchReady 全文索引是否已经初始化，未初始化时模型钩子不写索引
+var searchReady bool
+
+// PostSearchResult 文章搜索结果
+type PostSearchResult struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	TitleHTML   string     `json:"title_highlight"`
+	Snippet     string     `json:"snippet"`
+	UserID      uint       `json:"user_id"`
+	PublishedAt *time.Time `json:"published_at"`
+	Score       float64    `json:"score"`
+}
+
+// CommentSearchResult 评论搜索结果
+type CommentSearchResult struct {
+	ID        uint      `json:"id"`
+	PostID    uint      `json:"post_id"`
+	UserID    uint      `json:"user_id"`
+	Snippet   string    `json:"snippet"`
+	CreatedAt time.Time `json:"created_at"`
+	Score     float64   `json:"score"`
+}
+
+// SetupSearch 创建全文索引表，首次创建时为已有数据建立索引。
+// MySQL 和 PostgreSQL 不建立索引，搜索时直接用 LIKE 匹配（见 search_like.go）
+func SetupSearch(db *gorm.DB) error {
+	if !usesFTS(db) {
+		return nil
+	}
+
+	var count int64
+	if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('posts_fts', 'comments_fts')").Scan(&count).Error; err != nil {
+		return err
+	}
+
+	for _, stmt := range ftsTables {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	searchReady = true
+
+	if count == int64(len(ftsTables)) {
+		return nil
+	}
+	return RebuildSearchIndex(db)
+}
+
+// RebuildSearchIndex 清空并重建全部全文索引
+func RebuildSearchIndex(db *gorm.DB) error {
+	if !usesFTS(db) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM posts_fts").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM comments_fts").Error; err != nil {
+			return err
+		}
+
+		var posts []Post
+		if err := tx.Select("id", "title", "content").FindInBatches(&posts, 200, func(batch *gorm.DB, _ int) error {
+			for i := range posts {
+				if err := indexPost(batch, &posts[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error; err != nil {
+			return err
+		}
+
+		var comments []Comment
+		return tx.Select("id", "content").FindInBatches(&comments, 200, func(batch *gorm.DB, _ int) error {
+			for i := range comments {
+				if err := indexComment(batch, &comments[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	})
+}
+
+// SearchPosts 搜索公开可见的文章，按 bm25 排序（标题权重更高）
+func SearchPosts(db *gorm.DB, query string, now time.Time, limit, offset int) ([]PostSearchResult, error) {
+	if !usesFTS(db) {
+		return searchPostsLike(db, query, now, limit, offset)
+	}
+
+	match := BuildMatchQuery(query)
+	if match == "" {
+		return []PostSearchResult{}, nil
+	}
+
+	results := []PostSearchResult{}
+	err := db.Table("posts_fts").
+		Select("posts.id, posts.title, posts.user_id, posts.published_at, "+
+			"highlight(posts_fts, 0, ?, ?) AS title_html, "+
+			"snippet(posts_fts, 1, ?, ?, '…', 32) AS snippet, "+
+			"bm25(posts_fts, 10.0, 1.0) AS score", markOpen, markClose, markOpen, markClose).
+		Joins("JOIN posts ON posts.id = posts_fts.rowid").
+		Where("posts_fts MATCH ?", match).
+		Scopes(VisiblePosts(now)).
+		Order("score").
+		Limit(limit).Offset(offset).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].TitleHTML = renderHighlight(results[i].TitleHTML)
+		results[i].Snippet = renderHighlight(results[i].Snippet)
+	}
+	return results, nil
+}
+
+// SearchComments 搜索公开文章下审核通过的评论
+func SearchComments(db *gorm.DB, query string, now time.Time, limit, offset int) ([]CommentSearchResult, error) {
+	if !usesFTS(db) {
+		return searchCommentsLike(db, query, now, limit, offset)
+	}
+
+	match := BuildMatchQuery(query)
+	if match == "" {
+		return []CommentSearchResult{}, nil
+	}
+
+	results := []CommentSearchResult{}
+	err := db.Table("comments_fts").
+		Select("comments.id, comments.post_id, comments.user_id, comments.created_at, "+
+			"snippet(comments_fts, 0, ?, ?, '…', 32) AS snippet, "+
+			"bm25(comments_fts) AS score", markOpen, markClose).
+		Joins("JOIN comments ON comments.id = comments_fts.rowid").
+		Joins("JOIN posts ON posts.id = comments.post_id").
+		Where("comments_fts MATCH ?", match).
+		Scopes(ApprovedComments, VisiblePosts(now)).
+		Order("score").
+		Limit(limit).Offset(offset).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Snippet = renderHighlight(results[i].Snippet)
+	}
+	return results, nil
+}
+
+// BuildMatchQuery 把用户输入转换成安全的 FTS5 查询表达式
+// 支持 "短语" 和 前缀* 查询，多个词之间为 AND 关系，其余语法字符一律按普通文本处理
+func BuildMatchQuery(input string) string {
+	var terms []string
+	for _, t := range parseSearchTerms(input) {
+		tokens := strings.FieldsFunc(segmentCJK(t.text), func(r rune) bool {
+			return r == cjkSeparator || !(unicode.IsLetter(r) || unicode.IsNumber(r))
+		})
+		if len(tokens) == 0 {
+			continue
+		}
+		term := `"` + strings.Join(tokens, " ") + `"`
+		if t.prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchTerm 用户输入中的一个词或短语
+type searchTerm struct {
+	text   string
+	prefix bool
+}
+
+// parseSearchTerms 拆分用户输入，双引号内为短语，以 * 结尾表示前缀查询
+func parseSearchTerms(input string) []searchTerm {
+	var terms []searchTerm
+	rest := input
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		if rest == "" {
+			break
+		}
+
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				terms = append(terms, searchTerm{text: rest[1:]})
+				break
+			}
+			phrase := rest[1 : end+1]
+			rest = rest[end+2:]
+			prefix := strings.HasPrefix(rest, "*")
+			if prefix {
+				rest = rest[1:]
+			}
+			terms = append(terms, searchTerm{text: phrase, prefix: prefix})
+			continue
+		}
+
+		end := strings.IndexAny(rest, " \t\r\n\"")
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+		terms = append(terms, searchTerm{text: strings.TrimSuffix(word, "*"), prefix: strings.HasSuffix(word, "*")})
+	}
+	return terms
+}
+
+func indexPost(tx *gorm.DB, p *Post) error {
+	if err := tx.Exec("DELETE FROM posts_fts WHERE rowid = ?", p.ID).Error; err != nil {
+		return err
+	}
+	return tx.Exec("INSERT INTO posts_fts(rowid, title, content) VALUES (?, ?, ?)",
+		p.ID, segmentCJK(p.Title), segmentCJK(p.Content)).Error
+}
+
+func unindexPost(tx *gorm.DB, postID uint) error {
+	if err := tx.Exec("DELETE FROM comments_fts WHERE rowid IN (SELECT id FROM comments WHERE post_id = ?)", postID).Error; err != nil {
+		return err
+	}
+	return tx.Exec("DELETE FROM posts_fts WHERE rowid = ?", postID).Error
+}
+
+func indexComment(tx *gorm.DB, c *Comment) error {
+	if err := tx.Exec("DELETE FROM comments_fts WHERE rowid = ?", c.ID).Error; err != nil {
+		return err
+	}
+	return tx.Exec("INSERT INTO comments_fts(rowid, content) VALUES (?, ?)",
+		c.ID, segmentCJK(c.Content)).Error
+}
+
+func unindexComment(tx *gorm.DB, commentID uint) error {
+	return tx.Exec("DELETE FROM comments_fts WHERE rowid = ?", commentID).Error
+}
+
+// segmentCJK 在每个中日韩字符两侧插入分隔符，使其按单字分词
+func segmentCJK(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		// 去掉原文中的私有区控制字符，保证摘要还原时不会出错
+		if strings.ContainsRune(string(cjkSeparator)+markOpen+markClose, r) {
+			continue
+		}
+		if isCJK(r) {
+			sb.WriteRune(cjkSeparator)
+			sb.WriteRune(r)
+			sb.WriteRune(cjkSeparator)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// renderHighlight 去掉分隔符并转义 HTML，命中部分用 <mark> 标记
+func renderHighlight(s string) string {
+	s = strings.ReplaceAll(s, string(cjkSeparator), "")
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, markOpen, "<mark>")
+	return strings.ReplaceAll(s, markClose, "</mark>")
+}
+
+// usesFTS 只有 SQLite 使用 FTS5 全文索引
+func usesFTS(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
+
+// searchEnabled 模型钩子是否需要同步全文索引
+func searchEnabled(tx *gorm.DB) bool {
+	return searchReady && usesFTS(tx)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQL 和 PostgreSQL 没有与 FTS5 等价的可移植中文分词，搜索时对每个关键词做
+// 不区分大小写的 LIKE 子串匹配（多个关键词为 AND 关系），高亮和摘要在程序中生成。
+// 得分沿用 bm25 的约定：越小越相关。文章的得分与 SQL 中的排序一致，每个关键词命中标题计 10 分、
+// 命中正文计 1 分；评论都包含全部关键词，得分相同，按时间倒序。
+
+// snippetRunes 摘要的长度（字符数）
+const snippetRunes = 64
+
+// likeEscape LIKE 的转义字符，不用反斜杠，避免 MySQL 字符串字面量中的二次转义
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// likeContains 包含 keyword 的 LIKE 模式，转义其中的通配符，需要配合 ESCAPE '!' 使用
+func likeContains(keyword string) string {
+	return "%" + likeEscaper.Replace(keyword) + "%"
+}
+
+type postLikeRow struct {
+	ID          uint
+	Title       string
+	Content     string
+	UserID      uint
+	PublishedAt *time.Time
+}
+
+type commentLikeRow struct {
+	ID        uint
+	PostID    uint
+	UserID    uint
+	Content   string
+	CreatedAt time.Time
+}
+
+func searchPostsLike(db *gorm.DB, query string, now time.Time, limit, offset int) ([]PostSearchResult, error) {
+	keywords := searchKeywords(query)
+	if len(keywords) == 0 {
+		return []PostSearchResult{}, nil
+	}
+
+	tx := db.Model(&Post{}).
+		Select("posts.id, posts.title, posts.content, posts.user_id, posts.published_at").
+		Scopes(VisiblePosts(now))
+	hits := make([]string, 0, len(keywords))
+	var orderArgs []interface{}
+	for _, keyword := range keywords {
+		pattern := likeContains(keyword)
+		tx = tx.Where("(LOWER(posts.title) LIKE ? ESCAPE '"+likeEscape+"' OR LOWER(posts.content) LIKE ? ESCAPE '"+likeEscape+"')", pattern, pattern)
+		hits = append(hits, "CASE WHEN LOWER(posts.title) LIKE ? ESCAPE '"+likeEscape+"' THEN 10 ELSE 0 END",
+			"CASE WHEN LOWER(posts.content) LIKE ? ESCAPE '"+likeEscape+"' THEN 1 ELSE 0 END")
+		orderArgs = append(orderArgs, pattern, pattern)
+	}
+
+	var rows []postLikeRow
+	err := tx.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "(" + strings.Join(hits, " + ") + ") DESC, posts.published_at DESC, posts.id DESC",
+		Vars:               orderArgs,
+		WithoutParentheses: true,
+	}}).
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PostSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, PostSearchResult{
+			ID:          row.ID,
+			Title:       row.Title,
+			TitleHTML:   highlightKeywords(row.Title, keywords),
+			Snippet:     snippetKeywords(row.Content, keywords),
+			UserID:      row.UserID,
+			PublishedAt: row.PublishedAt,
+			Score:       -float64(postLikeScore(row.Title, row.Content, keywords)),
+		})
+	}
+	return results, nil
+}
+
+func searchCommentsLike(db *gorm.DB, query string, now time.Time, limit, offset int) ([]CommentSearchResult, error) {
+	keywords := searchKeywords(query)
+	if len(keywords) == 0 {
+		return []CommentSearchResult{}, nil
+	}
+
+	tx := db.Model(&Comment{}).
+		Select("comments.id, comments.post_id, comments.user_id, comments.content, comments.created_at").
+		Joins("JOIN posts ON posts.id = comments.post_id").
+		Where("comments.deleted = ?", false).
+		Scopes(ApprovedComments, VisiblePosts(now))
+	for _, keyword := range keywords {
+		tx = tx.Where("LOWER(comments.content) LIKE ? ESCAPE '"+likeEscape+"'", likeContains(keyword))
+	}
+
+	var rows []commentLikeRow
+	if err := tx.Order("comments.created_at desc, comments.id desc").Limit(limit).Offset(offset).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]CommentSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, CommentSearchResult{
+			ID:        row.ID,
+			PostID:    row.PostID,
+			UserID:    row.UserID,
+			Snippet:   snippetKeywords(row.Content, keywords),
+			CreatedAt: row.CreatedAt,
+			Score:     -float64(len(keywords)),
+		})
+	}
+	return results, nil
+}
+
+// searchKeywords 把用户输入拆成小写关键词，只保留字母和数字，去掉重复
+func searchKeywords(input string) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, t := range parseSearchTerms(input) {
+		for _, keyword := range strings.FieldsFunc(strings.ToLower(t.text), func(r rune) bool {
+			return !(unicode.IsLetter(r) || unicode.IsNumber(r))
+		}) {
+			if !seen[keyword] {
+				seen[keyword] = true
+				keywords = append(keywords, keyword)
+			}
+		}
+	}
+	return keywords
+}
+
+// matchRanges 标记文本中命中关键词的字符，比较时逐字符转小写，保证下标与原文一致
+func matchRanges(text []rune, keywords []string) []bool {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(text))
+	for _, keyword := range keywords {
+		k := []rune(keyword)
+		for i := 0; i+len(k) <= len(lower); i++ {
+			if string(lower[i:i+len(k)]) == keyword {
+				for j := i; j < i+len(k); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+	return marked
+}
+
+// postLikeScore 与 searchPostsLike 中 ORDER BY 的表达式相同：每个关键词命中标题计 10 分，命中正文计 1 分
+func postLikeScore(title, content string, keywords []string) int {
+	title, content = strings.ToLower(title), strings.ToLower(content)
+	score := 0
+	for _, keyword := range keywords {
+		if strings.Contains(title, keyword) {
+			score += 10
+		}
+		if strings.Contains(content, keyword) {
+			score++
+		}
+	}
+	return score
+}
+
+// highlightKeywords 转义 HTML，命中部分用 <mark> 标记
+func highlightKeywords(text string, keywords []string) string {
+	runes := []rune(text)
+	return renderMarked(runes, matchRanges(runes, keywords))
+}
+
+// snippetKeywords 截取第一个命中位置附近的一段文字并高亮
+func snippetKeywords(text string, keywords []string) string {
+	runes := []rune(text)
+	marked := matchRanges(runes, keywords)
+
+	start := 0
+	for i, m := range marked {
+		if m {
+			start = max(0, i-snippetRunes/4)
+			break
+		}
+	}
+	end := min(len(runes), start+snippetRunes)
+
+	s := renderMarked(runes[start:end], marked[start:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
+
+func renderMarked(runes []rune, marked []bool) string {
+	var sb strings.Builder
+	open := false
+	for i, r := range runes {
+		if marked[i] != open {
+			if marked[i] {
+				sb.WriteString("<mark>")
+			} else {
+				sb.WriteString("</mark>")
+			}
+			open = marked[i]
+		}
+		sb.WriteString(html.EscapeString(string(r)))
+	}
+	if open {
+		sb.WriteString("</mark>")
+	}
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLikeContainsEscapesWildcards(t *testing.T) {
+	if got, want := likeContains("a%b_c!"), "%a!%b!_c!!%"; got != want {
+		t.Fatalf("likeContains = %q, want %q", got, want)
+	}
+}
+
+func TestSearchPostsLikeScoreMatchesOrder(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&User{}, &Post{}); err != nil {
+		t.Fatal(err)
+	}
+	author := User{Username: "author"}
+	if err := db.Create(&author).Error; err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	posts := []Post{
+		{Title: "正文很多次 golang", Content: "golang golang golang golang golang golang golang golang golang golang golang"},
+		{Title: "其他", Content: "golang 入门"},
+		{Title: "不相关", Content: "rust"},
+	}
+	for i := range posts {
+		posts[i].UserID, posts[i].Status, posts[i].PublishedAt = author.ID, PostStatusPublished, &now
+		if err := db.Create(&posts[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := searchPostsLike(db, "golang", now, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != posts[0].ID || results[0].Score != -11 || results[1].Score != -1 {
+		t.Fatalf("results = %+v", results)
+	}
+}
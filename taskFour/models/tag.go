@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"taskFour/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tag 文章标签，与文章是多对多关系
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"uniqueIndex;not null;size:50" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null;size:60" json:"slug"`
+	Posts     []Post    `gorm:"many2many:post_tags" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagWithCount 带文章数量的标签
+type TagWithCount struct {
+	Tag
+	PostCount int64 `json:"post_count"`
+}
+
+// ErrInvalidTagName 标签名生成的 slug 为空
+var ErrInvalidTagName = errors.New("invalid tag name")
+
+// FindOrCreateTags 按 slug 查找标签，不存在时创建，返回顺序与去重后的输入一致
+func FindOrCreateTags(tx *gorm.DB, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := utils.Slugify(name)
+		if slug == "" {
+			return nil, ErrInvalidTagName
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		tag := Tag{Name: name, Slug: slug}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("slug = ?", slug).First(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// TagsWithCount 返回所有标签及其公开文章数量
+func TagsWithCount(db *gorm.DB, now time.Time) ([]TagWithCount, error) {
+	visible, args := visiblePostsCondition(now)
+
+	tags := []TagWithCount{}
+	err := db.Model(&Tag{}).
+		Select("tags.*, COUNT(posts.id) AS post_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND ("+visible+")", args...).
+		Group("tags.id").
+		Order("post_count desc, tags.name asc").
+		Scan(&tags).Error
+	return tags, err
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，只保存令牌的哈希值
+// 同一次登录轮换出来的令牌属于同一个 FamilyID，检测到重放时整组吊销
+type RefreshToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	TokenHash  string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	FamilyID   string     `gorm:"index;not null;size:36" json:"family_id"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uint      `json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RevokedToken 已吊销的访问令牌（按 jti 记录），过期后可以清理
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JTI       string    `gorm:"column:jti;uniqueIndex;not null;size:36" json:"jti"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -8,18 +8,23 @@ import (
 )
 
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"uniqueIndex;not null;size:100" json:"username"`
-	Password  string    `gorm:"not null" json:"-"`
-	Email     string    `gorm:"uniqueIndex;not null" json:"email"`
-	Posts     []Post    `gorm:"foreignKey:UserID" json:"-"`
-	Comments  []Comment `gorm:"foreignKey:UserID" json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	Username   string         `gorm:"uniqueIndex;not null;size:100" json:"username"`
+	Password   string         `gorm:"not null" json:"-"`
+	Email      *string        `gorm:"uniqueIndex" json:"email"`
+	Role       string         `gorm:"not null;size:20;default:user" json:"role"`
+	Posts      []Post         `gorm:"foreignKey:UserID" json:"-"`
+	Comments   []Comment      `gorm:"foreignKey:UserID" json:"-"`
+	Identities []UserIdentity `gorm:"foreignKey:UserID" json:"-"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
 }
 
-// HashPassword 加密密码
+// HashPassword 加密密码，钱包用户没有密码时跳过
 func (u *User) HashPassword() error {
+	if u.Password == "" {
+		return nil
+	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -30,10 +35,40 @@ func (u *User) HashPassword() error {
 
 // CheckPassword 验证密码
 func (u *User) CheckPassword(password string) error {
+	if u.Password == "" {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
 
+// HasPermission 判断用户角色是否拥有指定权限
+func (u *User) HasPermission(permission string) bool {
+	return HasPermission(u.Role, permission)
+}
+
 // BeforeCreate GORM钩子，在创建前加密密码
 func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
 	return u.HashPassword()
 }
+
+// AfterCreate GORM钩子，注册时同步创建密码和邮箱身份
+func (u *User) AfterCreate(tx *gorm.DB) error {
+	var identities []UserIdentity
+	if u.Password != "" {
+		now := time.Now().UTC()
+		identities = append(identities, UserIdentity{UserID: u.ID, Provider: IdentityPassword, Subject: u.Username, VerifiedAt: &now})
+	}
+	if u.Email != nil && *u.Email != "" {
+		if err := ReleaseUnverifiedEmail(tx, *u.Email); err != nil {
+			return err
+		}
+		identities = append(identities, UserIdentity{UserID: u.ID, Provider: IdentityEmail, Subject: *u.Email})
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+	return tx.Create(&identities).Error
+}
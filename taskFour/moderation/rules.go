@@ -0,0 +1,93 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"taskFour/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+
+// LinkCountRule 链接数超过 Max 的评论进入待审核队列
+type LinkCountRule struct {
+	Max int
+}
+
+// Check 实现 SpamChecker
+func (r LinkCountRule) Check(_ *gorm.DB, comment *models.Comment, _ *models.User) (Result, error) {
+	if n := len(linkPattern.FindAllStringIndex(comment.Content, -1)); n > r.Max {
+		return Result{Status: models.CommentStatusPending, Reason: fmt.Sprintf("too many links (%d)", n)}, nil
+	}
+	return Approved, nil
+}
+
+// BlocklistRule 包含屏蔽词（不区分大小写）的评论标记为垃圾
+type BlocklistRule struct {
+	Words []string
+}
+
+// Check 实现 SpamChecker
+func (r BlocklistRule) Check(_ *gorm.DB, comment *models.Comment, _ *models.User) (Result, error) {
+	content := strings.ToLower(comment.Content)
+	for _, word := range r.Words {
+		if word != "" && strings.Contains(content, strings.ToLower(word)) {
+			return Result{Status: models.CommentStatusSpam, Reason: "blocklisted word"}, nil
+		}
+	}
+	return Approved, nil
+}
+
+// RepeatContentRule 同一用户在 Window 内重复发表相同内容时标记为垃圾
+type RepeatContentRule struct {
+	Window time.Duration
+}
+
+// Check 实现 SpamChecker
+func (r RepeatContentRule) Check(db *gorm.DB, comment *models.Comment, _ *models.User) (Result, error) {
+	if r.Window <= 0 {
+		return Approved, nil
+	}
+
+	var count int64
+	err := db.Model(&models.Comment{}).
+		Where("user_id = ? AND id <> ? AND content = ? AND created_at > ?",
+			comment.UserID, comment.ID, comment.Content, time.Now().UTC().Add(-r.Window)).
+		Count(&count).Error
+	if err != nil {
+		return Result{}, err
+	}
+	if count > 0 {
+		return Result{Status: models.CommentStatusSpam, Reason: "repeated content"}, nil
+	}
+	return Approved, nil
+}
+
+// NewAccountRule 注册不满 MinAge 的账号在 Window 内最多直接发布 Limit 条评论，超出后进入待审核队列
+type NewAccountRule struct {
+	MinAge time.Duration
+	Limit  int
+	Window time.Duration
+}
+
+// Check 实现 SpamChecker
+func (r NewAccountRule) Check(db *gorm.DB, comment *models.Comment, author *models.User) (Result, error) {
+	if author == nil || r.MinAge <= 0 || time.Since(author.CreatedAt) >= r.MinAge {
+		return Approved, nil
+	}
+
+	var count int64
+	err := db.Model(&models.Comment{}).
+		Where("user_id = ? AND id <> ? AND created_at > ?", author.ID, comment.ID, time.Now().UTC().Add(-r.Window)).
+		Count(&count).Error
+	if err != nil {
+		return Result{}, err
+	}
+	if count >= int64(r.Limit) {
+		return Result{Status: models.CommentStatusPending, Reason: "new account rate limit"}, nil
+	}
+	return Approved, nil
+}
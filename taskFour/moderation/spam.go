@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"context"
+
+	"taskFour/config"
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// Result 反垃圾检查结果，Status 为 models.CommentStatus* 之一
+type Result struct {
+	Status string
+	Reason string
+}
+
+// Approved 检查通过
+var Approved = Result{Status: models.CommentStatusApproved}
+
+// SpamChecker 评论反垃圾检查器，在评论写入前调用
+type SpamChecker interface {
+	Check(db *gorm.DB, comment *models.Comment, author *models.User) (Result, error)
+}
+
+// CheckerFunc 把普通函数适配为 SpamChecker
+type CheckerFunc func(db *gorm.DB, comment *models.Comment, author *models.User) (Result, error)
+
+// Check 实现 SpamChecker
+func (f CheckerFunc) Check(db *gorm.DB, comment *models.Comment, author *models.User) (Result, error) {
+	return f(db, comment, author)
+}
+
+// Chain 依次执行多个检查器，返回最严重的结果（spam > pending > approved），命中 spam 后不再继续
+type Chain []SpamChecker
+
+// Check 实现 SpamChecker
+func (ch Chain) Check(db *gorm.DB, comment *models.Comment, author *models.User) (Result, error) {
+	result := Approved
+	for _, checker := range ch {
+		r, err := checker.Check(db, comment, author)
+		if err != nil {
+			return Result{}, err
+		}
+		if severity(r.Status) > severity(result.Status) {
+			result = r
+		}
+		if result.Status == models.CommentStatusSpam {
+			break
+		}
+	}
+	return result, nil
+}
+
+func severity(status string) int {
+	switch status {
+	case models.CommentStatusSpam:
+		return 2
+	case models.CommentStatusPending:
+		return 1
+	}
+	return 0
+}
+
+// DefaultChecker 按配置组装内置规则
+func DefaultChecker() SpamChecker {
+	return Chain{
+		BlocklistRule{Words: config.SpamBlocklist},
+		LinkCountRule{Max: config.SpamMaxLinks},
+		RepeatContentRule{Window: config.SpamRepeatWindow},
+		NewAccountRule{
+			MinAge: config.SpamNewAccountAge,
+			Limit:  config.SpamNewAccountLimit,
+			Window: config.SpamNewAccountWindow,
+		},
+	}
+}
+
+// checker 通过 SetChecker 替换的检查器，为 nil 时每次按当前配置组装 DefaultChecker，
+// 这样 config.Apply 之后的配置才会生效
+var checker SpamChecker
+
+// SetChecker 替换全局使用的检查器，传入 nil 恢复为按配置组装的内置规则
+func SetChecker(c SpamChecker) {
+	checker = c
+}
+
+// Check 使用全局检查器检查评论
+func Check(db *gorm.DB, comment *models.Comment, author *models.User) (Result, error) {
+	c := checker
+	if c == nil {
+		c = DefaultChecker()
+	}
+	return c.Check(db, comment, author)
+}
+
+// CheckFunc 绑定了数据库的检查函数，服务层通过它检查评论而不直接依赖数据库
+type CheckFunc func(ctx context.Context, comment *models.Comment, author *models.User) (Result, error)
+
+// ForDB 使用全局检查器，查询在 db 上以请求的 context 执行
+func ForDB(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context, comment *models.Comment, author *models.User) (Result, error) {
+		return Check(db.WithContext(ctx), comment, author)
+	}
+}
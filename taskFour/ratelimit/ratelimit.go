@@ -0,0 +1,113 @@
+// Package ratelimit 令牌桶限流：桶容量为一个周期内允许的请求数，令牌按周期匀速补充。
+// 状态保存在 Store 中，单实例部署用 MemoryStore，多实例共享用 RedisStore
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit 每个 Period 允许 Requests 个请求，允许一次性用完（突发）
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit 解析 "请求数/周期" 格式，如 "10/1m"、"300/1h"；空字符串或 "0" 表示不限流
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+
+	count, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected requests/period such as 10/1m", s)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("invalid request count in rate limit %q", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d < time.Second {
+		return Limit{}, fmt.Errorf("invalid period in rate limit %q, must be at least 1s", s)
+	}
+	return Limit{Requests: requests, Period: d}, nil
+}
+
+// Enabled 是否限流
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// String 与 ParseLimit 的格式相同
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// rate 每纳秒补充的令牌数
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / float64(l.Period)
+}
+
+// Result 一次取令牌的结果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // 剩余令牌数（取整）
+	ResetAfter time.Duration // 桶重新装满所需的时间
+	RetryAfter time.Duration // 被拒绝时，下一个令牌补充所需的时间
+}
+
+// Store 保存令牌桶状态，Take 为 key 对应的桶取一个令牌，必须是原子的
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Pinger 依赖外部服务的存储实现，就绪检查通过 Ping 检查它是否可用
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// result 根据取令牌后剩余的令牌数计算返回给客户端的信息
+func result(limit Limit, allowed bool, tokens float64) Result {
+	rate := limit.rate()
+	r := Result{
+		Allowed:    allowed,
+		Limit:      limit.Requests,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration(math.Ceil((float64(limit.Requests) - tokens) / rate)),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+	return r
+}
+
+var store Store = NewMemoryStore()
+
+// SetStore 替换全局使用的存储
+func SetStore(s Store) {
+	store = s
+}
+
+// Ping 检查全局存储是否可用，存储不依赖外部服务（没有实现 Pinger）时 checked 为 false
+func Ping(ctx context.Context) (checked bool, err error) {
+	pinger, ok := store.(Pinger)
+	if !ok {
+		return false, nil
+	}
+	return true, pinger.Ping(ctx)
+}
+
+// Take 使用全局存储取令牌
+func Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return store.Take(ctx, key, limit, time.Now())
+}
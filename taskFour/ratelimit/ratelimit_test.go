@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseLimit(t *testing.T) {
+	valid := map[string]Limit{
+		"10/1m":    {Requests: 10, Period: time.Minute},
+		" 300/1h ": {Requests: 300, Period: time.Hour},
+		"":         {},
+		"0":        {},
+	}
+	for s, want := range valid {
+		got, err := ParseLimit(s)
+		if err != nil || got != want {
+			t.Errorf("ParseLimit(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"10", "ten/1m", "10/m", "-1/1m", "10/10ms"} {
+		if _, err := ParseLimit(s); err == nil {
+			t.Errorf("ParseLimit(%q) should fail", s)
+		}
+	}
+}
+
+// testStore 对所有存储运行同样的令牌桶行为测试
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	limit := Limit{Requests: 3, Period: 3 * time.Second} // 每秒补充一个令牌
+	now := time.Unix(1700000000, 0)
+
+	for i := 2; i >= 0; i-- {
+		r, err := store.Take(ctx, "ip:1.2.3.4", limit, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Allowed || r.Remaining != i || r.Limit != 3 {
+			t.Fatalf("request %d: %+v", 3-i, r)
+		}
+	}
+
+	r, err := store.Take(ctx, "ip:1.2.3.4", limit, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Allowed || r.Remaining != 0 || r.RetryAfter != time.Second || r.ResetAfter != 3*time.Second {
+		t.Fatalf("bucket should be empty: %+v", r)
+	}
+
+	// 其他 key 不受影响
+	if r, _ := store.Take(ctx, "user:1", limit, now); !r.Allowed {
+		t.Fatalf("separate key limited: %+v", r)
+	}
+
+	// 1.5 秒后补充了 1.5 个令牌
+	r, _ = store.Take(ctx, "ip:1.2.3.4", limit, now.Add(1500*time.Millisecond))
+	if !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("token not refilled: %+v", r)
+	}
+
+	// 很久之后桶最多装满
+	r, _ = store.Take(ctx, "ip:1.2.3.4", limit, now.Add(time.Hour))
+	if !r.Allowed || r.Remaining != 2 {
+		t.Fatalf("bucket should be capped at burst: %+v", r)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 1, Period: time.Second}
+	now := time.Unix(1700000000, 0)
+	store.Take(context.Background(), "a", limit, now)
+	store.Take(context.Background(), "b", limit, now.Add(2*time.Minute))
+	if _, ok := store.buckets["a"]; ok || len(store.buckets) != 1 {
+		t.Fatalf("full bucket not swept: %v", store.buckets)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewRedisStore(client, "ratelimit:")
+	testStore(t, store)
+
+	if !server.Exists("ratelimit:ip:1.2.3.4") {
+		t.Fatal("bucket not stored with prefix")
+	}
+	if ttl := server.TTL("ratelimit:user:1"); ttl <= 0 || ttl > 3*time.Second {
+		t.Errorf("bucket ttl = %s", ttl)
+	}
+}
+
+func TestPing(t *testing.T) {
+	defer SetStore(store)
+
+	SetStore(NewMemoryStore())
+	if checked, err := Ping(context.Background()); checked || err != nil {
+		t.Fatalf("memory store: checked %v, err %v", checked, err)
+	}
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	SetStore(NewRedisStore(client, "ratelimit:"))
+	if checked, err := Ping(context.Background()); !checked || err != nil {
+		t.Fatalf("redis store: checked %v, err %v", checked, err)
+	}
+
+	server.Close()
+	if checked, err := Ping(context.Background()); !checked || err == nil {
+		t.Fatalf("redis down: checked %v, err %v", checked, err)
+	}
+}
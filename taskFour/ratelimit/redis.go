@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript 在 Redis 中原子地补充令牌并取一个，桶为 hash（tokens、ts 毫秒），
+// 过期时间设为装满所需的时间。当前时间由调用方传入，便于测试
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+
+if now > ts then
+  tokens = math.min(burst, tokens + (now - ts) * rate)
+  ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil((burst - tokens) / rate)))
+return {allowed, tostring(tokens)}
+`)
+
+// RedisClient RedisStore 用到的 Redis 命令，*redis.Client 和 *redis.ClusterClient 都满足
+type RedisClient interface {
+	redis.Scripter
+	Ping(ctx context.Context) *redis.StatusCmd
+}
+
+// RedisStore 在 Redis（或兼容 Redis 协议的服务）中保存令牌桶，多个实例共享限流状态
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 存储，key 会加上 prefix
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Ping 检查 Redis 能否连通
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	ratePerMs := float64(limit.Requests) / float64(limit.Period.Milliseconds())
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Requests, strconv.FormatFloat(ratePerMs, 'g', -1, 64), now.UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	text, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected token count %q", text)
+	}
+	return result(limit, allowed == 1, tokens), nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepository 分类仓储
+type CategoryRepository interface {
+	// Tree 全部分类按父子关系组装成的树，同级按名称排序
+	Tree(ctx context.Context) ([]models.Category, error)
+	Find(ctx context.Context, id uint) (*models.Category, error)
+	// SlugExists slug 是否已被 ID 不是 excludeID 的分类使用
+	SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error)
+	// DescendantIDs 分类自身及其所有子孙分类的 ID
+	DescendantIDs(ctx context.Context, id uint) ([]uint, error)
+	Create(ctx context.Context, category *models.Category) error
+	// Update 保存名称、slug 和父分类
+	Update(ctx context.Context, category *models.Category) error
+	// Delete 删除分类，子分类和文章移动到它的父分类下，文章的版本号加一
+	Delete(ctx context.Context, category *models.Category) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository 创建基于 GORM 的分类仓储
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Tree(ctx context.Context) ([]models.Category, error) {
+	return models.CategoryTree(r.db.WithContext(ctx))
+}
+
+func (r *categoryRepository) Find(ctx context.Context, id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) SlugExists(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *categoryRepository) DescendantIDs(ctx context.Context, id uint) ([]uint, error) {
+	return models.CategoryDescendantIDs(r.db.WithContext(ctx), id)
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Model(category).Updates(map[string]interface{}{
+		"name":      category.Name,
+		"slug":      category.Slug,
+		"parent_id": category.ParentID,
+	}).Error
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", category.ID).
+			Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Post{}).Where("category_id = ?", category.ID).
+			Updates(map[string]interface{}{"category_id": category.ParentID, "version": models.NextVersion}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// CommentRepository 评论仓储
+type CommentRepository interface {
+	// Find 查询评论，包括已删除的占位评论，带有作者和所属文章
+	Find(ctx context.Context, id uint) (*models.Comment, error)
+	// ListApproved 文章下审核通过的评论，按创建时间正序
+	ListApproved(ctx context.Context, postID uint) ([]models.Comment, error)
+	// ListThreads 按顶级评论分页（page.Sort 为 CommentSorts 中的一种），Items 中先是这一页的顶级评论，
+	// 之后是它们审核通过的全部回复（按创建时间正序），Total 为顶级评论总数
+	ListThreads(ctx context.Context, postID uint, page PageRequest) (Page[models.Comment], error)
+	// ListByStatus 指定审核状态的评论及总数，按创建时间正序
+	ListByStatus(ctx context.Context, status string, offset, limit int) ([]models.Comment, int64, error)
+	Create(ctx context.Context, comment *models.Comment) error
+	// Update 保存评论的内容、审核状态和修改时间
+	Update(ctx context.Context, comment *models.Comment) error
+	// UpdateStatus 把状态属于 from 的评论改为 to，返回实际更新的数量
+	UpdateStatus(ctx context.Context, ids []uint, from []string, to string) (int64, error)
+	// Delete 删除评论，仍有回复时保留为墓碑，见 models.DeleteComment
+	Delete(ctx context.Context, comment *models.Comment) error
+	// Search 全文搜索在 visibleAt 时公开可见的文章下审核通过的评论，按相关度排序，见 models.SearchComments
+	Search(ctx context.Context, query string, visibleAt time.Time, offset, limit int) ([]models.CommentSearchResult, error)
+}
+
+// 评论列表的排序字段
+const CommentSortCreatedAt = "created_at"
+
+// CommentSorts 评论列表支持的排序方式
+var CommentSorts = map[string]SortKey[models.Comment]{
+	CommentSortCreatedAt: timeKey("comments.created_at", func(c models.Comment) time.Time { return c.CreatedAt },
+		func(c models.Comment) uint { return c.ID }),
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository 创建基于 GORM 的评论仓储
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Find(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).Preload("User").Preload("Post").First(&comment, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) ListApproved(ctx context.Context, postID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.WithContext(ctx).Preload("User").Scopes(models.ApprovedComments).
+		Where("post_id = ?", postID).Order("created_at asc, id asc").Find(&comments).Error
+	return comments, err
+}
+
+func (r *commentRepository) ListThreads(ctx context.Context, postID uint, page PageRequest) (Page[models.Comment], error) {
+	key, ok := CommentSorts[page.Sort]
+	if !ok {
+		return Page[models.Comment]{}, fmt.Errorf("unknown comment sort %q", page.Sort)
+	}
+
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.Comment{}).Scopes(models.ApprovedComments).
+		Where("comments.post_id = ? AND comments.parent_id IS NULL", postID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if page.Total {
+		if err := query.Count(&total).Error; err != nil {
+			return Page[models.Comment]{}, err
+		}
+	}
+
+	paged, err := key.Apply(query.Preload("User"), "comments.id", page)
+	if err != nil {
+		return Page[models.Comment]{}, err
+	}
+	var roots []models.Comment
+	if err := paged.Find(&roots).Error; err != nil {
+		return Page[models.Comment]{}, err
+	}
+	result := key.Page(roots, page)
+	if page.Total {
+		result.Total = &total
+	}
+
+	// 逐层加载回复，层数不超过 config.CommentMaxDepth
+	parents := make([]uint, len(result.Items))
+	for i, c := range result.Items {
+		parents[i] = c.ID
+	}
+	var replies []models.Comment
+	for len(parents) > 0 {
+		var level []models.Comment
+		err := db.Preload("User").Scopes(models.ApprovedComments).
+			Where("comments.parent_id IN ?", parents).
+			Order("comments.created_at asc, comments.id asc").Find(&level).Error
+		if err != nil {
+			return Page[models.Comment]{}, err
+		}
+		parents = parents[:0]
+		for _, c := range level {
+			parents = append(parents, c.ID)
+		}
+		replies = append(replies, level...)
+	}
+	slices.SortStableFunc(replies, func(a, b models.Comment) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	result.Items = append(result.Items, replies...)
+	return result, nil
+}
+
+func (r *commentRepository) ListByStatus(ctx context.Context, status string, offset, limit int) ([]models.Comment, int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&models.Comment{}).Where("status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []models.Comment
+	err := query.Preload("User").Order("created_at asc").Offset(offset).Limit(limit).Find(&comments).Error
+	return comments, total, err
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	if err := r.db.WithContext(ctx).Omit("User", "Post").Create(comment).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Preload("User").First(comment, comment.ID).Error
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *models.Comment) error {
+	err := r.db.WithContext(ctx).Model(comment).Omit("User", "Post").Updates(map[string]interface{}{
+		"content":         comment.Content,
+		"status":          comment.Status,
+		"moderation_note": comment.ModerationNote,
+		"edited_at":       comment.EditedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Preload("User").First(comment, comment.ID).Error
+}
+
+func (r *commentRepository) UpdateStatus(ctx context.Context, ids []uint, from []string, to string) (int64, error) {
+	updates := map[string]interface{}{"status": to}
+	if to == models.CommentStatusApproved {
+		updates["moderation_note"] = ""
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.Comment{}).
+		Where("id IN ? AND status IN ?", ids, from).
+		Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+func (r *commentRepository) Delete(ctx context.Context, comment *models.Comment) error {
+	// 去掉预加载的关联，避免保存墓碑时连带写入作者和文章
+	target := *comment
+	target.User = nil
+	target.Post = models.Post{}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return models.DeleteComment(tx, &target)
+	})
+}
+
+func (r *commentRepository) Search(ctx context.Context, query string, visibleAt time.Time, offset, limit int) ([]models.CommentSearchResult, error) {
+	return models.SearchComments(r.db.WithContext(ctx), query, visibleAt, limit, offset)
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrLastLoginMethod 删除的是账号最后一个可登录的身份（密码或钱包）
+	ErrLastLoginMethod = errors.New("cannot remove the last login method")
+	// ErrPasswordSet 账号已经设置了密码
+	ErrPasswordSet = errors.New("password already set")
+)
+
+// IdentityRepository 账号关联的登录身份仓储
+type IdentityRepository interface {
+	// List 账号的全部身份，按 ID 正序
+	List(ctx context.Context, userID uint) ([]models.UserIdentity, error)
+	// Find 账号的某个身份，属于其他账号时同样返回 ErrNotFound
+	Find(ctx context.Context, id, userID uint) (*models.UserIdentity, error)
+	// FindUser 身份所属的账号
+	FindUser(ctx context.Context, provider, subject string) (*models.User, error)
+	// Linked 身份是否已被任意账号关联，未验证的邮箱身份不占用地址，不算作已关联
+	Linked(ctx context.Context, provider, subject string) (bool, error)
+	// EmailTaken 邮箱是否是 userID 以外的账号的主邮箱
+	EmailTaken(ctx context.Context, email string, userID uint) (bool, error)
+	// Create 关联身份并替换其他账号未验证的同一邮箱，已验证的邮箱身份在账号没有主邮箱时同时设为主邮箱
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	// CreatePassword 创建密码身份并在同一事务中保存加密后的 password，账号已有密码时返回 ErrPasswordSet
+	CreatePassword(ctx context.Context, identity *models.UserIdentity, password string) error
+	// CreateUser 在同一事务中创建账号及其身份
+	CreateUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error
+	// Delete 解除身份并同步账号的密码和主邮箱，删除的是最后一个可登录的身份时返回 ErrLastLoginMethod。
+	// 检查和删除在同一事务中进行并锁定账号，同一账号并发删除身份时依次执行，身份已被删除时返回 ErrNotFound
+	Delete(ctx context.Context, identity *models.UserIdentity) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository 创建基于 GORM 的身份仓储
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) List(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id asc").Find(&identities).Error
+	return identities, err
+}
+
+func (r *identityRepository) Find(ctx context.Context, id, userID uint) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&identity).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) FindUser(ctx context.Context, provider, subject string) (*models.User, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &identity.User, nil
+}
+
+func (r *identityRepository) Linked(ctx context.Context, provider, subject string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserIdentity{}).
+		Where("provider = ? AND subject = ? AND verified_at IS NOT NULL", provider, subject).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *identityRepository) EmailTaken(ctx context.Context, email string, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("email = ? AND id <> ?", email, userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if identity.Provider == models.IdentityEmail {
+			if err := models.ReleaseUnverifiedEmail(tx, identity.Subject); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(identity).Error; err != nil {
+			return err
+		}
+		if identity.Provider != models.IdentityEmail || identity.VerifiedAt == nil {
+			return nil
+		}
+		return tx.Model(&models.User{}).Where("id = ? AND email IS NULL", identity.UserID).Update("email", identity.Subject).Error
+	})
+}
+
+func (r *identityRepository) CreatePassword(ctx context.Context, identity *models.UserIdentity, password string) error {
+	user := models.User{Password: password}
+	if err := user.HashPassword(); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 条件更新防止并发请求重复设置密码
+		result := tx.Model(&models.User{}).Where("id = ? AND password = ?", identity.UserID, "").Update("password", user.Password)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPasswordSet
+		}
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *identityRepository) CreateUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *identityRepository) Delete(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定账号，同一账号的身份检查和删除依次执行，避免并发删除后一种登录方式都不剩
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, identity.UserID).Error; err != nil {
+			return notFound(err)
+		}
+
+		if identity.IsLoginMethod() {
+			var count int64
+			if err := tx.Model(&models.UserIdentity{}).
+				Where("user_id = ? AND provider IN ?", identity.UserID, []string{models.IdentityPassword, models.IdentityEthereum}).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count <= 1 {
+				return ErrLastLoginMethod
+			}
+		}
+
+		result := tx.Delete(identity)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		// 同步 users 表上的密码和主邮箱，主邮箱只会换成已验证的邮箱
+		switch identity.Provider {
+		case models.IdentityPassword:
+			return tx.Model(&models.User{}).Where("id = ?", identity.UserID).Update("password", "").Error
+		case models.IdentityEmail:
+			var next models.UserIdentity
+			err := tx.Where("user_id = ? AND provider = ? AND verified_at IS NOT NULL", identity.UserID, models.IdentityEmail).
+				Order("id asc").First(&next).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			var email *string
+			if err == nil {
+				email = &next.Subject
+			}
+			return tx.Model(&models.User{}).Where("id = ? AND email = ?", identity.UserID, identity.Subject).Update("email", email).Error
+		}
+		return nil
+	})
+}
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+type commentRepository struct {
+	*Store
+}
+
+func (r commentRepository) Find(_ context.Context, id uint) (*models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	comment = r.withUser(comment)
+	comment.Post = r.posts[comment.PostID]
+	return &comment, nil
+}
+
+func (r commentRepository) ListApproved(_ context.Context, postID uint) ([]models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.list(func(c models.Comment) bool {
+		return c.PostID == postID && c.Status == models.CommentStatusApproved
+	}), nil
+}
+
+func (r commentRepository) ListThreads(_ context.Context, postID uint, page repositories.PageRequest) (repositories.Page[models.Comment], error) {
+	key, ok := repositories.CommentSorts[page.Sort]
+	if !ok {
+		return repositories.Page[models.Comment]{}, fmt.Errorf("unknown comment sort %q", page.Sort)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	approved := r.list(func(c models.Comment) bool {
+		return c.PostID == postID && c.Status == models.CommentStatusApproved
+	})
+	var roots []models.Comment
+	for _, c := range approved {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		}
+	}
+	result, err := key.Slice(roots, page)
+	if err != nil {
+		return result, err
+	}
+
+	// 回复按创建时间正序，只保留这一页顶级评论下的
+	threads := make(map[uint]bool)
+	for _, c := range result.Items {
+		threads[c.ID] = true
+	}
+	for _, c := range approved {
+		if c.ParentID != nil && threads[*c.ParentID] {
+			threads[c.ID] = true
+			result.Items = append(result.Items, c)
+		}
+	}
+	return result, nil
+}
+
+func (r commentRepository) ListByStatus(_ context.Context, status string, offset, limit int) ([]models.Comment, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comments := r.list(func(c models.Comment) bool { return c.Status == status })
+	return paginate(comments, offset, limit), int64(len(comments)), nil
+}
+
+func (r commentRepository) Create(_ context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.posts[comment.PostID]; !ok {
+		return repositories.ErrNotFound
+	}
+	if comment.Status == "" {
+		comment.Status = models.CommentStatusApproved
+	}
+	comment.ID = r.id()
+	comment.CreatedAt = time.Now().UTC()
+	r.save(*comment)
+	*comment = r.withUser(r.comments[comment.ID])
+	return nil
+}
+
+func (r commentRepository) Update(_ context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.comments[comment.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	stored.Content = comment.Content
+	stored.Status = comment.Status
+	stored.ModerationNote = comment.ModerationNote
+	stored.EditedAt = comment.EditedAt
+	r.save(stored)
+	*comment = r.withUser(stored)
+	return nil
+}
+
+func (r commentRepository) UpdateStatus(_ context.Context, ids []uint, from []string, to string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var updated int64
+	for _, id := range ids {
+		comment, ok := r.comments[id]
+		if !ok || !slices.Contains(from, comment.Status) {
+			continue
+		}
+		comment.Status = to
+		if to == models.CommentStatusApproved {
+			comment.ModerationNote = ""
+		}
+		r.save(comment)
+		updated++
+	}
+	return updated, nil
+}
+
+// Delete 与 models.DeleteComment 的规则相同：有回复时保留墓碑，否则删除并向上清理没有回复的墓碑
+func (r commentRepository) Delete(_ context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := comment.ID
+	for {
+		stored, ok := r.comments[id]
+		if !ok {
+			return nil
+		}
+		if r.hasReplies(id) {
+			stored.Content = ""
+			stored.Deleted = true
+			r.save(stored)
+			return nil
+		}
+		delete(r.comments, id)
+		if stored.ParentID == nil {
+			return nil
+		}
+		parent, ok := r.comments[*stored.ParentID]
+		if !ok || !parent.Deleted {
+			return nil
+		}
+		id = parent.ID
+	}
+}
+
+// list 满足条件的评论，按创建时间正序
+// Search 内存实现没有全文索引，总是返回空结果
+func (r commentRepository) Search(context.Context, string, time.Time, int, int) ([]models.CommentSearchResult, error) {
+	return []models.CommentSearchResult{}, nil
+}
+
+func (r commentRepository) list(match func(models.Comment) bool) []models.Comment {
+	comments := make([]models.Comment, 0)
+	for _, comment := range r.comments {
+		if match(comment) {
+			comments = append(comments, r.withUser(comment))
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments
+}
+
+func (r commentRepository) hasReplies(id uint) bool {
+	for _, comment := range r.comments {
+		if comment.ParentID != nil && *comment.ParentID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// save 去掉关联数据后保存
+func (r commentRepository) save(comment models.Comment) {
+	comment.User = nil
+	comment.Post = models.Post{}
+	comment.Replies = nil
+	r.comments[comment.ID] = comment
+}
+
+func (r commentRepository) withUser(comment models.Comment) models.Comment {
+	if user, ok := r.users[comment.UserID]; ok {
+		comment.User = &user
+	}
+	return comment
+}
@@ -0,0 +1,93 @@
+// Package memory 仓储接口的内存实现，用于不依赖数据库测试服务层和处理器。
+// 只实现接口约定的行为，不维护全文索引
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+// Store 保存全部数据，各仓储共享同一个 Store，返回给调用方的都是副本
+type Store struct {
+	mu         sync.Mutex
+	nextID     uint
+	users      map[uint]models.User
+	posts      map[uint]models.Post
+	comments   map[uint]models.Comment
+	categories map[uint]models.Category
+	tags       map[string]models.Tag          // 按 slug 索引
+	revisions  map[uint][]models.PostRevision // 按文章 ID 索引，按版本号正序
+	revisionID uint                           // 历史版本单独编号，不占用其他数据的 ID
+	refresh    map[uint]models.RefreshToken
+	revoked    map[string]models.RevokedToken // 按 jti 索引
+	nonces     map[string]models.AuthNonce    // 按随机数索引
+}
+
+// New 创建空的 Store
+func New() *Store {
+	return &Store{
+		users:      make(map[uint]models.User),
+		posts:      make(map[uint]models.Post),
+		comments:   make(map[uint]models.Comment),
+		categories: make(map[uint]models.Category),
+		tags:       make(map[string]models.Tag),
+		revisions:  make(map[uint][]models.PostRevision),
+		refresh:    make(map[uint]models.RefreshToken),
+		revoked:    make(map[string]models.RevokedToken),
+		nonces:     make(map[string]models.AuthNonce),
+	}
+}
+
+// Users 用户仓储
+func (s *Store) Users() repositories.UserRepository {
+	return userRepository{s}
+}
+
+// Posts 文章仓储
+func (s *Store) Posts() repositories.PostRepository {
+	return postRepository{s}
+}
+
+// Comments 评论仓储
+func (s *Store) Comments() repositories.CommentRepository {
+	return commentRepository{s}
+}
+
+// Tokens 令牌仓储
+func (s *Store) Tokens() repositories.TokenRepository {
+	return tokenRepository{s}
+}
+
+// AddCategory 添加分类，parentID 为 nil 时是顶级分类
+func (s *Store) AddCategory(name, slug string, parentID *uint) models.Category {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	category := models.Category{ID: s.id(), Name: name, Slug: slug, ParentID: parentID, CreatedAt: now, UpdatedAt: now}
+	s.categories[category.ID] = category
+	return category
+}
+
+// id 分配自增 ID，所有表共用一个序列，调用方需持有锁
+func (s *Store) id() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// paginate 与 SQL 的 OFFSET/LIMIT 语义一致，limit 为负数时不限制
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset > len(items) {
+		offset = len(items)
+	}
+	if offset > 0 {
+		items = items[offset:]
+	}
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,322 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"taskFour/models"
+	"taskFour/repositories"
+	"taskFour/utils"
+)
+
+type postRepository struct {
+	*Store
+}
+
+func (r postRepository) List(_ context.Context, filter repositories.PostFilter, page repositories.PageRequest) (repositories.Page[models.Post], error) {
+	key, ok := repositories.PostSorts[page.Sort]
+	if !ok {
+		return repositories.Page[models.Post]{}, fmt.Errorf("unknown post sort %q", page.Sort)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var categories map[uint]bool
+	if filter.Category != "" {
+		categories = r.categoryWithDescendants(filter.Category)
+	}
+
+	posts := make([]models.Post, 0)
+	for _, post := range r.posts {
+		switch {
+		case filter.AuthorID != 0 && post.UserID != filter.AuthorID,
+			filter.Status != "" && post.Status != filter.Status,
+			filter.VisibleAt != nil && !post.IsVisible(*filter.VisibleAt),
+			filter.Tag != "" && !hasTag(post, filter.Tag),
+			categories != nil && (post.CategoryID == nil || !categories[*post.CategoryID]):
+			continue
+		}
+		posts = append(posts, r.withRelations(post))
+	}
+
+	return key.Slice(posts, page)
+}
+
+func (r postRepository) Find(_ context.Context, id uint) (*models.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	post = r.withRelations(post)
+	return &post, nil
+}
+
+func (r postRepository) Create(_ context.Context, post *models.Post, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkCategory(post.CategoryID); err != nil {
+		return err
+	}
+	found, err := r.findOrCreateTags(tags)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	post.ID = r.id()
+	post.Tags = found
+	post.Version = 1
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	r.posts[post.ID] = r.stored(*post)
+	r.addRevision(*post, post.UserID, nil)
+	*post = r.withRelations(r.posts[post.ID])
+	return nil
+}
+
+func (r postRepository) Update(_ context.Context, before, after *models.Post, tags *[]string, editorID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.posts[after.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if stored.Version != before.Version {
+		return repositories.ErrVersionConflict
+	}
+	if !sameID(before.CategoryID, after.CategoryID) {
+		if err := r.checkCategory(after.CategoryID); err != nil {
+			return err
+		}
+	}
+	// 与 GORM 实现相同，没有任何修改时不改变版本号和修改时间
+	changed := tags != nil || stored.Title != after.Title || stored.Content != after.Content ||
+		stored.Status != after.Status || !sameTime(stored.PublishedAt, after.PublishedAt) ||
+		!sameID(stored.CategoryID, after.CategoryID)
+	if !changed {
+		*after = r.withRelations(stored)
+		return nil
+	}
+	if tags != nil {
+		found, err := r.findOrCreateTags(*tags)
+		if err != nil {
+			return err
+		}
+		stored.Tags = found
+	}
+
+	// 标题或内容有变化时保存新版本
+	if stored.Title != after.Title || stored.Content != after.Content {
+		r.addRevision(*after, editorID, nil)
+	}
+
+	stored.Title = after.Title
+	stored.Content = after.Content
+	stored.Status = after.Status
+	stored.PublishedAt = after.PublishedAt
+	stored.CategoryID = after.CategoryID
+	stored.Version++
+	stored.UpdatedAt = time.Now().UTC()
+	r.posts[stored.ID] = r.stored(stored)
+	*after = r.withRelations(r.posts[stored.ID])
+	return nil
+}
+
+func (r postRepository) Delete(_ context.Context, post *models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.posts, post.ID)
+	delete(r.revisions, post.ID)
+	for id, comment := range r.comments {
+		if comment.PostID == post.ID {
+			delete(r.comments, id)
+		}
+	}
+	return nil
+}
+
+// Search 内存实现没有全文索引，总是返回空结果
+func (r postRepository) Search(context.Context, string, time.Time, int, int) ([]models.PostSearchResult, error) {
+	return []models.PostSearchResult{}, nil
+}
+
+func (r postRepository) Revisions(_ context.Context, postID uint) ([]models.PostRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.revisions[postID]
+	revisions := make([]models.PostRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revision := stored[i]
+		revision.Editor = r.users[revision.EditorID]
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func (r postRepository) Revision(_ context.Context, postID uint, rev int) (*models.PostRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, revision := range r.revisions[postID] {
+		if revision.Rev == rev {
+			return &revision, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r postRepository) Restore(_ context.Context, post *models.Post, revision *models.PostRevision, editorID uint) (*models.PostRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.posts[post.ID]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	if stored.Version != post.Version {
+		return nil, repositories.ErrVersionConflict
+	}
+
+	stored.Title = revision.Title
+	stored.Content = revision.Content
+	stored.Version++
+	stored.UpdatedAt = time.Now().UTC()
+	r.posts[stored.ID] = stored
+	rev := revision.Rev
+	created := r.addRevision(stored, editorID, &rev)
+	*post = r.withRelations(stored)
+	return &created, nil
+}
+
+// addRevision 以 post 当前的标题和内容追加一个版本，调用方需持有锁
+func (r postRepository) addRevision(post models.Post, editorID uint, restoredFrom *int) models.PostRevision {
+	r.revisionID++
+	revision := models.PostRevision{
+		ID:           r.revisionID,
+		PostID:       post.ID,
+		Rev:          len(r.revisions[post.ID]) + 1,
+		EditorID:     editorID,
+		Title:        post.Title,
+		Content:      post.Content,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    time.Now().UTC(),
+	}
+	r.revisions[post.ID] = append(r.revisions[post.ID], revision)
+	return revision
+}
+
+// stored 去掉关联数据后保存，读取时由 withRelations 重新关联
+func (r postRepository) stored(post models.Post) models.Post {
+	post.User = models.User{}
+	post.Category = nil
+	post.Comments = nil
+	post.Tags = append([]models.Tag(nil), post.Tags...)
+	return post
+}
+
+// withRelations 关联作者、分类和标签并统计评论数，返回的文章与 Store 中的数据不共享内存
+func (s *Store) withRelations(post models.Post) models.Post {
+	post.User = s.users[post.UserID]
+	post.CommentCount = 0
+	for _, comment := range s.comments {
+		if comment.PostID == post.ID && comment.Status == models.CommentStatusApproved && !comment.Deleted {
+			post.CommentCount++
+		}
+	}
+	post.Category = nil
+	if post.CategoryID != nil {
+		if category, ok := s.categories[*post.CategoryID]; ok {
+			post.Category = &category
+		}
+	}
+	post.Tags = append([]models.Tag{}, post.Tags...)
+	return post
+}
+
+func (r postRepository) checkCategory(id *uint) error {
+	if id == nil {
+		return nil
+	}
+	if _, ok := r.categories[*id]; !ok {
+		return models.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// findOrCreateTags 与 models.FindOrCreateTags 的规则相同：按 slug 去重，slug 为空时报错
+func (r postRepository) findOrCreateTags(names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := utils.Slugify(name)
+		if slug == "" {
+			return nil, models.ErrInvalidTagName
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		tag, ok := r.tags[slug]
+		if !ok {
+			tag = models.Tag{ID: r.id(), Name: name, Slug: slug, CreatedAt: time.Now().UTC()}
+			r.tags[slug] = tag
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// categoryWithDescendants slug 对应的分类及其子孙分类，分类不存在时返回空集合
+func (r postRepository) categoryWithDescendants(slug string) map[uint]bool {
+	ids := make(map[uint]bool)
+	for _, category := range r.categories {
+		if category.Slug == slug {
+			ids[category.ID] = true
+		}
+	}
+	for added := len(ids) > 0; added; {
+		added = false
+		for _, category := range r.categories {
+			if category.ParentID != nil && ids[*category.ParentID] && !ids[category.ID] {
+				ids[category.ID] = true
+				added = true
+			}
+		}
+	}
+	return ids
+}
+
+func hasTag(post models.Post, slug string) bool {
+	for _, tag := range post.Tags {
+		if tag.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+type tokenRepository struct {
+	*Store
+}
+
+func (r tokenRepository) CreateRefreshToken(_ context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = r.id()
+	token.CreatedAt = time.Now().UTC()
+	r.refresh[token.ID] = *token
+	return nil
+}
+
+func (r tokenRepository) FindRefreshToken(_ context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.refresh {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r tokenRepository) RotateRefreshToken(_ context.Context, current, next *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.refresh[current.ID]
+	if !ok || stored.RevokedAt != nil {
+		return repositories.ErrTokenRevoked
+	}
+
+	now := time.Now().UTC()
+	next.ID = r.id()
+	next.CreatedAt = now
+	r.refresh[next.ID] = *next
+	stored.RevokedAt = &now
+	stored.ReplacedBy = &next.ID
+	r.refresh[stored.ID] = stored
+	return nil
+}
+
+func (r tokenRepository) RevokeFamily(_ context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	for id, token := range r.refresh {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.refresh[id] = token
+		}
+	}
+	return nil
+}
+
+func (r tokenRepository) RevokeAccessToken(_ context.Context, token *models.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.revoked[token.JTI]; ok {
+		return nil
+	}
+	token.ID = r.id()
+	token.CreatedAt = time.Now().UTC()
+	r.revoked[token.JTI] = *token
+	return nil
+}
+
+func (r tokenRepository) AccessTokenRevoked(_ context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.revoked[jti]
+	return ok, nil
+}
+
+func (r tokenRepository) CreateNonce(_ context.Context, nonce *models.AuthNonce) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nonce.ID = r.id()
+	nonce.CreatedAt = time.Now().UTC()
+	r.nonces[nonce.Nonce] = *nonce
+	return nil
+}
+
+func (r tokenRepository) ConsumeNonce(_ context.Context, nonce string, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.nonces[nonce]
+	if !ok || stored.UsedAt != nil || !stored.ExpiresAt.After(now) {
+		return false, nil
+	}
+	stored.UsedAt = &now
+	r.nonces[nonce] = stored
+	return true, nil
+}
+
+func (r tokenRepository) PurgeExpired(_ context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for jti, token := range r.revoked {
+		if token.ExpiresAt.Before(now) {
+			delete(r.revoked, jti)
+		}
+	}
+	for nonce, stored := range r.nonces {
+		if stored.ExpiresAt.Before(now) {
+			delete(r.nonces, nonce)
+		}
+	}
+	for id, token := range r.refresh {
+		if token.ExpiresAt.Before(now) {
+			delete(r.refresh, id)
+		}
+	}
+	return nil
+}
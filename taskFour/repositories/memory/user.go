@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+// errDuplicateUser 模拟数据库的唯一约束
+var errDuplicateUser = errors.New("username or email already exists")
+
+type userRepository struct {
+	*Store
+}
+
+func (r userRepository) Find(_ context.Context, id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r userRepository) FindByUsername(_ context.Context, username string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r userRepository) Exists(_ context.Context, username, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exists(username, email), nil
+}
+
+func (r userRepository) exists(username, email string) bool {
+	for _, user := range r.users {
+		if user.Username == username || (user.Email != nil && email != "" && *user.Email == email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r userRepository) Create(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email := ""
+	if user.Email != nil {
+		email = *user.Email
+	}
+	if r.exists(user.Username, email) {
+		return errDuplicateUser
+	}
+	if err := user.BeforeCreate(nil); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	user.ID = r.id()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r userRepository) List(_ context.Context, offset, limit int) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return paginate(users, offset, limit), nil
+}
+
+func (r userRepository) UpdateRole(_ context.Context, user *models.User, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	stored.Role = role
+	stored.UpdatedAt = time.Now().UTC()
+	r.users[user.ID] = stored
+	*user = stored
+	return nil
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 排序方向
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// ErrInvalidCursor 游标无法解析，或与请求的排序方式不一致
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor 键集分页的位置：排序字段的值和记录 ID。Before 为 true 时取该位置之前（上一页）的记录，
+// 否则取之后的记录。对客户端不透明，通过 Encode / DecodeCursor 传递
+type Cursor struct {
+	Sort   string `json:"s"`
+	Order  string `json:"o"`
+	Value  string `json:"v"`
+	ID     uint   `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// Encode 编码为 URL 安全的字符串
+func (c *Cursor) Encode() string {
+	if c == nil {
+		return ""
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析 Encode 的结果
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort == "" || (c.Order != OrderAsc && c.Order != OrderDesc) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// PageRequest 一页数据的请求，Cursor 为 nil 时取第一页
+type PageRequest struct {
+	Sort   string
+	Order  string
+	Cursor *Cursor
+	Limit  int
+	Offset int  // 兼容已废弃的页码分页，跳过的记录数，不与 Cursor 同时使用
+	Total  bool // 是否统计符合条件的记录总数
+}
+
+// Page 一页数据，没有下一页或上一页时对应的游标为 nil，Total 只在请求统计时返回
+type Page[T any] struct {
+	Items []T
+	Next  *Cursor
+	Prev  *Cursor
+	Total *int64
+}
+
+// SortKey 一种排序方式：SQL 中的排序表达式，以及从记录取排序值的方法。
+// 排序值为 time.Time、string 或 int64，相同时按 ID 排序
+type SortKey[T any] struct {
+	Column string
+	Value  func(T) any
+	ID     func(T) uint
+	parse  func(string) (any, error)
+}
+
+func timeKey[T any](column string, value func(T) time.Time, id func(T) uint) SortKey[T] {
+	return SortKey[T]{
+		Column: column,
+		Value:  func(item T) any { return value(item) },
+		ID:     id,
+		parse: func(s string) (any, error) {
+			return time.Parse(time.RFC3339Nano, s)
+		},
+	}
+}
+
+func stringKey[T any](column string, value func(T) string, id func(T) uint) SortKey[T] {
+	return SortKey[T]{
+		Column: column,
+		Value:  func(item T) any { return value(item) },
+		ID:     id,
+		parse:  func(s string) (any, error) { return s, nil },
+	}
+}
+
+func intKey[T any](column string, value func(T) int64, id func(T) uint) SortKey[T] {
+	return SortKey[T]{
+		Column: column,
+		Value:  func(item T) any { return value(item) },
+		ID:     id,
+		parse: func(s string) (any, error) {
+			return strconv.ParseInt(s, 10, 64)
+		},
+	}
+}
+
+// cursorValue 游标中的排序值，时间保留时区，便于与数据库中的值精确比较
+func cursorValue(v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// position 解析请求中游标的排序值，没有游标时返回 nil
+func (k SortKey[T]) position(req PageRequest) (any, error) {
+	if req.Cursor == nil {
+		return nil, nil
+	}
+	if req.Cursor.Sort != req.Sort || req.Cursor.Order != req.Order {
+		return nil, ErrInvalidCursor
+	}
+	value, err := k.parse(req.Cursor.Value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return value, nil
+}
+
+// descending 本次查询是否按降序扫描：上一页按与排序方向相反的顺序查询，再反转结果
+func descending(req PageRequest) bool {
+	return (req.Order == OrderDesc) != (req.Cursor != nil && req.Cursor.Before)
+}
+
+// Apply 为 query 加上游标条件、排序、LIMIT（多取一条用于判断是否还有更多记录）和 OFFSET，idColumn 为 ID 列
+func (k SortKey[T]) Apply(query *gorm.DB, idColumn string, req PageRequest) (*gorm.DB, error) {
+	value, err := k.position(req)
+	if err != nil {
+		return nil, err
+	}
+
+	op, dir := ">", "asc"
+	if descending(req) {
+		op, dir = "<", "desc"
+	}
+	if value != nil {
+		query = query.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", k.Column, op, idColumn),
+			value, value, req.Cursor.ID)
+	}
+	query = query.Order(k.Column + " " + dir + ", " + idColumn + " " + dir).Limit(req.Limit + 1)
+	if req.Offset > 0 {
+		query = query.Offset(req.Offset)
+	}
+	return query, nil
+}
+
+// Page 由 Apply 查询的结果组装一页
+func (k SortKey[T]) Page(rows []T, req PageRequest) Page[T] {
+	more := len(rows) > req.Limit
+	if more {
+		rows = rows[:req.Limit]
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	before := req.Cursor != nil && req.Cursor.Before
+	if before {
+		slices.Reverse(rows)
+	}
+
+	page := Page[T]{Items: rows}
+	if len(rows) == 0 {
+		return page
+	}
+	cursor := func(item T, before bool) *Cursor {
+		return &Cursor{Sort: req.Sort, Order: req.Order, Value: cursorValue(k.Value(item)), ID: k.ID(item), Before: before}
+	}
+	// 向后翻页时游标之前一定有记录，向前翻页时游标之后一定有记录，跳过了记录时前面同样有记录
+	if more || before {
+		page.Next = cursor(rows[len(rows)-1], false)
+	}
+	if before && more || !before && (req.Cursor != nil || req.Offset > 0) {
+		page.Prev = cursor(rows[0], true)
+	}
+	return page
+}
+
+// Slice 在内存中对 items 做与 Apply + Page 相同的键集分页
+func (k SortKey[T]) Slice(items []T, req PageRequest) (Page[T], error) {
+	value, err := k.position(req)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	desc := descending(req)
+	compare := func(a, b any, aID, bID uint) int {
+		c := compareValues(a, b)
+		if c == 0 {
+			c = cmp.Compare(aID, bID)
+		}
+		if desc {
+			return -c
+		}
+		return c
+	}
+
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b T) int {
+		return compare(k.Value(a), k.Value(b), k.ID(a), k.ID(b))
+	})
+	rows := make([]T, 0, req.Limit+1)
+	skip := req.Offset
+	for _, item := range sorted {
+		if len(rows) > req.Limit {
+			break
+		}
+		if value != nil && compare(k.Value(item), value, k.ID(item), req.Cursor.ID) <= 0 {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		rows = append(rows, item)
+	}
+
+	page := k.Page(rows, req)
+	if req.Total {
+		total := int64(len(items))
+		page.Total = &total
+	}
+	return page, nil
+}
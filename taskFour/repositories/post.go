@@ -0,0 +1,299 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// 文章列表的排序字段
+const (
+	PostSortPublishedAt  = "published_at" // 发布时间，未发布的文章按创建时间
+	PostSortCreatedAt    = "created_at"
+	PostSortUpdatedAt    = "updated_at"
+	PostSortTitle        = "title"
+	PostSortCommentCount = "comment_count" // 公开评论数
+)
+
+func postID(p models.Post) uint { return p.ID }
+
+// PostSorts 文章列表支持的排序方式
+var PostSorts = map[string]SortKey[models.Post]{
+	PostSortPublishedAt: timeKey("COALESCE(posts.published_at, posts.created_at)", func(p models.Post) time.Time {
+		if p.PublishedAt != nil {
+			return *p.PublishedAt
+		}
+		return p.CreatedAt
+	}, postID),
+	PostSortCreatedAt:    timeKey("posts.created_at", func(p models.Post) time.Time { return p.CreatedAt }, postID),
+	PostSortUpdatedAt:    timeKey("posts.updated_at", func(p models.Post) time.Time { return p.UpdatedAt }, postID),
+	PostSortTitle:        stringKey("posts.title", func(p models.Post) string { return p.Title }, postID),
+	PostSortCommentCount: intKey(models.PostCommentCount, func(p models.Post) int64 { return p.CommentCount }, postID),
+}
+
+// PostFilter 文章列表的查询条件，零值表示不限
+type PostFilter struct {
+	AuthorID  uint
+	Status    string
+	VisibleAt *time.Time // 只返回在该时间公开可见的文章
+	Tag       string     // 标签 slug
+	Category  string     // 分类 slug，包含子孙分类，分类不存在时结果为空
+}
+
+// PostRepository 文章仓储，返回的文章都带有作者、分类、标签和评论数
+type PostRepository interface {
+	// List 按 page.Sort（PostSorts 中的一种）键集分页，游标与排序方式不一致时返回 ErrInvalidCursor
+	List(ctx context.Context, filter PostFilter, page PageRequest) (Page[models.Post], error)
+	Find(ctx context.Context, id uint) (*models.Post, error)
+	// Create 创建文章、关联标签（不存在时创建）并保存第一个历史版本
+	Create(ctx context.Context, post *models.Post, tags []string) error
+	// Update 保存 after 相对 before 变化的字段，tags 不为 nil 时替换标签，标题或内容变化时以 editorID 保存新版本。
+	// 有修改时版本号加一，文章的版本号已不是 before.Version 时返回 ErrVersionConflict
+	Update(ctx context.Context, before, after *models.Post, tags *[]string, editorID uint) error
+	Delete(ctx context.Context, post *models.Post) error
+	// Search 全文搜索在 visibleAt 时公开可见的文章，按相关度排序，见 models.SearchPosts
+	Search(ctx context.Context, query string, visibleAt time.Time, offset, limit int) ([]models.PostSearchResult, error)
+	// Revisions 文章的全部历史版本及编辑者，按版本号倒序
+	Revisions(ctx context.Context, postID uint) ([]models.PostRevision, error)
+	// Revision 文章的第 rev 个历史版本
+	Revision(ctx context.Context, postID uint, rev int) (*models.PostRevision, error)
+	// Restore 用 revision 的标题和内容覆盖文章，以 editorID 保存一个注明来源的新版本。
+	// 版本号加一，文章的版本号已不是 post.Version 时返回 ErrVersionConflict；成功后 post 为恢复后的文章
+	Restore(ctx context.Context, post *models.Post, revision *models.PostRevision, editorID uint) (*models.PostRevision, error)
+}
+
+type postRepository struct {
+	db *gorm.DB
+}
+
+// NewPostRepository 创建基于 GORM 的文章仓储
+func NewPostRepository(db *gorm.DB) PostRepository {
+	return &postRepository{db: db}
+}
+
+func (r *postRepository) List(ctx context.Context, filter PostFilter, page PageRequest) (Page[models.Post], error) {
+	key, ok := PostSorts[page.Sort]
+	if !ok {
+		return Page[models.Post]{}, fmt.Errorf("unknown post sort %q", page.Sort)
+	}
+
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.Post{})
+	if filter.AuthorID != 0 {
+		query = query.Where("posts.user_id = ?", filter.AuthorID)
+	}
+	if filter.Status != "" {
+		query = query.Where("posts.status = ?", filter.Status)
+	}
+	if filter.VisibleAt != nil {
+		query = query.Scopes(models.VisiblePosts(*filter.VisibleAt))
+	}
+	if filter.Tag != "" {
+		query = query.Where("posts.id IN (?)", db.Table("post_tags").
+			Select("post_tags.post_id").
+			Joins("JOIN tags ON tags.id = post_tags.tag_id").
+			Where("tags.slug = ?", filter.Tag))
+	}
+	if filter.Category != "" {
+		var category models.Category
+		if err := db.Where("slug = ?", filter.Category).First(&category).Error; err != nil {
+			if notFound(err) == ErrNotFound {
+				return key.Slice(nil, page)
+			}
+			return Page[models.Post]{}, err
+		}
+		ids, err := models.CategoryDescendantIDs(db, category.ID)
+		if err != nil {
+			return Page[models.Post]{}, err
+		}
+		query = query.Where("posts.category_id IN ?", ids)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if page.Total {
+		if err := query.Count(&total).Error; err != nil {
+			return Page[models.Post]{}, err
+		}
+	}
+
+	paged, err := key.Apply(query.Scopes(models.PostRelations), "posts.id", page)
+	if err != nil {
+		return Page[models.Post]{}, err
+	}
+	var posts []models.Post
+	if err := paged.Find(&posts).Error; err != nil {
+		return Page[models.Post]{}, err
+	}
+
+	result := key.Page(posts, page)
+	if page.Total {
+		result.Total = &total
+	}
+	return result, nil
+}
+
+func (r *postRepository) Find(ctx context.Context, id uint) (*models.Post, error) {
+	var post models.Post
+	if err := r.db.WithContext(ctx).Scopes(models.PostRelations).First(&post, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &post, nil
+}
+
+func (r *postRepository) Create(ctx context.Context, post *models.Post, tags []string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := models.CheckCategory(tx, post.CategoryID); err != nil {
+			return err
+		}
+		found, err := models.FindOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+		post.Tags = found
+		if err := tx.Omit("Tags.*").Create(post).Error; err != nil {
+			return err
+		}
+		_, err = models.CreateRevision(tx, nil, post, post.UserID, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return r.reload(ctx, post)
+}
+
+func (r *postRepository) Update(ctx context.Context, before, after *models.Post, tags *[]string, editorID uint) error {
+	updates := postChanges(before, after)
+	if len(updates) == 0 && tags == nil {
+		return r.reload(ctx, after)
+	}
+	updates["version"] = models.NextVersion
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, ok := updates["category_id"]; ok {
+			if err := models.CheckCategory(tx, after.CategoryID); err != nil {
+				return err
+			}
+		}
+		// 先按版本号更新，其他请求已修改过时不做任何改动
+		result := tx.Model(after).Omit("User", "Category", "Tags").Where("version = ?", before.Version).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if tags != nil {
+			found, err := models.FindOrCreateTags(tx, *tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(after).Omit("Tags.*").Association("Tags").Replace(found); err != nil {
+				return err
+			}
+		}
+		// 标题或内容有变化时保存新版本
+		if after.Title == before.Title && after.Content == before.Content {
+			return nil
+		}
+		_, err := models.CreateRevision(tx, before, after, editorID, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return r.reload(ctx, after)
+}
+
+func (r *postRepository) Delete(ctx context.Context, post *models.Post) error {
+	return r.db.WithContext(ctx).Delete(post).Error
+}
+
+func (r *postRepository) Search(ctx context.Context, query string, visibleAt time.Time, offset, limit int) ([]models.PostSearchResult, error) {
+	return models.SearchPosts(r.db.WithContext(ctx), query, visibleAt, limit, offset)
+}
+
+func (r *postRepository) Revisions(ctx context.Context, postID uint) ([]models.PostRevision, error) {
+	var revisions []models.PostRevision
+	err := r.db.WithContext(ctx).Preload("Editor").Where("post_id = ?", postID).Order("rev desc").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *postRepository) Revision(ctx context.Context, postID uint, rev int) (*models.PostRevision, error) {
+	var revision models.PostRevision
+	if err := r.db.WithContext(ctx).Where("post_id = ? AND rev = ?", postID, rev).First(&revision).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &revision, nil
+}
+
+func (r *postRepository) Restore(ctx context.Context, post *models.Post, revision *models.PostRevision, editorID uint) (*models.PostRevision, error) {
+	before := *post
+	var created *models.PostRevision
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(post).Omit("User", "Category", "Tags").Where("version = ?", before.Version).Updates(map[string]interface{}{
+			"title":   revision.Title,
+			"content": revision.Content,
+			"version": models.NextVersion,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		post.Title = revision.Title
+		post.Content = revision.Content
+		var err error
+		created, err = models.CreateRevision(tx, &before, post, editorID, &revision.Rev)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, r.reload(ctx, post)
+}
+
+// reload 重新加载文章的关联数据
+func (r *postRepository) reload(ctx context.Context, post *models.Post) error {
+	*post = models.Post{ID: post.ID}
+	return r.db.WithContext(ctx).Scopes(models.PostRelations).First(post).Error
+}
+
+// postChanges 文章中可修改的字段相对修改前的变化
+func postChanges(before, after *models.Post) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if after.Title != before.Title {
+		updates["title"] = after.Title
+	}
+	if after.Content != before.Content {
+		updates["content"] = after.Content
+	}
+	if after.Status != before.Status || !sameTime(after.PublishedAt, before.PublishedAt) {
+		updates["status"] = after.Status
+		updates["published_at"] = after.PublishedAt
+	}
+	if !sameID(after.CategoryID, before.CategoryID) {
+		updates["category_id"] = after.CategoryID
+	}
+	return updates
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
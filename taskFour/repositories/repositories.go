@@ -0,0 +1,23 @@
+// Package repositories 数据访问层：文章、评论、用户、分类、标签、身份和令牌的仓储接口及 GORM 实现。
+// 服务层只依赖接口，测试时可以换成 repositories/memory 中的内存实现
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound 记录不存在，各实现都必须返回这个错误而不是底层驱动的错误
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionConflict 记录在读取之后已被其他请求修改，版本号与 before 不一致
+var ErrVersionConflict = errors.New("version conflict")
+
+// notFound 把 GORM 的记录不存在转换为 ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// TagRepository 标签仓储
+type TagRepository interface {
+	// ListWithCount 全部标签及每个标签下在 visibleAt 时公开可见的文章数量，按文章数量倒序
+	ListWithCount(ctx context.Context, visibleAt time.Time) ([]models.TagWithCount, error)
+	Find(ctx context.Context, id uint) (*models.Tag, error)
+	// Exists 名称或 slug 是否已被 ID 不是 excludeID 的标签使用
+	Exists(ctx context.Context, name, slug string, excludeID uint) (bool, error)
+	Create(ctx context.Context, tag *models.Tag) error
+	// Update 保存名称和 slug
+	Update(ctx context.Context, tag *models.Tag) error
+	// Delete 删除标签并解除它与文章的关联
+	Delete(ctx context.Context, tag *models.Tag) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository 创建基于 GORM 的标签仓储
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) ListWithCount(ctx context.Context, visibleAt time.Time) ([]models.TagWithCount, error) {
+	return models.TagsWithCount(r.db.WithContext(ctx), visibleAt)
+}
+
+func (r *tagRepository) Find(ctx context.Context, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.WithContext(ctx).First(&tag, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) Exists(ctx context.Context, name, slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Tag{}).
+		Where("(name = ? OR slug = ?) AND id <> ?", name, slug, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Model(tag).Updates(map[string]interface{}{
+		"name": tag.Name,
+		"slug": tag.Slug,
+	}).Error
+}
+
+func (r *tagRepository) Delete(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(tag).Association("Posts").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTokenRevoked 刷新令牌在读取之后已被其他请求轮换或吊销
+var ErrTokenRevoked = errors.New("token already revoked")
+
+// TokenRepository 刷新令牌、访问令牌吊销列表和钱包登录随机数的仓储
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	// FindRefreshToken 按令牌哈希查找刷新令牌，包括已吊销和已过期的
+	FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// RotateRefreshToken 创建 next 并在同一事务中吊销 current、记录替换它的令牌，
+	// current 已被吊销时不做任何改动并返回 ErrTokenRevoked
+	RotateRefreshToken(ctx context.Context, current, next *models.RefreshToken) error
+	// RevokeFamily 吊销令牌家族中所有未吊销的刷新令牌
+	RevokeFamily(ctx context.Context, familyID string) error
+	// RevokeAccessToken 把访问令牌加入吊销列表，已存在时忽略
+	RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error
+	// AccessTokenRevoked jti 是否在吊销列表中
+	AccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	CreateNonce(ctx context.Context, nonce *models.AuthNonce) error
+	// ConsumeNonce 把在 now 时未过期、未使用的随机数标记为已使用，随机数不可用时返回 false
+	ConsumeNonce(ctx context.Context, nonce string, now time.Time) (bool, error)
+	// PurgeExpired 清理在 now 之前过期的吊销记录、刷新令牌和登录随机数
+	PurgeExpired(ctx context.Context, now time.Time) error
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository 创建基于 GORM 的令牌仓储
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *tokenRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) RotateRefreshToken(ctx context.Context, current, next *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		// 条件更新防止并发请求用同一个令牌轮换两次
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now().UTC(), "replaced_by": next.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenRevoked
+		}
+		return nil
+	})
+}
+
+func (r *tokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now().UTC()).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(ctx context.Context, token *models.RevokedToken) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *tokenRepository) AccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *tokenRepository) CreateNonce(ctx context.Context, nonce *models.AuthNonce) error {
+	return r.db.WithContext(ctx).Create(nonce).Error
+}
+
+func (r *tokenRepository) ConsumeNonce(ctx context.Context, nonce string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.AuthNonce{}).
+		Where("nonce = ? AND used_at IS NULL AND expires_at > ?", nonce, now).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *tokenRepository) PurgeExpired(ctx context.Context, now time.Time) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("expires_at < ?", now).Delete(&models.AuthNonce{}).Error; err != nil {
+		return err
+	}
+	return db.Where("expires_at < ?", now).Delete(&models.RefreshToken{}).Error
+}
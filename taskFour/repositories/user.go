@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+
+	"taskFour/models"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository 用户仓储
+type UserRepository interface {
+	Find(ctx context.Context, id uint) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	// Exists 用户名或邮箱是否已被使用，邮箱也可能是其他账号已验证的附加身份，未验证的邮箱身份不占用地址
+	Exists(ctx context.Context, username, email string) (bool, error)
+	// Create 创建用户，密码在保存前加密，未指定角色时为普通用户
+	Create(ctx context.Context, user *models.User) error
+	// List 按 ID 正序分页
+	List(ctx context.Context, offset, limit int) ([]models.User, error)
+	UpdateRole(ctx context.Context, user *models.User, role string) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建基于 GORM 的用户仓储
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Find(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Exists(ctx context.Context, username, email string) (bool, error) {
+	db := r.db.WithContext(ctx)
+	var count int64
+	if err := db.Model(&models.User{}).Where("username = ? OR email = ?", username, email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err := db.Model(&models.UserIdentity{}).
+		Where("provider = ? AND subject = ? AND verified_at IS NOT NULL", models.IdentityEmail, email).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *userRepository) List(ctx context.Context, offset, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Order("id asc").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, user *models.User, role string) error {
+	return r.db.WithContext(ctx).Model(user).Update("role", role).Error
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/config"
+	"taskFour/metrics"
+	"taskFour/models"
+	"taskFour/moderation"
+	"taskFour/repositories"
+)
+
+// CreateCommentParams 发表评论的参数，ParentID 不为空时回复该评论
+type CreateCommentParams struct {
+	Content  string
+	PostID   uint
+	ParentID *uint
+}
+
+// CommentService 评论和评论审核的业务规则
+type CommentService struct {
+	comments repositories.CommentRepository
+	posts    repositories.PostRepository
+	users    repositories.UserRepository
+	check    moderation.CheckFunc
+}
+
+// NewCommentService 创建评论服务，check 为写入前的反垃圾检查
+func NewCommentService(comments repositories.CommentRepository, posts repositories.PostRepository, users repositories.UserRepository, check moderation.CheckFunc) *CommentService {
+	return &CommentService{comments: comments, posts: posts, users: users, check: check}
+}
+
+// Create 对公开可见的文章发表评论，回复的嵌套层数不能超过 config.CommentMaxDepth
+func (s *CommentService) Create(ctx context.Context, userID uint, params CreateCommentParams) (*models.Comment, error) {
+	post, err := s.posts.Find(ctx, params.PostID)
+	if err != nil {
+		return nil, lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	// 只能评论已发布的文章
+	if !post.IsVisible(time.Now().UTC()) {
+		return nil, apperr.NotFound("Post not found")
+	}
+
+	comment := &models.Comment{
+		Content: params.Content,
+		UserID:  userID,
+		PostID:  params.PostID,
+	}
+
+	// 回复评论：父评论必须属于同一篇文章、审核通过且未被删除
+	if params.ParentID != nil {
+		parent, err := s.comments.Find(ctx, *params.ParentID)
+		if err != nil {
+			return nil, lookupError(err, "Parent comment not found", "Failed to fetch comment")
+		}
+		if parent.PostID != params.PostID || parent.Status != models.CommentStatusApproved {
+			return nil, apperr.NotFound("Parent comment not found")
+		}
+		if parent.Deleted {
+			return nil, apperr.BadRequest("Cannot reply to a deleted comment")
+		}
+		if parent.Depth+1 > config.CommentMaxDepth {
+			return nil, apperr.BadRequest("Maximum reply depth exceeded")
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	if err := s.checkSpam(ctx, comment); err != nil {
+		return nil, err
+	}
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return nil, apperr.Internal("Failed to create comment", err)
+	}
+	metrics.CommentsCreated.WithLabelValues(comment.Status).Inc()
+	return comment, nil
+}
+
+// ListForPost 文章下审核通过的评论按顶级评论分页，见 repositories.CommentRepository.ListThreads，已删除评论的作者被隐藏
+func (s *CommentService) ListForPost(ctx context.Context, viewerID, postID uint, page repositories.PageRequest) (repositories.Page[models.Comment], error) {
+	post, err := s.posts.Find(ctx, postID)
+	if err != nil {
+		return repositories.Page[models.Comment]{}, lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	ok, err := canViewPost(ctx, s.users, viewerID, post)
+	if err != nil {
+		return repositories.Page[models.Comment]{}, err
+	}
+	if !ok {
+		return repositories.Page[models.Comment]{}, apperr.NotFound("Post not found")
+	}
+
+	comments, err := s.comments.ListThreads(ctx, postID, page)
+	if err != nil {
+		return comments, listError(err, "Failed to fetch comments")
+	}
+	for i := range comments.Items {
+		comments.Items[i].Redact()
+	}
+	return comments, nil
+}
+
+// Update 作者在 config.CommentEditWindow 内修改评论内容，新内容重新经过反垃圾检查
+func (s *CommentService) Update(ctx context.Context, userID, id uint, content string) (*models.Comment, error) {
+	comment, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只有作者可以修改评论内容
+	if comment.UserID != userID {
+		return nil, apperr.Forbidden("You can only edit your own comments")
+	}
+
+	now := time.Now().UTC()
+	if config.CommentEditWindow > 0 && now.Sub(comment.CreatedAt) > config.CommentEditWindow {
+		return nil, apperr.Forbidden("Comment can no longer be edited")
+	}
+	if content == comment.Content {
+		return comment, nil
+	}
+
+	comment.Content = content
+	comment.EditedAt = &now
+	if err := s.checkSpam(ctx, comment); err != nil {
+		return nil, err
+	}
+	if err := s.comments.Update(ctx, comment); err != nil {
+		return nil, apperr.Internal("Failed to update comment", err)
+	}
+	return comment, nil
+}
+
+// Delete 评论作者、文章作者或拥有 comment:moderate 权限的用户可以删除评论
+func (s *CommentService) Delete(ctx context.Context, userID, id uint) error {
+	comment, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if comment.UserID != userID && comment.Post.UserID != userID {
+		ok, err := hasPermission(ctx, s.users, userID, models.PermCommentModerate)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperr.Forbidden("You can only delete your own comments or comments on your posts")
+		}
+	}
+
+	if err := s.comments.Delete(ctx, comment); err != nil {
+		return apperr.Internal("Failed to delete comment", err)
+	}
+	return nil
+}
+
+// ModerationQueue 待审核或被判定为垃圾的评论及总数，按创建时间正序
+func (s *CommentService) ModerationQueue(ctx context.Context, status string, offset, limit int) ([]models.Comment, int64, error) {
+	if status != models.CommentStatusPending && status != models.CommentStatusSpam {
+		return nil, 0, apperr.BadRequest("status must be pending or spam")
+	}
+	comments, total, err := s.comments.ListByStatus(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, apperr.Internal("Failed to fetch comments", err)
+	}
+	return comments, total, nil
+}
+
+// Approve 把待审核或被误判为垃圾的评论标记为通过，返回实际更新的数量
+func (s *CommentService) Approve(ctx context.Context, ids []uint) (int64, error) {
+	return s.moderate(ctx, ids, []string{models.CommentStatusPending, models.CommentStatusSpam}, models.CommentStatusApproved)
+}
+
+// Reject 把待审核的评论标记为垃圾，返回实际更新的数量
+func (s *CommentService) Reject(ctx context.Context, ids []uint) (int64, error) {
+	return s.moderate(ctx, ids, []string{models.CommentStatusPending}, models.CommentStatusSpam)
+}
+
+// ModerateDelete 审核人员删除任意评论
+func (s *CommentService) ModerateDelete(ctx context.Context, id uint) error {
+	comment, err := s.comments.Find(ctx, id)
+	if err != nil {
+		return lookupError(err, "Comment not found", "Failed to fetch comment")
+	}
+	if err := s.comments.Delete(ctx, comment); err != nil {
+		return apperr.Internal("Failed to delete comment", err)
+	}
+	return nil
+}
+
+func (s *CommentService) moderate(ctx context.Context, ids []uint, from []string, to string) (int64, error) {
+	updated, err := s.comments.UpdateStatus(ctx, ids, from, to)
+	if err != nil {
+		return 0, apperr.Internal("Failed to update comments", err)
+	}
+	return updated, nil
+}
+
+// find 查询评论，已删除的占位评论视为不存在
+func (s *CommentService) find(ctx context.Context, id uint) (*models.Comment, error) {
+	comment, err := s.comments.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Comment not found", "Failed to fetch comment")
+	}
+	if comment.Deleted {
+		return nil, apperr.NotFound("Comment not found")
+	}
+	return comment, nil
+}
+
+// checkSpam 对评论执行反垃圾检查并写入审核状态，拥有 comment:moderate 权限的用户直接通过
+func (s *CommentService) checkSpam(ctx context.Context, comment *models.Comment) error {
+	author, err := s.users.Find(ctx, comment.UserID)
+	if err != nil {
+		return apperr.Internal("Failed to fetch user", err)
+	}
+	if author.HasPermission(models.PermCommentModerate) {
+		comment.Status = models.CommentStatusApproved
+		comment.ModerationNote = ""
+		return nil
+	}
+
+	result, err := s.check(ctx, comment, author)
+	if err != nil {
+		return apperr.Internal("Failed to check comment", err)
+	}
+	comment.Status = result.Status
+	comment.ModerationNote = result.Reason
+	return nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+// ErrIdentityLinked 身份已被某个账号关联
+var ErrIdentityLinked = apperr.Conflict("Identity is already linked to an account")
+
+// IdentityService 账号关联的密码、邮箱和钱包身份，以及钱包登录（Sign-In with Ethereum）
+type IdentityService struct {
+	identities repositories.IdentityRepository
+	users      repositories.UserRepository
+	tokens     repositories.TokenRepository
+}
+
+// NewIdentityService 创建身份服务，tokens 用于保存钱包登录的随机数
+func NewIdentityService(identities repositories.IdentityRepository, users repositories.UserRepository, tokens repositories.TokenRepository) *IdentityService {
+	return &IdentityService{identities: identities, users: users, tokens: tokens}
+}
+
+// List 账号的全部身份
+func (s *IdentityService) List(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	identities, err := s.identities.List(ctx, userID)
+	if err != nil {
+		return nil, apperr.Internal("Failed to fetch identities", err)
+	}
+	return identities, nil
+}
+
+// LinkWallet 校验签名消息并把钱包地址关联到账号
+func (s *IdentityService) LinkWallet(ctx context.Context, userID uint, message, signature string) (*models.UserIdentity, error) {
+	address, err := s.verifySIWE(ctx, message, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	identity := &models.UserIdentity{
+		UserID:     userID,
+		Provider:   models.IdentityEthereum,
+		Subject:    address,
+		VerifiedAt: &now,
+	}
+	return identity, s.link(ctx, identity)
+}
+
+// LinkPassword 为没有密码的账号（如钱包登录创建的账号）设置密码，之后可以用用户名和密码登录
+func (s *IdentityService) LinkPassword(ctx context.Context, userID uint, password string) (*models.UserIdentity, error) {
+	user, err := s.users.Find(ctx, userID)
+	if err != nil {
+		return nil, lookupError(err, "User not found", "Failed to fetch user")
+	}
+
+	now := time.Now().UTC()
+	identity := &models.UserIdentity{
+		UserID:     userID,
+		Provider:   models.IdentityPassword,
+		Subject:    user.Username,
+		VerifiedAt: &now,
+	}
+	err = s.identities.CreatePassword(ctx, identity, password)
+	if errors.Is(err, repositories.ErrPasswordSet) {
+		return nil, apperr.Conflict("Password is already set")
+	}
+	if err != nil {
+		return nil, apperr.Internal("Failed to link identity", err)
+	}
+	return identity, nil
+}
+
+// LinkEmail 为账号添加邮箱。邮箱未经验证，不会设为主邮箱，也不占用地址：
+// 其他账号注册或添加同一邮箱时会替换它
+func (s *IdentityService) LinkEmail(ctx context.Context, userID uint, email string) (*models.UserIdentity, error) {
+	// 主邮箱在 users 表上也有唯一索引，被其他账号占用时同样视为冲突
+	taken, err := s.identities.EmailTaken(ctx, email, userID)
+	if err != nil {
+		return nil, apperr.Internal("Database error", err)
+	}
+	if taken {
+		return nil, ErrIdentityLinked
+	}
+
+	identity := &models.UserIdentity{
+		UserID:   userID,
+		Provider: models.IdentityEmail,
+		Subject:  email,
+	}
+	return identity, s.link(ctx, identity)
+}
+
+// link 创建身份，身份已被任意账号关联时返回 409
+func (s *IdentityService) link(ctx context.Context, identity *models.UserIdentity) error {
+	linked, err := s.identities.Linked(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return apperr.Internal("Database error", err)
+	}
+	if linked {
+		return ErrIdentityLinked
+	}
+	if err := s.identities.Create(ctx, identity); err != nil {
+		return apperr.Internal("Failed to link identity", err)
+	}
+	return nil
+}
+
+// Unlink 解除账号的某个身份，不能删除最后一个可登录的身份（密码或钱包）
+func (s *IdentityService) Unlink(ctx context.Context, userID, id uint) error {
+	identity, err := s.identities.Find(ctx, id, userID)
+	if err != nil {
+		return lookupError(err, "Identity not found", "Failed to fetch identity")
+	}
+
+	err = s.identities.Delete(ctx, identity)
+	if errors.Is(err, repositories.ErrLastLoginMethod) {
+		return apperr.BadRequest("Cannot remove the last login method")
+	}
+	if err != nil {
+		return lookupError(err, "Identity not found", "Failed to delete identity")
+	}
+	return nil
+}
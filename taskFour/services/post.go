@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/jobs"
+	"taskFour/metrics"
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+// ErrVersionRequired 修改文章时没有指定基于的版本
+var ErrVersionRequired = apperr.PreconditionRequired("If-Match header or version is required")
+
+// ErrScheduleInPast 定时发布的文章没有指定未来的发布时间
+var ErrScheduleInPast = apperr.BadRequest("published_at must be in the future for scheduled posts")
+
+// CreatePostParams 创建文章的参数，Status 为空时保存为草稿
+type CreatePostParams struct {
+	Title       string
+	Content     string
+	Status      string
+	PublishedAt *time.Time
+	Tags        []string
+	CategoryID  *uint
+}
+
+// UpdatePostParams 修改文章的参数，零值字段保持不变。Version 为修改基于的版本号，
+// 与文章当前版本不一致时返回 412，没有指定时返回 428；AnyVersion 为 true 时不检查版本（If-Match: *）
+type UpdatePostParams struct {
+	Version     *uint
+	AnyVersion  bool
+	Title       string
+	Content     string
+	Status      string
+	PublishedAt *time.Time
+	Tags        *[]string
+	CategoryID  *uint
+}
+
+// PostFields 文章中可由客户端修改的字段，Tags 为标签名称
+type PostFields struct {
+	Title       string
+	Content     string
+	Status      string
+	PublishedAt *time.Time
+	Tags        []string
+	CategoryID  *uint
+}
+
+// PatchPostParams 部分修改文章的参数，Version 和 AnyVersion 与 UpdatePostParams 相同。
+// Patch 由文章当前的字段计算修改后的字段，可以清空分类和标签，返回的错误原样返回给调用方
+type PatchPostParams struct {
+	Version    *uint
+	AnyVersion bool
+	Patch      func(PostFields) (PostFields, error)
+}
+
+// PostService 文章的业务规则
+type PostService struct {
+	posts    repositories.PostRepository
+	comments repositories.CommentRepository
+	users    repositories.UserRepository
+}
+
+// NewPostService 创建文章服务
+func NewPostService(posts repositories.PostRepository, comments repositories.CommentRepository, users repositories.UserRepository) *PostService {
+	return &PostService{posts: posts, comments: comments, users: users}
+}
+
+// Published 公开可见的文章
+func (s *PostService) Published(ctx context.Context, filter repositories.PostFilter, page repositories.PageRequest) (repositories.Page[models.Post], error) {
+	now := time.Now().UTC()
+	filter.VisibleAt = &now
+	posts, err := s.posts.List(ctx, filter, page)
+	if err != nil {
+		return posts, listError(err, "Failed to fetch posts")
+	}
+	return posts, nil
+}
+
+// ByAuthor 作者的全部文章，包括草稿、定时和归档文章
+func (s *PostService) ByAuthor(ctx context.Context, authorID uint, filter repositories.PostFilter, page repositories.PageRequest) (repositories.Page[models.Post], error) {
+	filter.AuthorID = authorID
+	posts, err := s.posts.List(ctx, filter, page)
+	if err != nil {
+		return posts, listError(err, "Failed to fetch posts")
+	}
+	return posts, nil
+}
+
+// Get 文章详情及审核通过的评论，viewerID 为 0 表示未登录，看不到的文章按不存在处理
+func (s *PostService) Get(ctx context.Context, viewerID, id uint) (*models.Post, error) {
+	post, err := s.viewable(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.comments.ListApproved(ctx, post.ID)
+	if err != nil {
+		return nil, apperr.Internal("Failed to fetch post", err)
+	}
+	for i := range comments {
+		comments[i].Redact()
+	}
+	post.Comments = comments
+	return post, nil
+}
+
+// Create 创建文章并保存第一个历史版本
+func (s *PostService) Create(ctx context.Context, authorID uint, params CreatePostParams) (*models.Post, error) {
+	post := &models.Post{
+		Title:      params.Title,
+		Content:    params.Content,
+		UserID:     authorID,
+		CategoryID: params.CategoryID,
+	}
+
+	status := params.Status
+	if status == "" {
+		status = models.PostStatusDraft
+	}
+	if err := applyPostStatus(post, status, params.PublishedAt, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if err := s.posts.Create(ctx, post, params.Tags); err != nil {
+		return nil, postInputError(err, "Failed to create post")
+	}
+
+	metrics.PostsCreated.WithLabelValues(post.Status).Inc()
+	if post.Status == models.PostStatusScheduled {
+		jobs.NotifyScheduleChanged()
+	}
+	return post, nil
+}
+
+// Update 修改文章，作者本人或拥有 post:update:any 权限的用户可以修改
+func (s *PostService) Update(ctx context.Context, userID, id uint, params UpdatePostParams) (*models.Post, error) {
+	before, err := s.posts.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	if err := s.checkOwner(ctx, userID, before, models.PermPostUpdateAny, "You can only update your own posts"); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(before, params.Version, params.AnyVersion); err != nil {
+		return nil, err
+	}
+
+	after := *before
+	if params.Title != "" {
+		after.Title = params.Title
+	}
+	if params.Content != "" {
+		after.Content = params.Content
+	}
+	scheduleChanged := false
+	if params.Status != "" || params.PublishedAt != nil {
+		status := params.Status
+		if status == "" {
+			status = before.Status
+		}
+		if err := applyPostStatus(&after, status, params.PublishedAt, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+		scheduleChanged = before.Status == models.PostStatusScheduled || after.Status == models.PostStatusScheduled
+	}
+	if params.CategoryID != nil {
+		after.CategoryID = params.CategoryID
+	}
+	return s.save(ctx, userID, before, &after, params.Tags, scheduleChanged)
+}
+
+// Patch 按 params.Patch 的结果修改文章，权限和版本检查与 Update 相同。
+// 状态或发布时间有变化时按新状态重新计算发布时间，标签有变化时整体替换
+func (s *PostService) Patch(ctx context.Context, userID, id uint, params PatchPostParams) (*models.Post, error) {
+	before, err := s.posts.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	if err := s.checkOwner(ctx, userID, before, models.PermPostUpdateAny, "You can only update your own posts"); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(before, params.Version, params.AnyVersion); err != nil {
+		return nil, err
+	}
+
+	current := postFields(before)
+	fields, err := params.Patch(current)
+	if err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.Title = fields.Title
+	after.Content = fields.Content
+	after.CategoryID = fields.CategoryID
+	scheduleChanged := false
+	if fields.Status != before.Status || !sameTime(fields.PublishedAt, before.PublishedAt) {
+		if err := applyPostStatus(&after, fields.Status, fields.PublishedAt, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+		scheduleChanged = before.Status == models.PostStatusScheduled || after.Status == models.PostStatusScheduled
+	}
+	var tags *[]string
+	if !slices.Equal(fields.Tags, current.Tags) {
+		tags = &fields.Tags
+	}
+	return s.save(ctx, userID, before, &after, tags, scheduleChanged)
+}
+
+// save 保存 Update 和 Patch 的修改，文章在读取之后被其他请求修改时返回 412
+func (s *PostService) save(ctx context.Context, userID uint, before, after *models.Post, tags *[]string, scheduleChanged bool) (*models.Post, error) {
+	if err := s.posts.Update(ctx, before, after, tags, userID); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, s.modified(ctx, before.ID)
+		}
+		return nil, postInputError(err, "Failed to update post")
+	}
+	if scheduleChanged {
+		jobs.NotifyScheduleChanged()
+	}
+	return after, nil
+}
+
+// Revisions 文章的全部历史版本，按版本号倒序，看不到的文章按不存在处理
+func (s *PostService) Revisions(ctx context.Context, viewerID, id uint) ([]models.PostRevision, error) {
+	if _, err := s.viewable(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	revisions, err := s.posts.Revisions(ctx, id)
+	if err != nil {
+		return nil, apperr.Internal("Failed to fetch revisions", err)
+	}
+	return revisions, nil
+}
+
+// RevisionPair 文章的第 from 和第 to 个历史版本，用于比较差异，可见性与 Revisions 相同
+func (s *PostService) RevisionPair(ctx context.Context, viewerID, id uint, from, to int) (*models.PostRevision, *models.PostRevision, error) {
+	if _, err := s.viewable(ctx, viewerID, id); err != nil {
+		return nil, nil, err
+	}
+	fromRev, err := s.posts.Revision(ctx, id, from)
+	if err != nil {
+		return nil, nil, lookupError(err, "Revision not found", "Failed to fetch revision")
+	}
+	toRev, err := s.posts.Revision(ctx, id, to)
+	if err != nil {
+		return nil, nil, lookupError(err, "Revision not found", "Failed to fetch revision")
+	}
+	return fromRev, toRev, nil
+}
+
+// RestoreRevisionParams 恢复历史版本的参数，Version 和 AnyVersion 与 UpdatePostParams 相同
+type RestoreRevisionParams struct {
+	Rev        int
+	Version    *uint
+	AnyVersion bool
+}
+
+// RestoreRevision 用第 params.Rev 个历史版本的标题和内容覆盖文章并保存为新版本，只有作者本人可以恢复，
+// 版本检查与 Update 相同。返回恢复后的文章和新保存的版本
+func (s *PostService) RestoreRevision(ctx context.Context, userID, id uint, params RestoreRevisionParams) (*models.Post, *models.PostRevision, error) {
+	post, err := s.posts.Find(ctx, id)
+	if err != nil {
+		return nil, nil, lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	if post.UserID != userID {
+		return nil, nil, apperr.Forbidden("You can only restore your own posts")
+	}
+	if err := checkVersion(post, params.Version, params.AnyVersion); err != nil {
+		return nil, nil, err
+	}
+
+	revision, err := s.posts.Revision(ctx, id, params.Rev)
+	if err != nil {
+		return nil, nil, lookupError(err, "Revision not found", "Failed to fetch revision")
+	}
+	created, err := s.posts.Restore(ctx, post, revision, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, nil, s.modified(ctx, id)
+		}
+		return nil, nil, apperr.Internal("Failed to restore revision", err)
+	}
+	return post, created, nil
+}
+
+// Delete 删除文章，作者本人或拥有 post:delete:any 权限的用户可以删除
+func (s *PostService) Delete(ctx context.Context, userID, id uint) error {
+	post, err := s.posts.Find(ctx, id)
+	if err != nil {
+		return lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	if err := s.checkOwner(ctx, userID, post, models.PermPostDeleteAny, "You can only delete your own posts"); err != nil {
+		return err
+	}
+	if err := s.posts.Delete(ctx, post); err != nil {
+		return apperr.Internal("Failed to delete post", err)
+	}
+	return nil
+}
+
+// checkVersion 检查修改基于的版本是否为文章的当前版本，anyVersion 为 true 时不检查
+func checkVersion(post *models.Post, version *uint, anyVersion bool) error {
+	if anyVersion {
+		return nil
+	}
+	if version == nil {
+		return ErrVersionRequired
+	}
+	if *version != post.Version {
+		return apperr.PreconditionFailed("Post has been modified", post)
+	}
+	return nil
+}
+
+// postFields 文章当前可修改的字段
+func postFields(post *models.Post) PostFields {
+	tags := make([]string, 0, len(post.Tags))
+	for _, tag := range post.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return PostFields{
+		Title:       post.Title,
+		Content:     post.Content,
+		Status:      post.Status,
+		PublishedAt: post.PublishedAt,
+		Tags:        tags,
+		CategoryID:  post.CategoryID,
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// viewable 读取 viewerID 可以查看的文章，看不到的文章按不存在处理
+func (s *PostService) viewable(ctx context.Context, viewerID, id uint) (*models.Post, error) {
+	post, err := s.posts.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	ok, err := canViewPost(ctx, s.users, viewerID, post)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperr.NotFound("Post not found")
+	}
+	return post, nil
+}
+
+// modified 读取之后文章被其他请求修改，返回带有文章当前状态的 412
+func (s *PostService) modified(ctx context.Context, id uint) error {
+	current, err := s.posts.Find(ctx, id)
+	if err != nil {
+		return lookupError(err, "Post not found", "Failed to fetch post")
+	}
+	return apperr.PreconditionFailed("Post has been modified", current)
+}
+
+// checkOwner 作者本人或拥有 permission 权限的编辑/管理员才能操作文章
+func (s *PostService) checkOwner(ctx context.Context, userID uint, post *models.Post, permission, message string) error {
+	if post.UserID == userID {
+		return nil
+	}
+	ok, err := hasPermission(ctx, s.users, userID, permission)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperr.Forbidden(message)
+	}
+	return nil
+}
+
+// applyPostStatus 按目标状态设置文章的状态和发布时间
+func applyPostStatus(post *models.Post, status string, publishedAt *time.Time, now time.Time) error {
+	switch status {
+	case models.PostStatusDraft:
+		post.PublishedAt = nil
+	case models.PostStatusPublished:
+		// 重新发布时保留第一次的发布时间
+		if (post.Status != models.PostStatusPublished && post.Status != models.PostStatusArchived) || post.PublishedAt == nil {
+			post.PublishedAt = &now
+		}
+	case models.PostStatusScheduled:
+		if publishedAt == nil || !publishedAt.After(now) {
+			return ErrScheduleInPast
+		}
+		t := publishedAt.UTC()
+		post.PublishedAt = &t
+	case models.PostStatusArchived:
+	}
+	post.Status = status
+	return nil
+}
+
+// postInputError 把文章输入相关的业务错误转换为 400，其他错误按 message 返回内部错误
+func postInputError(err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrCategoryNotFound):
+		return apperr.BadRequest("Category not found")
+	case errors.Is(err, models.ErrInvalidTagName):
+		return apperr.BadRequest("Invalid tag name")
+	default:
+		return apperr.Internal(message, err)
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+// 搜索范围
+const (
+	SearchAll      = "all"
+	SearchPosts    = "posts"
+	SearchComments = "comments"
+)
+
+// SearchResult 搜索结果，没有搜索的范围为 nil
+type SearchResult struct {
+	Posts    []models.PostSearchResult
+	Comments []models.CommentSearchResult
+}
+
+// SearchService 文章和评论的全文搜索，只返回当前公开可见的内容
+type SearchService struct {
+	posts    repositories.PostRepository
+	comments repositories.CommentRepository
+}
+
+// NewSearchService 创建搜索服务
+func NewSearchService(posts repositories.PostRepository, comments repositories.CommentRepository) *SearchService {
+	return &SearchService{posts: posts, comments: comments}
+}
+
+// Search 在 scope 范围（SearchAll、SearchPosts 或 SearchComments）内搜索，文章和评论分别分页
+func (s *SearchService) Search(ctx context.Context, query, scope string, offset, limit int) (SearchResult, error) {
+	var result SearchResult
+	now := time.Now().UTC()
+
+	if scope != SearchComments {
+		posts, err := s.posts.Search(ctx, query, now, offset, limit)
+		if err != nil {
+			return result, apperr.Internal("Failed to search posts", err)
+		}
+		result.Posts = posts
+	}
+
+	if scope != SearchPosts {
+		comments, err := s.comments.Search(ctx, query, now, offset, limit)
+		if err != nil {
+			return result, apperr.Internal("Failed to search comments", err)
+		}
+		result.Comments = comments
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+// Package services 业务规则：资源是否存在、所有权和权限检查、状态流转。
+// 服务只依赖 repositories 中的接口，返回的错误都是 *apperr.Error，控制器直接交给 c.Error
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/models"
+	"taskFour/repositories"
+)
+
+// hasPermission 按用户当前的角色判断权限，角色每次从仓储读取，修改后立即生效。userID 为 0 表示未登录
+func hasPermission(ctx context.Context, users repositories.UserRepository, userID uint, permission string) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+	user, err := users.Find(ctx, userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperr.Internal("Failed to load user role", err)
+	}
+	return user.HasPermission(permission), nil
+}
+
+// canViewPost 已发布的文章所有人可见，其他状态只有作者和拥有 post:update:any 权限的用户可见
+func canViewPost(ctx context.Context, users repositories.UserRepository, viewerID uint, post *models.Post) (bool, error) {
+	if post.IsVisible(time.Now().UTC()) || (viewerID != 0 && post.UserID == viewerID) {
+		return true, nil
+	}
+	return hasPermission(ctx, users, viewerID, models.PermPostUpdateAny)
+}
+
+// lookupError 仓储查询的错误，记录不存在时返回 404，其他错误按 message 返回内部错误
+func lookupError(err error, notFound, message string) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return apperr.NotFound(notFound)
+	}
+	return apperr.Internal(message, err)
+}
+
+// ErrInvalidCursor 分页游标无效或与排序方式不一致
+var ErrInvalidCursor = apperr.BadRequest("Invalid cursor")
+
+// listError 列表查询的错误，游标无效时返回 400
+func listError(err error, message string) error {
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return ErrInvalidCursor
+	}
+	return apperr.Internal(message, err)
+}
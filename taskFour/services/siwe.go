@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/config"
+	"taskFour/metrics"
+	"taskFour/models"
+	"taskFour/repositories"
+	"taskFour/utils"
+)
+
+// NewNonce 生成钱包登录使用的一次性随机数
+func (s *IdentityService) NewNonce(ctx context.Context) (*models.AuthNonce, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, apperr.Internal("Failed to generate nonce", err)
+	}
+
+	nonce := &models.AuthNonce{
+		Nonce:     hex.EncodeToString(b),
+		ExpiresAt: time.Now().UTC().Add(config.SIWENonceTTL),
+	}
+	if err := s.tokens.CreateNonce(ctx, nonce); err != nil {
+		return nil, apperr.Internal("Failed to generate nonce", err)
+	}
+	return nonce, nil
+}
+
+// SignInWithEthereum 校验签名消息，返回钱包身份对应的账号，首次登录时自动创建
+func (s *IdentityService) SignInWithEthereum(ctx context.Context, message, signature string) (*models.User, error) {
+	address, err := s.verifySIWE(ctx, message, signature)
+	if err != nil {
+		var appErr *apperr.Error
+		if errors.As(err, &appErr) && appErr.Code == apperr.CodeInvalidCredentials {
+			metrics.LoginFailures.WithLabelValues("siwe").Inc()
+		}
+		return nil, err
+	}
+	return s.findOrCreateWalletUser(ctx, address)
+}
+
+// findOrCreateWalletUser 根据钱包身份查找账号，首次登录时创建以地址为用户名、没有密码和邮箱的账号。
+// 注册时不允许使用地址形式的用户名；并发的首次登录只有一个能创建成功，其余的重新读取身份
+func (s *IdentityService) findOrCreateWalletUser(ctx context.Context, address string) (*models.User, error) {
+	user, err := s.identities.FindUser(ctx, models.IdentityEthereum, address)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperr.Internal("Failed to load user", err)
+	}
+
+	now := time.Now().UTC()
+	user = &models.User{Username: address}
+	err = s.identities.CreateUser(ctx, user, &models.UserIdentity{
+		Provider:   models.IdentityEthereum,
+		Subject:    address,
+		VerifiedAt: &now,
+	})
+	if err == nil {
+		return user, nil
+	}
+	if existing, findErr := s.identities.FindUser(ctx, models.IdentityEthereum, address); findErr == nil {
+		return existing, nil
+	}
+	// 用户名在禁止地址形式的用户名之前已被注册
+	if _, findErr := s.users.FindByUsername(ctx, address); findErr == nil {
+		return nil, apperr.Wrap(apperr.CodeConflict, "Username or email already exists", err)
+	}
+	return nil, apperr.Internal("Failed to create user", err)
+}
+
+// siweError 签名消息校验失败的原因转换为固定的错误信息，原始错误只记录在日志中
+func siweError(err error) *apperr.Error {
+	message := "Invalid signature"
+	switch {
+	case errors.Is(err, utils.ErrSIWEDomainMismatch):
+		message = "SIWE message domain mismatch"
+	case errors.Is(err, utils.ErrSIWEChainMismatch):
+		message = "SIWE message chain ID mismatch"
+	case errors.Is(err, utils.ErrSIWEExpired):
+		message = "SIWE message has expired"
+	case errors.Is(err, utils.ErrSIWENotYetValid):
+		message = "SIWE message is not yet valid"
+	}
+	return apperr.Wrap(apperr.CodeInvalidCredentials, message, err)
+}
+
+// verifySIWE 解析并校验签名消息，成功时消耗随机数并返回钱包地址
+func (s *IdentityService) verifySIWE(ctx context.Context, message, signature string) (string, error) {
+	msg, err := utils.ParseSIWEMessage(message)
+	if err != nil {
+		return "", apperr.Wrap(apperr.CodeInvalidRequest, "Invalid SIWE message", err)
+	}
+
+	now := time.Now().UTC()
+	if err := msg.Verify(message, signature, config.SIWEDomain, int64(config.SIWEChainID), now); err != nil {
+		return "", siweError(err)
+	}
+
+	// 随机数只能使用一次
+	consumed, err := s.tokens.ConsumeNonce(ctx, msg.Nonce, now)
+	if err != nil {
+		return "", apperr.Internal("Database error", err)
+	}
+	if !consumed {
+		return "", apperr.New(apperr.CodeInvalidCredentials, "Invalid or expired nonce")
+	}
+
+	return msg.Address, nil
+}
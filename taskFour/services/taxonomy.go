@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/models"
+	"taskFour/repositories"
+	"taskFour/utils"
+)
+
+// ErrCategoryCycle 分类不能移动到自身或子孙分类下
+var ErrCategoryCycle = apperr.BadRequest("Category cannot be moved under itself or its descendants")
+
+// TaxonomyService 分类和标签管理的业务规则，slug 未指定时由名称生成
+type TaxonomyService struct {
+	categories repositories.CategoryRepository
+	tags       repositories.TagRepository
+}
+
+// NewTaxonomyService 创建分类和标签服务
+func NewTaxonomyService(categories repositories.CategoryRepository, tags repositories.TagRepository) *TaxonomyService {
+	return &TaxonomyService{categories: categories, tags: tags}
+}
+
+// CategoryTree 全部分类组装成的树
+func (s *TaxonomyService) CategoryTree(ctx context.Context) ([]models.Category, error) {
+	tree, err := s.categories.Tree(ctx)
+	if err != nil {
+		return nil, apperr.Internal("Failed to fetch categories", err)
+	}
+	return tree, nil
+}
+
+// CreateCategory 创建分类，parentID 为 nil 时是顶级分类
+func (s *TaxonomyService) CreateCategory(ctx context.Context, name, slug string, parentID *uint) (*models.Category, error) {
+	category := &models.Category{Name: name, Slug: taxonomySlug(name, slug), ParentID: parentID}
+	if err := s.checkCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Create(ctx, category); err != nil {
+		return nil, apperr.Internal("Failed to create category", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory 修改分类名称、slug 和父分类
+func (s *TaxonomyService) UpdateCategory(ctx context.Context, id uint, name, slug string, parentID *uint) (*models.Category, error) {
+	category, err := s.categories.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Category not found", "Failed to fetch category")
+	}
+
+	category.Name = name
+	category.Slug = taxonomySlug(name, slug)
+	category.ParentID = parentID
+	if err := s.checkCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Update(ctx, category); err != nil {
+		return nil, apperr.Internal("Failed to update category", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory 删除分类，子分类和文章移动到它的父分类下
+func (s *TaxonomyService) DeleteCategory(ctx context.Context, id uint) error {
+	category, err := s.categories.Find(ctx, id)
+	if err != nil {
+		return lookupError(err, "Category not found", "Failed to fetch category")
+	}
+	if err := s.categories.Delete(ctx, category); err != nil {
+		return apperr.Internal("Failed to delete category", err)
+	}
+	return nil
+}
+
+// checkCategory 校验 slug 有效且唯一、父分类存在且不会形成环
+func (s *TaxonomyService) checkCategory(ctx context.Context, category *models.Category) error {
+	if category.Slug == "" {
+		return apperr.BadRequest("Invalid category name")
+	}
+	exists, err := s.categories.SlugExists(ctx, category.Slug, category.ID)
+	if err != nil {
+		return apperr.Internal("Database error", err)
+	}
+	if exists {
+		return apperr.Conflict("Category slug already exists")
+	}
+
+	if category.ParentID == nil {
+		return nil
+	}
+	if _, err := s.categories.Find(ctx, *category.ParentID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return apperr.BadRequest("Parent category not found")
+		}
+		return apperr.Internal("Database error", err)
+	}
+
+	// 新建的分类没有子孙，无需检查环
+	if category.ID == 0 {
+		return nil
+	}
+	descendants, err := s.categories.DescendantIDs(ctx, category.ID)
+	if err != nil {
+		return apperr.Internal("Database error", err)
+	}
+	if slices.Contains(descendants, *category.ParentID) {
+		return ErrCategoryCycle
+	}
+	return nil
+}
+
+// Tags 全部标签及每个标签下公开文章的数量
+func (s *TaxonomyService) Tags(ctx context.Context) ([]models.TagWithCount, error) {
+	tags, err := s.tags.ListWithCount(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, apperr.Internal("Failed to fetch tags", err)
+	}
+	return tags, nil
+}
+
+// CreateTag 创建标签
+func (s *TaxonomyService) CreateTag(ctx context.Context, name, slug string) (*models.Tag, error) {
+	tag := &models.Tag{Name: name, Slug: taxonomySlug(name, slug)}
+	if err := s.checkTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	if err := s.tags.Create(ctx, tag); err != nil {
+		return nil, apperr.Internal("Failed to create tag", err)
+	}
+	return tag, nil
+}
+
+// UpdateTag 修改标签名称和 slug
+func (s *TaxonomyService) UpdateTag(ctx context.Context, id uint, name, slug string) (*models.Tag, error) {
+	tag, err := s.tags.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Tag not found", "Failed to fetch tag")
+	}
+
+	tag.Name = name
+	tag.Slug = taxonomySlug(name, slug)
+	if err := s.checkTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	if err := s.tags.Update(ctx, tag); err != nil {
+		return nil, apperr.Internal("Failed to update tag", err)
+	}
+	return tag, nil
+}
+
+// DeleteTag 删除标签并解除它与文章的关联，文章本身不受影响
+func (s *TaxonomyService) DeleteTag(ctx context.Context, id uint) error {
+	tag, err := s.tags.Find(ctx, id)
+	if err != nil {
+		return lookupError(err, "Tag not found", "Failed to fetch tag")
+	}
+	if err := s.tags.Delete(ctx, tag); err != nil {
+		return apperr.Internal("Failed to delete tag", err)
+	}
+	return nil
+}
+
+// checkTag 校验 slug 有效，名称或 slug 已被其他标签使用时返回 409
+func (s *TaxonomyService) checkTag(ctx context.Context, tag *models.Tag) error {
+	if tag.Slug == "" {
+		return apperr.BadRequest("Invalid tag name")
+	}
+	exists, err := s.tags.Exists(ctx, tag.Name, tag.Slug, tag.ID)
+	if err != nil {
+		return apperr.Internal("Database error", err)
+	}
+	if exists {
+		return apperr.Conflict("Tag already exists")
+	}
+	return nil
+}
+
+// taxonomySlug 优先使用显式指定的 slug，否则由名称生成
+func taxonomySlug(name, slug string) string {
+	if slug != "" {
+		return utils.Slugify(slug)
+	}
+	return utils.Slugify(name)
+}
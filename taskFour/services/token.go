@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"taskFour/apperr"
+	"taskFour/config"
+	"taskFour/middleware"
+	"taskFour/models"
+	"taskFour/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌不存在或已过期
+	ErrInvalidRefreshToken = apperr.New(apperr.CodeInvalidToken, "Invalid refresh token")
+	// ErrRefreshTokenReused 已轮换过的刷新令牌被再次使用，整组令牌已被吊销
+	ErrRefreshTokenReused = apperr.New(apperr.CodeTokenReused, "Refresh token reuse detected, please login again")
+)
+
+// TokenPair 访问令牌 + 刷新令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// TokenService 签发、轮换和吊销令牌。刷新令牌只保存哈希值，
+// 同一次登录轮换出来的令牌属于同一个令牌家族，检测到重放时整组吊销
+type TokenService struct {
+	tokens repositories.TokenRepository
+}
+
+// NewTokenService 创建令牌服务
+func NewTokenService(tokens repositories.TokenRepository) *TokenService {
+	return &TokenService{tokens: tokens}
+}
+
+// Issue 登录成功后签发一组新的令牌（新的令牌家族）
+func (s *TokenService) Issue(ctx context.Context, userID uint) (*TokenPair, error) {
+	pair, refresh, err := newTokenPair(userID, uuid.NewString())
+	if err != nil {
+		return nil, apperr.Internal("Failed to generate token", err)
+	}
+	if err := s.tokens.CreateRefreshToken(ctx, refresh); err != nil {
+		return nil, apperr.Internal("Failed to generate token", err)
+	}
+	return pair, nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧的刷新令牌立即失效。
+// 如果旧令牌已经被使用过，说明令牌可能泄露，吊销整个令牌家族
+func (s *TokenService) Refresh(ctx context.Context, rawToken string) (*TokenPair, error) {
+	current, err := s.tokens.FindRefreshToken(ctx, hashToken(rawToken))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, apperr.Internal("Failed to refresh token", err)
+	}
+	if current.RevokedAt != nil {
+		return nil, s.revokeReused(ctx, current)
+	}
+	if time.Now().UTC().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	pair, next, err := newTokenPair(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, apperr.Internal("Failed to refresh token", err)
+	}
+	err = s.tokens.RotateRefreshToken(ctx, current, next)
+	if errors.Is(err, repositories.ErrTokenRevoked) {
+		return nil, s.revokeReused(ctx, current)
+	}
+	if err != nil {
+		return nil, apperr.Internal("Failed to refresh token", err)
+	}
+	return pair, nil
+}
+
+// revokeReused 已轮换过的令牌被再次使用时吊销整个令牌家族
+func (s *TokenService) revokeReused(ctx context.Context, token *models.RefreshToken) error {
+	if err := s.tokens.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return apperr.Internal("Failed to refresh token", err)
+	}
+	return ErrRefreshTokenReused
+}
+
+// Revoke 登出：访问令牌的 jti 加入吊销列表直到它自然过期，refreshToken 不为空时同时吊销它所在的令牌家族。
+// 刷新令牌无效或不属于当前用户时忽略
+func (s *TokenService) Revoke(ctx context.Context, claims *middleware.Claims, refreshToken string) error {
+	revoked := models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if err := s.tokens.RevokeAccessToken(ctx, &revoked); err != nil {
+		return apperr.Internal("Failed to revoke token", err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	token, err := s.tokens.FindRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && token.UserID != claims.UserID) {
+		return nil
+	}
+	if err == nil {
+		err = s.tokens.RevokeFamily(ctx, token.FamilyID)
+	}
+	if err != nil {
+		return apperr.Internal("Failed to revoke refresh token", err)
+	}
+	return nil
+}
+
+// PurgeExpired 清理已过期的吊销记录、刷新令牌和登录随机数
+func (s *TokenService) PurgeExpired(ctx context.Context) error {
+	return s.tokens.PurgeExpired(ctx, time.Now().UTC())
+}
+
+// newTokenPair 生成访问令牌和属于 familyID 的刷新令牌，刷新令牌记录由调用方保存
+func newTokenPair(userID uint, familyID string) (*TokenPair, *models.RefreshToken, error) {
+	accessToken, err := middleware.GenerateToken(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawRefresh, err := newRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh := &models.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashToken(rawRefresh),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().UTC().Add(config.RefreshTokenTTL),
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		ExpiresIn:    int64(config.AccessTokenTTL.Seconds()),
+	}, refresh, nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
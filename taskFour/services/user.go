@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"taskFour/apperr"
+	"taskFour/metrics"
+	"taskFour/models"
+	"taskFour/repositories"
+	"taskFour/utils"
+)
+
+// ErrUsernameReserved 以太坊地址形式的用户名留给钱包登录自动创建的账号
+var ErrUsernameReserved = apperr.BadRequest("Username is reserved for wallet accounts")
+
+// UserService 用户注册、登录和角色管理的业务规则
+type UserService struct {
+	users repositories.UserRepository
+}
+
+// NewUserService 创建用户服务
+func NewUserService(users repositories.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+// Register 注册用户，用户名和邮箱不能已被使用
+func (s *UserService) Register(ctx context.Context, username, password, email string) (*models.User, error) {
+	if utils.IsHexAddress(strings.ToLower(username)) {
+		return nil, ErrUsernameReserved
+	}
+	exists, err := s.users.Exists(ctx, username, email)
+	if err != nil {
+		return nil, apperr.Internal("Database error", err)
+	}
+	if exists {
+		return nil, apperr.BadRequest("Username or email already exists")
+	}
+
+	user := &models.User{
+		Username: username,
+		Password: password,
+		Email:    &email,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, apperr.Internal("Failed to create user", err)
+	}
+	metrics.UsersRegistered.Inc()
+	return user, nil
+}
+
+// Authenticate 校验用户名和密码，用户不存在和密码错误返回相同的错误
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperr.Internal("Database error", err)
+	}
+	if err != nil || user.CheckPassword(password) != nil {
+		metrics.LoginFailures.WithLabelValues("password").Inc()
+		return nil, apperr.New(apperr.CodeInvalidCredentials, "Invalid credentials")
+	}
+	return user, nil
+}
+
+// List 按 ID 正序分页获取用户
+func (s *UserService) List(ctx context.Context, offset, limit int) ([]models.User, error) {
+	users, err := s.users.List(ctx, offset, limit)
+	if err != nil {
+		return nil, apperr.Internal("Failed to fetch users", err)
+	}
+	return users, nil
+}
+
+// UpdateRole 修改用户角色，不能修改自己的角色，防止管理员把自己降级后无人可以管理角色
+func (s *UserService) UpdateRole(ctx context.Context, actorID, id uint, role string) (*models.User, error) {
+	if id == actorID {
+		return nil, apperr.BadRequest("You cannot change your own role")
+	}
+	user, err := s.users.Find(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "User not found", "Failed to fetch user")
+	}
+	if err := s.users.UpdateRole(ctx, user, role); err != nil {
+		return nil, apperr.Internal("Failed to update role", err)
+	}
+	return user, nil
+}
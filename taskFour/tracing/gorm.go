@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin 为每条 GORM 语句创建一个 span，父 span 取自 db.WithContext 传入的 context。
+// span 中记录带占位符的 SQL，不包含参数值
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 为各类操作注册开始和结束 span 的回调
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	system := dbSystem(db.Dialector.Name())
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("*").Register("tracing:before_create", before("create")),
+		callback.Create().After("*").Register("tracing:after_create", after(system)),
+		callback.Query().Before("*").Register("tracing:before_query", before("query")),
+		callback.Query().After("*").Register("tracing:after_query", after(system)),
+		callback.Update().Before("*").Register("tracing:before_update", before("update")),
+		callback.Update().After("*").Register("tracing:after_update", after(system)),
+		callback.Delete().Before("*").Register("tracing:before_delete", before("delete")),
+		callback.Delete().After("*").Register("tracing:after_delete", after(system)),
+		callback.Row().Before("*").Register("tracing:before_row", before("row")),
+		callback.Row().After("*").Register("tracing:after_row", after(system)),
+		callback.Raw().Before("*").Register("tracing:before_raw", before("raw")),
+		callback.Raw().After("*").Register("tracing:after_raw", after(system)),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := otel.Tracer(scopeName).Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBOperationName(operation)),
+		)
+		// 嵌套的查询（如 Preload）以当前 span 为父 span
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func after(system attribute.KeyValue) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			system,
+			semconv.DBQueryText(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if db.Statement.Table != "" {
+			span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+		}
+		if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+}
+
+func dbSystem(dialect string) attribute.KeyValue {
+	switch dialect {
+	case "sqlite":
+		return semconv.DBSystemNameSQLite
+	case "mysql":
+		return semconv.DBSystemNameMySQL
+	case "postgres":
+		return semconv.DBSystemNamePostgreSQL
+	default:
+		return semconv.DBSystemNameKey.String(dialect)
+	}
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffEdit 编辑脚本中的一行，aPos/bPos 为该行之前两侧已消耗的行数
+type diffEdit struct {
+	op   diffOp
+	line string
+	aPos int
+	bPos int
+}
+
+// UnifiedDiff 生成两段文本按行比较的 unified diff，没有差异时返回空字符串
+func UnifiedDiff(a, b, fromFile, toFile string, context int) string {
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	changed := false
+	for _, e := range edits {
+		if e.op != diffEqual {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromFile, toFile)
+
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].op == diffEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// 相邻改动之间的相同行不超过 2*context 时合并为一个 hunk
+		start := max(i-context, 0)
+		last := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != diffEqual {
+				last = j
+			} else if j-last > 2*context {
+				break
+			}
+		}
+		stop := min(last+context+1, len(edits))
+
+		writeHunk(&sb, edits[start:stop])
+		i = stop
+	}
+
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, hunk []diffEdit) {
+	aCount, bCount := 0, 0
+	for _, e := range hunk {
+		if e.op != diffInsert {
+			aCount++
+		}
+		if e.op != diffDelete {
+			bCount++
+		}
+	}
+
+	aStart, bStart := hunk[0].aPos, hunk[0].bPos
+	if aCount > 0 {
+		aStart++
+	}
+	if bCount > 0 {
+		bStart++
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	for _, e := range hunk {
+		switch e.op {
+		case diffEqual:
+			sb.WriteString(" ")
+		case diffDelete:
+			sb.WriteString("-")
+		case diffInsert:
+			sb.WriteString("+")
+		}
+		sb.WriteString(e.line)
+		sb.WriteString("\n")
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines 使用 Myers 算法计算最短编辑脚本
+func diffLines(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// 从终点回溯编辑路径
+	var reversed []diffEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+offset]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, diffEdit{op: diffEqual, line: a[x]})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, diffEdit{op: diffInsert, line: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, diffEdit{op: diffDelete, line: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, diffEdit{op: diffEqual, line: a[x]})
+	}
+
+	edits := make([]diffEdit, len(reversed))
+	aPos, bPos := 0, 0
+	for i := range reversed {
+		e := reversed[len(reversed)-1-i]
+		e.aPos, e.bPos = aPos, bPos
+		if e.op != diffInsert {
+			aPos++
+		}
+		if e.op != diffDelete {
+			bPos++
+		}
+		edits[i] = e
+	}
+	return edits
+}
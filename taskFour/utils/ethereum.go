@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// Keccak256 以太坊使用的 Keccak-256 哈希（不是标准 SHA3-256）
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// IsHexAddress 判断是否为 0x 开头的 20 字节十六进制地址
+func IsHexAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
+
+// ChecksumAddress 按 EIP-55 返回带大小写校验的地址
+func ChecksumAddress(address string) (string, error) {
+	if !IsHexAddress(address) {
+		return "", fmt.Errorf("invalid address: %s", address)
+	}
+
+	lower := strings.ToLower(address[2:])
+	hash := hex.EncodeToString(Keccak256([]byte(lower)))
+
+	result := []byte("0x" + lower)
+	for i := 0; i < len(lower); i++ {
+		if lower[i] >= 'a' && hash[i] >= '8' {
+			result[i+2] = lower[i] - 32
+		}
+	}
+	return string(result), nil
+}
+
+// PersonalSignHash 计算 personal_sign（EIP-191 version 0x45）的消息哈希
+func PersonalSignHash(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return Keccak256([]byte(prefix), message)
+}
+
+// RecoverPersonalSignAddress 从 personal_sign 签名中恢复签名者地址（EIP-55 格式）
+// 签名为 65 字节 r || s || v，v 可以是 0/1 或 27/28
+func RecoverPersonalSignAddress(message []byte, signature string) (string, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if len(sig) != 65 {
+		return "", errors.New("invalid signature length")
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", errors.New("invalid signature recovery id")
+	}
+
+	// 转换为 decred 的紧凑签名格式：[27 + recid] || r || s，使用非压缩公钥
+	compact := make([]byte, 65)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+
+	pub, _, err := ecdsa.RecoverCompact(compact, PersonalSignHash(message))
+	if err != nil {
+		return "", fmt.Errorf("failed to recover public key: %w", err)
+	}
+
+	// 地址 = keccak256(未压缩公钥去掉 0x04 前缀) 的后 20 字节
+	addr := Keccak256(pub.SerializeUncompressed()[1:])[12:]
+	return ChecksumAddress("0x" + hex.EncodeToString(addr))
+}
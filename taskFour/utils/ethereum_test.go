@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// personalSign 用私钥对消息做 personal_sign，返回 r || s || v 的十六进制签名，v 为 0/1
+func personalSign(key *secp256k1.PrivateKey, message string) string {
+	compact := ecdsa.SignCompact(key, PersonalSignHash([]byte(message)), false)
+	sig := append(append([]byte{}, compact[1:]...), compact[0]-27)
+	return "0x" + hex.EncodeToString(sig)
+}
+
+// withV 替换签名的最后一个字节
+func withV(signature string, v byte) string {
+	sig, _ := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	sig[64] = v
+	return "0x" + hex.EncodeToString(sig)
+}
+
+func TestKeccak256(t *testing.T) {
+	cases := map[string]string{
+		"":    "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"abc": "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+	}
+	for input, want := range cases {
+		if got := hex.EncodeToString(Keccak256([]byte(input))); got != want {
+			t.Errorf("Keccak256(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	// EIP-55 规范中的示例
+	valid := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+	for _, want := range valid {
+		for _, input := range []string{want, strings.ToLower(want), "0x" + strings.ToUpper(want[2:])} {
+			got, err := ChecksumAddress(input)
+			if err != nil || got != want {
+				t.Errorf("ChecksumAddress(%s) = %s, %v, want %s", input, got, err, want)
+			}
+		}
+	}
+
+	invalid := []string{
+		"",
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAedff",
+		"0xZaAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	}
+	for _, input := range invalid {
+		if _, err := ChecksumAddress(input); err == nil {
+			t.Errorf("ChecksumAddress(%q) should fail", input)
+		}
+	}
+}
+
+func TestPersonalSignHash(t *testing.T) {
+	// keccak256("\x19Ethereum Signed Message:\n5hello")
+	want := "50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750"
+	if got := hex.EncodeToString(PersonalSignHash([]byte("hello"))); got != want {
+		t.Errorf("PersonalSignHash = %s, want %s", got, want)
+	}
+}
+
+func TestRecoverPersonalSignAddress(t *testing.T) {
+	// 私钥 1 和 2 对应的地址是公开的测试向量
+	key1 := secp256k1.PrivKeyFromBytes([]byte{1})
+	key2 := secp256k1.PrivKeyFromBytes([]byte{2})
+	const (
+		address1 = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+		address2 = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+	)
+	message := "hello"
+	sig1 := personalSign(key1, message)
+	raw, _ := hex.DecodeString(sig1[2:])
+	v := raw[64]
+
+	cases := []struct {
+		name      string
+		message   string
+		signature string
+		want      string
+		wantErr   string
+	}{
+		{name: "key 1", message: message, signature: sig1, want: address1},
+		{name: "key 2", message: message, signature: personalSign(key2, message), want: address2},
+		{name: "without 0x prefix", message: message, signature: strings.TrimPrefix(sig1, "0x"), want: address1},
+		{name: "v 27/28", message: message, signature: withV(sig1, v+27), want: address1},
+		{name: "other message", message: "hello!", signature: sig1, want: "not " + address1},
+		{name: "v 2", message: message, signature: withV(sig1, 2), wantErr: "invalid signature recovery id"},
+		{name: "v 26", message: message, signature: withV(sig1, 26), wantErr: "invalid signature recovery id"},
+		{name: "v 29", message: message, signature: withV(sig1, 29), wantErr: "invalid signature recovery id"},
+		{name: "short", message: message, signature: sig1[:len(sig1)-2], wantErr: "invalid signature length"},
+		{name: "not hex", message: message, signature: "0xzz", wantErr: "invalid signature encoding"},
+		{name: "zero r and s", message: message, signature: "0x" + strings.Repeat("00", 65), wantErr: "failed to recover public key"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RecoverPersonalSignAddress([]byte(tc.message), tc.signature)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, ok := strings.CutPrefix(tc.want, "not "); ok {
+				if got == want {
+					t.Fatalf("recovered %s from a signature over another message", got)
+				}
+				return
+			}
+			if got != tc.want {
+				t.Fatalf("address = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
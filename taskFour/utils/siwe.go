@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+
+var (
+	// ErrSIWEDomainMismatch 消息中的域名与服务端配置不一致
+	ErrSIWEDomainMismatch = errors.New("siwe: domain mismatch")
+	// ErrSIWEChainMismatch 消息中的链 ID 与服务端配置不一致
+	ErrSIWEChainMismatch = errors.New("siwe: chain id mismatch")
+	// ErrSIWEExpired 消息已过期
+	ErrSIWEExpired = errors.New("siwe: message expired")
+	// ErrSIWENotYetValid 消息尚未生效
+	ErrSIWENotYetValid = errors.New("siwe: message not yet valid")
+	// ErrSIWESignature 签名者与消息中的地址不一致
+	ErrSIWESignature = errors.New("siwe: signature does not match address")
+)
+
+// SIWEMessage EIP-4361 Sign-In with Ethereum 消息
+type SIWEMessage struct {
+	Scheme         string
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// ParseSIWEMessage 解析 EIP-4361 格式的消息文本
+func ParseSIWEMessage(text string) (*SIWEMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) < 2 {
+		return nil, errors.New("siwe: message too short")
+	}
+
+	msg := &SIWEMessage{}
+
+	// 第一行：[scheme://]domain wants you to sign in with your Ethereum account:
+	header := lines[0]
+	if !strings.HasSuffix(header, siweHeaderSuffix) {
+		return nil, errors.New("siwe: invalid header")
+	}
+	msg.Domain = strings.TrimSuffix(header, siweHeaderSuffix)
+	if i := strings.Index(msg.Domain, "://"); i >= 0 {
+		msg.Scheme, msg.Domain = msg.Domain[:i], msg.Domain[i+3:]
+	}
+	if msg.Domain == "" {
+		return nil, errors.New("siwe: missing domain")
+	}
+
+	// 第二行：EIP-55 格式的地址
+	msg.Address = lines[1]
+	checksummed, err := ChecksumAddress(msg.Address)
+	if err != nil || checksummed != msg.Address {
+		return nil, errors.New("siwe: address must be EIP-55 checksummed")
+	}
+
+	// 可选的 statement，前后各有一个空行
+	i := 2
+	for i < len(lines) && lines[i] == "" {
+		i++
+	}
+	if i < len(lines) && !strings.HasPrefix(lines[i], "URI: ") {
+		msg.Statement = lines[i]
+		i++
+		for i < len(lines) && lines[i] == "" {
+			i++
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		if line == "Resources:" {
+			for i++; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+				msg.Resources = append(msg.Resources, strings.TrimPrefix(lines[i], "- "))
+			}
+			i--
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, fmt.Errorf("siwe: invalid line %q", line)
+		}
+		if err := msg.setField(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case msg.URI == "":
+		return nil, errors.New("siwe: missing URI")
+	case msg.Version != "1":
+		return nil, errors.New("siwe: unsupported version")
+	case msg.ChainID == 0:
+		return nil, errors.New("siwe: missing chain id")
+	case len(msg.Nonce) < 8:
+		return nil, errors.New("siwe: nonce must be at least 8 characters")
+	case msg.IssuedAt.IsZero():
+		return nil, errors.New("siwe: missing issued at")
+	}
+
+	return msg, nil
+}
+
+func (m *SIWEMessage) setField(key, value string) error {
+	var err error
+	switch key {
+	case "URI":
+		m.URI = value
+	case "Version":
+		m.Version = value
+	case "Chain ID":
+		m.ChainID, err = strconv.ParseInt(value, 10, 64)
+	case "Nonce":
+		for _, r := range value {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+				return errors.New("siwe: nonce must be alphanumeric")
+			}
+		}
+		m.Nonce = value
+	case "Issued At":
+		m.IssuedAt, err = time.Parse(time.RFC3339, value)
+	case "Expiration Time":
+		var t time.Time
+		t, err = time.Parse(time.RFC3339, value)
+		m.ExpirationTime = &t
+	case "Not Before":
+		var t time.Time
+		t, err = time.Parse(time.RFC3339, value)
+		m.NotBefore = &t
+	case "Request ID":
+		m.RequestID = value
+	default:
+		return fmt.Errorf("siwe: unknown field %q", key)
+	}
+	if err != nil {
+		return fmt.Errorf("siwe: invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+// Verify 校验域名、链 ID（chainID 为 0 时不检查）、有效期，并用 personal_sign 签名恢复签名者地址
+// 不检查 nonce，nonce 是否有效由调用方结合存储判断
+func (m *SIWEMessage) Verify(rawMessage, signature, domain string, chainID int64, now time.Time) error {
+	if m.Domain != domain {
+		return ErrSIWEDomainMismatch
+	}
+	if chainID != 0 && m.ChainID != chainID {
+		return ErrSIWEChainMismatch
+	}
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return ErrSIWEExpired
+	}
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return ErrSIWENotYetValid
+	}
+
+	signer, err := RecoverPersonalSignAddress([]byte(rawMessage), signature)
+	if err != nil {
+		return err
+	}
+	if signer != m.Address {
+		return ErrSIWESignature
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// 私钥 1 对应的地址
+const siweTestAddress = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+
+const siweTestMessage = `localhost:8080 wants you to sign in with your Ethereum account:
+0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf
+
+Sign in to the blog
+
+URI: http://localhost:8080
+Version: 1
+Chain ID: 1
+Nonce: 9f1c2a7b4e6d8f01
+Issued At: 2025-01-01T00:00:00Z
+Expiration Time: 2025-01-01T00:10:00Z
+Not Before: 2025-01-01T00:00:00Z
+Request ID: req-1
+Resources:
+- https://example.com/a
+- https://example.com/b`
+
+// replaceLine 把消息中以 prefix 开头的行替换为 line，line 为空时删除该行
+func replaceLine(message, prefix, line string) string {
+	lines := strings.Split(message, "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			if line == "" {
+				continue
+			}
+			l = line
+		}
+		out = append(out, l)
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestParseSIWEMessage(t *testing.T) {
+	msg, err := ParseSIWEMessage(siweTestMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	switch {
+	case msg.Scheme != "" || msg.Domain != "localhost:8080":
+		t.Errorf("scheme/domain = %q %q", msg.Scheme, msg.Domain)
+	case msg.Address != siweTestAddress:
+		t.Errorf("address = %s", msg.Address)
+	case msg.Statement != "Sign in to the blog":
+		t.Errorf("statement = %q", msg.Statement)
+	case msg.URI != "http://localhost:8080" || msg.Version != "1" || msg.ChainID != 1:
+		t.Errorf("uri/version/chain = %q %q %d", msg.URI, msg.Version, msg.ChainID)
+	case msg.Nonce != "9f1c2a7b4e6d8f01" || !msg.IssuedAt.Equal(issuedAt):
+		t.Errorf("nonce/issued at = %q %v", msg.Nonce, msg.IssuedAt)
+	case msg.ExpirationTime == nil || !msg.ExpirationTime.Equal(issuedAt.Add(10*time.Minute)):
+		t.Errorf("expiration time = %v", msg.ExpirationTime)
+	case msg.NotBefore == nil || !msg.NotBefore.Equal(issuedAt):
+		t.Errorf("not before = %v", msg.NotBefore)
+	case msg.RequestID != "req-1" || len(msg.Resources) != 2 || msg.Resources[1] != "https://example.com/b":
+		t.Errorf("request id/resources = %q %v", msg.RequestID, msg.Resources)
+	}
+
+	// 带 scheme、没有 statement、使用 CRLF 换行
+	minimal := "https://localhost:8080 wants you to sign in with your Ethereum account:\r\n" + siweTestAddress +
+		"\r\n\r\nURI: http://localhost:8080\r\nVersion: 1\r\nChain ID: 5\r\nNonce: abcdefgh\r\nIssued At: 2025-01-01T00:00:00Z"
+	msg, err = ParseSIWEMessage(minimal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Scheme != "https" || msg.Domain != "localhost:8080" || msg.Statement != "" || msg.ChainID != 5 || msg.ExpirationTime != nil {
+		t.Errorf("minimal message parsed as %+v", msg)
+	}
+}
+
+func TestParseSIWEMessageInvalid(t *testing.T) {
+	header := "localhost:8080 wants you to sign in with your Ethereum account:"
+	cases := map[string]struct {
+		message string
+		wantErr string
+	}{
+		"empty":             {"", "message too short"},
+		"bad header":        {strings.Replace(siweTestMessage, "wants you to", "asks you to", 1), "invalid header"},
+		"missing domain":    {strings.TrimPrefix(siweTestMessage, "localhost:8080"), "missing domain"},
+		"lowercase address": {strings.Replace(siweTestMessage, siweTestAddress, strings.ToLower(siweTestAddress), 1), "EIP-55"},
+		"bad checksum":      {strings.Replace(siweTestMessage, siweTestAddress, "0x7e5F4552091A69125d5DfCb7b8C2659029395Bdf", 1), "EIP-55"},
+		"not an address":    {header + "\nalice\n\nURI: x", "EIP-55"},
+		"invalid line":      {replaceLine(siweTestMessage, "Request ID", "Request ID"), "invalid line"},
+		"unknown field":     {replaceLine(siweTestMessage, "Request ID", "Foo: bar"), "unknown field"},
+		"missing uri":       {replaceLine(siweTestMessage, "URI", ""), "missing URI"},
+		"wrong version":     {replaceLine(siweTestMessage, "Version", "Version: 2"), "unsupported version"},
+		"missing chain id":  {replaceLine(siweTestMessage, "Chain ID", ""), "missing chain id"},
+		"bad chain id":      {replaceLine(siweTestMessage, "Chain ID", "Chain ID: mainnet"), "invalid Chain ID"},
+		"short nonce":       {replaceLine(siweTestMessage, "Nonce", "Nonce: abc123"), "at least 8"},
+		"symbolic nonce":    {replaceLine(siweTestMessage, "Nonce", "Nonce: abc-12345"), "alphanumeric"},
+		"missing issued at": {replaceLine(siweTestMessage, "Issued At", ""), "missing issued at"},
+		"bad issued at":     {replaceLine(siweTestMessage, "Issued At", "Issued At: yesterday"), "invalid Issued At"},
+		"bad expiration":    {replaceLine(siweTestMessage, "Expiration Time", "Expiration Time: 2025-01-01"), "invalid Expiration Time"},
+		"bad not before":    {replaceLine(siweTestMessage, "Not Before", "Not Before: 0"), "invalid Not Before"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSIWEMessage(tc.message)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSIWEVerify(t *testing.T) {
+	key := secp256k1.PrivKeyFromBytes([]byte{1})
+	other := secp256k1.PrivKeyFromBytes([]byte{2})
+	signature := personalSign(key, siweTestMessage)
+	valid := time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		signature string
+		domain    string
+		chainID   int64
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "valid", signature: signature, domain: "localhost:8080", chainID: 1, now: valid},
+		{name: "any chain", signature: signature, domain: "localhost:8080", chainID: 0, now: valid},
+		{name: "wrong domain", signature: signature, domain: "evil.example", chainID: 1, now: valid, wantErr: ErrSIWEDomainMismatch},
+		{name: "wrong chain", signature: signature, domain: "localhost:8080", chainID: 137, now: valid, wantErr: ErrSIWEChainMismatch},
+		{name: "expired", signature: signature, domain: "localhost:8080", chainID: 1, now: valid.Add(5 * time.Minute), wantErr: ErrSIWEExpired},
+		{name: "not yet valid", signature: signature, domain: "localhost:8080", chainID: 1, now: valid.Add(-10 * time.Minute), wantErr: ErrSIWENotYetValid},
+		{name: "other signer", signature: personalSign(other, siweTestMessage), domain: "localhost:8080", chainID: 1, now: valid, wantErr: ErrSIWESignature},
+		{name: "signature over another message", signature: personalSign(key, siweTestMessage+"\n"), domain: "localhost:8080", chainID: 1, now: valid, wantErr: ErrSIWESignature},
+	}
+	msg, err := ParseSIWEMessage(siweTestMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := msg.Verify(siweTestMessage, tc.signature, tc.domain, tc.chainID, tc.now)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	if err := msg.Verify(siweTestMessage, withV(signature, 5), "localhost:8080", 1, valid); err == nil || !strings.Contains(err.Error(), "recovery id") {
+		t.Fatalf("bad v: err = %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify 生成 URL 友好的标识：转小写，保留字母（含中文）和数字，其余字符合并为 -
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}